@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/backfill"
+	log "github.com/behance/go-logrus"
+)
+
+// Backfill - starts the runs a job's schedules missed in a time window
+//   - the window's fire times are compared with the job's run history and the runs of earlier backfills
+//   - each missing run is started from a copy of the job with the fire time in its environment
+type Backfill struct {
+	JobID
+	schedID      string
+	from         TimeFlag
+	to           TimeFlag
+	tolerance    time.Duration
+	concurrency  int
+	pollInterval time.Duration
+	deleteJobs   bool
+	dryRun       bool
+}
+
+// FlagSet - the job, window and how to run the backfill
+func (theBackfill *Backfill) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theBackfill.JobID.FlagSet(flags)
+	flags.StringVar(&theBackfill.schedID, "sched-id", "", "Only backfill this schedule.  Defaults to every enabled schedule of the job")
+	flags.Var(&theBackfill.from, "from", "Start of the window. RFC3339 or a duration ago such as 24h")
+	flags.Var(&theBackfill.to, "to", "End of the window, exclusive. RFC3339 or a duration ago such as 1h.  Defaults to now")
+	flags.DurationVar(&theBackfill.tolerance, "tolerance", backfill.DefaultTolerance, "How long after a fire time a run may have been created and still count as its run")
+	flags.IntVar(&theBackfill.concurrency, "concurrency", 1, "How many backfill runs go at once.  1 runs them one after the other, oldest first")
+	flags.DurationVar(&theBackfill.pollInterval, "poll", backfill.DefaultPollInterval, "How often backfill runs are checked")
+	flags.BoolVar(&theBackfill.deleteJobs, "delete-jobs", false, "Delete each backfill job once its run finishes.  Backfilling the same window again then reruns its fire times")
+	flags.BoolVar(&theBackfill.dryRun, "dry-run", false, "List the window's fire times and their runs without starting anything")
+	return flags
+}
+
+// Validate - a job, a window and a positive concurrency
+func (theBackfill *Backfill) Validate() error {
+	if err := theBackfill.JobID.Validate(); err != nil {
+		return err
+	}
+	if theBackfill.from.IsZero() {
+		return errors.New("-from required")
+	}
+	if theBackfill.to.IsZero() {
+		theBackfill.to.Time = time.Now()
+	}
+	if !theBackfill.from.Before(theBackfill.to.Time) {
+		return errors.New("-from must be before -to")
+	}
+	if theBackfill.concurrency < 1 {
+		return errors.New("-concurrency must be at least 1")
+	}
+	return nil
+}
+
+// Usage - backfill usage
+func (theBackfill *Backfill) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "backfill\n\tStart the runs a job's schedules missed between -from and -to.  %s holds the missed fire time\n", backfill.LogicalTimeEnv)
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	theBackfill.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - backfill flags.  Returns self as CommandExec when valid
+func (theBackfill *Backfill) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	theBackfill.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theBackfill.Validate(); err != nil {
+		panic(err)
+	}
+	return theBackfill, nil
+}
+
+// Execute - with -dry-run the window's fire times, otherwise the backfill runs.  Errors when a backfill run fails
+func (theBackfill *Backfill) Execute(runtime *Runtime) (interface{}, error) {
+	jobID := string(theBackfill.JobID)
+	job, err := runtime.client.QueryJob(jobID, &met.JobQuery{Embed: []met.Embed{met.EmbedSchedules}})
+	if err != nil {
+		return nil, err
+	}
+	if theBackfill.schedID != "" && !hasSchedule(job, theBackfill.schedID) {
+		return nil, fmt.Errorf("job %s has no schedule %s", jobID, theBackfill.schedID)
+	}
+	fires, err := backfill.Find(runtime.client, job, theBackfill.schedID, theBackfill.from.Time, theBackfill.to.Time, theBackfill.tolerance)
+	if err != nil {
+		return nil, err
+	}
+	if theBackfill.dryRun {
+		return fires, nil
+	}
+	missing := backfill.Missing(fires)
+	log.Infof("%s: %d of %d fire times missed", jobID, len(missing), len(fires))
+	runner := &backfill.Runner{
+		Client:       runtime.client,
+		Job:          job,
+		Concurrency:  theBackfill.concurrency,
+		PollInterval: theBackfill.pollInterval,
+		DeleteJobs:   theBackfill.deleteJobs,
+	}
+	results := runner.Run(missing)
+	if failed := backfill.Failed(results); len(failed) > 0 {
+		times := make([]string, len(failed))
+		for i, result := range failed {
+			times[i] = result.Time.Format(time.RFC3339)
+		}
+		return nil, fmt.Errorf("%d of %d backfill runs failed: %s", len(failed), len(results), strings.Join(times, ","))
+	}
+	return results, nil
+}
+
+func hasSchedule(job *met.Job, schedID string) bool {
+	for _, sched := range job.Schedules {
+		if sched != nil && sched.ID == schedID {
+			return true
+		}
+	}
+	return false
+}
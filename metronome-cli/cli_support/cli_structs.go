@@ -8,6 +8,9 @@ import (
 	"errors"
 	"net/url"
 	"strconv"
+	"sort"
+	"time"
+	"flag"
 )
 
 //
@@ -58,6 +61,16 @@ func (list *NvList) Set(value string) error {
 	return nil
 }
 
+// sortedNames - the keys of an NvList in order so generated names like secret0 are stable
+func sortedNames(list NvList) []string {
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // ConstraintList - thin type providing Flags Value interface implementation for Metronome constraints
 //   type override to support parsing.  ConstraintList alias' []met.Constraint
 //   It implements flag.Value via Set/String
@@ -105,6 +118,23 @@ func (list *VolumeList) Set(value string) error {
 	return nil
 }
 
+// DockerParamList - thin type providing Flags Value interface implementation for docker parameters
+type DockerParamList []met.DockerParameter
+
+// String - Value interface implementation
+func (list *DockerParamList) String() string {
+	return fmt.Sprintf("%v", *list)
+}
+// Set - Value interface implementation.  key=value; the value may itself contain '='
+func (list *DockerParamList) Set(value string) error {
+	kv := strings.SplitN(value, "=", 2)
+	if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+		return errors.New("Docker parameters should be key=value")
+	}
+	*list = append(*list, met.DockerParameter{Key: strings.TrimSpace(kv[0]), Value: kv[1]})
+	return nil
+}
+
 // ArtifactList - thin type providing Flags Value interface implementation for Metronome artifacts
 type ArtifactList  []met.Artifact
 
@@ -151,4 +181,119 @@ func (list *ArtifactList) Set(value string) (err error) {
 	*list = append(*list, arty)
 
 	return nil
-}
\ No newline at end of file
+}
+
+// EmbedList - thin type providing Flags Value interface implementation for Metronome job read `embed` parameters
+//   - accepts comma separated values and may be called more than once
+//   - `none` requests no embedded detail
+type EmbedList struct {
+	embed []met.Embed
+	set   bool
+}
+
+// String - Value interface implementation
+func (list *EmbedList) String() string {
+	return fmt.Sprintf("%s", list.embed)
+}
+
+// Set - Value interface implementation
+func (list *EmbedList) Set(value string) error {
+	list.set = true
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || item == "none" {
+			continue
+		}
+		embed, err := met.ParseEmbed(item)
+		if err != nil {
+			return err
+		}
+		list.embed = append(list.embed, embed)
+	}
+	return nil
+}
+
+// Query - the job query selected on the command line or defaultQuery when --embed wasn't used
+func (list *EmbedList) Query(defaultQuery *met.JobQuery) *met.JobQuery {
+	if !list.set {
+		return defaultQuery
+	}
+	return &met.JobQuery{Embed: list.embed}
+}
+
+// TimeFlag - thin type providing Flags Value interface implementation for points in time
+//   - RFC3339 timestamps: 2016-12-12T18:00:00Z
+//   - durations counted back from now: 24h, 90m
+type TimeFlag struct {
+	time.Time
+}
+
+// String - Value interface implementation
+func (tf *TimeFlag) String() string {
+	if tf.IsZero() {
+		return ""
+	}
+	return tf.Format(time.RFC3339)
+}
+
+// Set - Value interface implementation
+func (tf *TimeFlag) Set(value string) error {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		tf.Time = at
+		return nil
+	}
+	ago, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("'%s' is neither an RFC3339 time nor a duration", value)
+	}
+	tf.Time = time.Now().Add(-ago)
+	return nil
+}
+
+// RunStatusList - thin type providing Flags Value interface implementation for filtering on run status
+type RunStatusList []met.RunStatus
+
+// String - Value interface implementation
+func (list *RunStatusList) String() string {
+	return fmt.Sprintf("%s", *list)
+}
+
+// Set - Value interface implementation.  Accepts comma separated statuses, rejecting unknown ones
+func (list *RunStatusList) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		status, err := met.ParseRunStatus(item)
+		if err != nil {
+			return err
+		}
+		*list = append(*list, status)
+	}
+	return nil
+}
+
+// RunFilterFlags - flags shared by the commands listing runs
+type RunFilterFlags struct {
+	since  TimeFlag
+	until  TimeFlag
+	status RunStatusList
+}
+
+// FlagSet - time window and status flags
+func (theFilter *RunFilterFlags) FlagSet(flags *flag.FlagSet, statusHelp string) *flag.FlagSet {
+	flags.Var(&theFilter.since, "since", "Only runs created at or after this time. RFC3339 or a duration ago such as 24h")
+	flags.Var(&theFilter.until, "until", "Only runs created before this time. RFC3339 or a duration ago such as 1h")
+	flags.Var(&theFilter.status, "status", statusHelp)
+	return flags
+}
+
+// Filter - the metronome RunFilter described by the flags
+func (theFilter *RunFilterFlags) Filter() *met.RunFilter {
+	return &met.RunFilter{
+		Since:  theFilter.since.Time,
+		Until:  theFilter.until.Time,
+		Status: []met.RunStatus(theFilter.status),
+	}
+}
@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/k8s"
+)
+
+// ConvertTopLevel - cli menu for converting Metronome jobs to other schedulers' formats
+type ConvertTopLevel struct {
+	subcommand string
+	task       CommandParse
+}
+
+// Usage - convert toplevel usage
+func (theConvert *ConvertTopLevel) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "convert {k8s|from-k8s}\n")
+	fmt.Fprintln(writer, `
+	  k8s      <options>  | Write a Job and its Schedules as Kubernetes CronJob yaml
+	  from-k8s <options>  | Convert Kubernetes CronJob yaml to Jobs and Schedules.  --apply creates them
+	`)
+}
+
+// Parse - parses out the target format and delegates to its CommandParse
+func (theConvert *ConvertTopLevel) Parse(args []string) (exec CommandExec, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			fmt.Fprintln(buf, r.(error).Error())
+			fmt.Fprintf(buf, "\nconvert %s usage:\n", theConvert.subcommand)
+			if theConvert.task != nil {
+				theConvert.task.Usage(buf)
+			}
+			theConvert.Usage(buf)
+			err = errors.New(buf.String())
+		}
+	}()
+	if len(args) == 0 {
+		panic(errors.New("convert subcommand required"))
+	}
+	theConvert.subcommand = args[0]
+	switch theConvert.subcommand {
+	case "k8s":
+		theConvert.task = CommandParse(new(ConvertK8s))
+	case "from-k8s":
+		theConvert.task = CommandParse(new(ConvertFromK8s))
+	case "help", "--help":
+		panic(errors.New("Please help"))
+	default:
+		panic(fmt.Errorf("convert Don't understand '%s'", theConvert.subcommand))
+	}
+	exec, err = theConvert.task.Parse(args[1:])
+	if err != nil {
+		panic(err)
+	}
+	return exec, nil
+}
+
+// ConvertK8s - exports a job, from Metronome or a json file, as CronJob manifests
+type ConvertK8s struct {
+	JobID
+	file   string
+	output string
+}
+
+// FlagSet - job source and output file
+func (theConvert *ConvertK8s) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theConvert.JobID.FlagSet(flags)
+	flags.StringVar(&theConvert.file, "f", "", "Read the job json or yaml, with embedded schedules, from a file instead of Metronome")
+	flags.StringVar(&theConvert.output, "o", "", "Write the yaml to a file instead of stdout")
+	return flags
+}
+
+// Validate - exactly one of job-id and f
+func (theConvert *ConvertK8s) Validate() error {
+	if (theConvert.JobID == "") == (theConvert.file == "") {
+		return errors.New("one of --job-id or -f required")
+	}
+	return nil
+}
+
+// Usage - ConvertK8s flags
+func (theConvert *ConvertK8s) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("convert k8s", flag.ExitOnError)
+	theConvert.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - ConvertK8s flags but returns self as CommandExec when valid
+func (theConvert *ConvertK8s) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("convert k8s", flag.ExitOnError)
+	theConvert.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theConvert.Validate(); err != nil {
+		panic(err)
+	}
+	return theConvert, nil
+}
+
+// Execute - writes the yaml and returns the warnings for anything that didn't translate
+func (theConvert *ConvertK8s) Execute(runtime *Runtime) (interface{}, error) {
+	job, err := theConvert.job(runtime)
+	if err != nil {
+		return nil, err
+	}
+	export, err := k8s.FromJob(job, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := export.YAML()
+	if err != nil {
+		return nil, err
+	}
+	if theConvert.output != "" {
+		err = ioutil.WriteFile(theConvert.output, doc, 0644)
+	} else {
+		_, err = os.Stdout.Write(doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return export.Warnings, nil
+}
+
+func (theConvert *ConvertK8s) job(runtime *Runtime) (*met.Job, error) {
+	if theConvert.file == "" {
+		return runtime.client.QueryJob(string(theConvert.JobID), &met.JobQuery{Embed: []met.Embed{met.EmbedSchedules}})
+	}
+	data, err := ioutil.ReadFile(theConvert.file)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := met.LoadJobs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", theConvert.file, err)
+	} else if len(jobs) != 1 {
+		return nil, fmt.Errorf("%s: has %d jobs; convert needs exactly one", theConvert.file, len(jobs))
+	}
+	return &jobs[0], nil
+}
+
+// ConvertFromK8s - converts a file of CronJob manifests and optionally creates the jobs
+type ConvertFromK8s struct {
+	file  string
+	apply bool
+}
+
+// ImportResult - a converted CronJob and, with --apply, what happened when it was created
+type ImportResult struct {
+	k8s.Import
+	Applied    bool   `json:"applied"`
+	ApplyError string `json:"applyError,omitempty"`
+}
+
+// FlagSet - file and apply flags
+func (theConvert *ConvertFromK8s) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theConvert.file, "f", "", "File of CronJob yaml.  Documents separated by ---")
+	flags.BoolVar(&theConvert.apply, "apply", false, "Create the converted jobs and schedules.  Jobs with errors are skipped")
+	return flags
+}
+
+// Validate - a file is required
+func (theConvert *ConvertFromK8s) Validate() error {
+	if theConvert.file == "" {
+		return errors.New("-f required")
+	}
+	return nil
+}
+
+// Usage - ConvertFromK8s flags
+func (theConvert *ConvertFromK8s) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("convert from-k8s", flag.ExitOnError)
+	theConvert.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - ConvertFromK8s flags but returns self as CommandExec when valid
+func (theConvert *ConvertFromK8s) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("convert from-k8s", flag.ExitOnError)
+	theConvert.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theConvert.Validate(); err != nil {
+		panic(err)
+	}
+	return theConvert, nil
+}
+
+// Execute - converts every CronJob in the file and, with --apply, creates each convertible job followed by its schedule
+func (theConvert *ConvertFromK8s) Execute(runtime *Runtime) (interface{}, error) {
+	data, err := ioutil.ReadFile(theConvert.file)
+	if err != nil {
+		return nil, err
+	}
+	cronJobs, err := k8s.ParseCronJobs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", theConvert.file, err)
+	}
+	imports := k8s.ToJobs(cronJobs)
+	results := make([]ImportResult, 0, len(imports))
+	for _, imp := range imports {
+		result := ImportResult{Import: imp}
+		if theConvert.apply && imp.OK() {
+			if err := createJob(runtime, imp.Job, imp.Schedules); err != nil {
+				result.ApplyError = err.Error()
+			} else {
+				result.Applied = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -15,7 +15,12 @@ type Runtime struct {
 	httpAddr  string
 	flags     *flag.FlagSet
 	Debug     bool
+	// Output - json, yaml or table
+	Output    string
 	help      bool
+	// Offline - don't connect to Metronome.  Set for OfflineCommands
+	Offline   bool
+	config    met.Config
 	client    met.Metronome
 	authToken string
 	user      string
@@ -31,6 +36,7 @@ func (runtime *Runtime) FlagSet(name  string) *flag.FlagSet {
 	flags := flag.NewFlagSet(name, flag.ExitOnError)
 	flags.StringVar(&runtime.httpAddr, "metronome-url", DefaultHTTPAddr, "Set the Metronome address")
 	flags.BoolVar(&runtime.Debug, "debug", false, "Turn on debug")
+	flags.StringVar(&runtime.Output, "output", OutputJSON, "Result format.  One of json,yaml,table.  Results without a table form are shown as json")
 	flags.StringVar(&runtime.authToken, "authorization", "", "Authorization token")
 	flags.StringVar(&runtime.user, "user", "", "user")
 	flags.StringVar(&runtime.pw, "password", "", "password")
@@ -47,6 +53,8 @@ func (runtime *Runtime) Parse(args []string) (CommandExec, error) {
 	flags := runtime.FlagSet("<global options> ")
 	if err := flags.Parse(args); err != nil {
 		return nil, err
+	} else if !In(runtime.Output, []string{OutputJSON, OutputYAML, OutputTable}) {
+		return nil, fmt.Errorf("-output must be one of %s,%s,%s not '%s'", OutputJSON, OutputYAML, OutputTable, runtime.Output)
 	}
 	config := met.NewDefaultConfig()
 	config.URL = runtime.httpAddr
@@ -67,13 +75,21 @@ func (runtime *Runtime) Parse(args []string) (CommandExec, error) {
 		config.Debug = runtime.Debug
 	}
 
-	client, err := met.NewClient(config)
-	if err != nil {
-		return nil, err
-	}
-	runtime.client = client
-
+	runtime.config = config
 	log.Debugf("Runtime <global flags> ok")
 	// No exec returned
 	return nil, nil
 }
+// Connect - create the Metronome client from the parsed global flags unless the command is Offline
+func (runtime *Runtime) Connect() error {
+	if runtime.Offline {
+		log.Debugf("Runtime offline; not connecting")
+		return nil
+	}
+	client, err := met.NewClient(runtime.config)
+	if err != nil {
+		return err
+	}
+	runtime.client = client
+	return nil
+}
@@ -14,3 +14,8 @@ type CommandParse interface {
 	Usage(writer io.Writer)
 }
 
+// OfflineCommand - implemented by the CommandExec of commands that never call Metronome.
+// The runtime doesn't connect for them, so they work without a cluster
+type OfflineCommand interface {
+	Offline() bool
+}
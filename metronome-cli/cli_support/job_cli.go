@@ -2,11 +2,13 @@ package cli
 
 import (
 	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/jobspec"
 	log "github.com/behance/go-logrus"
 	"fmt"
 	"errors"
 	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
 	"flag"
 )
@@ -24,15 +26,17 @@ type JobTopLevel struct {
 
 // Usage - show usage
 func (theJob *JobTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "job {create|delete|update|ls|get|schedules|schedule|help}\n")
+	fmt.Fprintf(writer, "job {create|delete|update|ls|get|diff|render|schedules|schedule|help}\n")
 	fmt.Fprintln(writer, `
 	  create  <options>   | creates a Job
 	  delete  <options>   | deletes a Job
 	  update  <options>   | update a Job
-	  get     <options>   | get a Job by job-id
+	  get     <options>   | get a Job by job-id.  --embed selects detail
+	  diff    <options>   | compare a Job definition file with the Job in Metronome
+	  render  <options>   | render a templated Job spec file with values, environment and overlays
 	  schedules <options> | get all schedules [] for a Job
 	  schedule  <options> | get a particular Schedule for Job
-	  ls                  | get all Jobs []
+	  ls      <options>   | get all Jobs [].  --embed selects detail
 	  Call job <action> help for more on a sub-command
 	`)
 
@@ -75,6 +79,12 @@ func (theJob *JobTopLevel) Parse(args [] string) (exec CommandExec, err error) {
 	case "update":
 		// PUT /v1/jobs/$jobId
 		theJob.task = CommandParse(new(JobUpdate))
+	case "diff":
+		// GET /v1/jobs/$jobId compared with a file
+		theJob.task = CommandParse(new(JobDiff))
+	case "render":
+		// no Metronome call; renders a spec file
+		theJob.task = CommandParse(new(JobRender))
 	case "schedules":
 		// GET /v1/jobs/$jobId/schedules  []Schedule
 		theJob.task = CommandParse(new(JobScheduleList))
@@ -112,11 +122,16 @@ type JobCreateConfig struct {
 	mem                   int
 	description           string
 	dockerImage           string
+	dockerParams          DockerParamList
+	ucrImage              string
+	forcePull             bool
 	restartPolicy         string
 	activeDeadlineSeconds int
 	constraints           ConstraintList
 	volumes               VolumeList
 	env                   NvList
+	envSecrets            NvList
+	secretVolumes         NvList
 	labels                NvList
 	artifacts             ArtifactList
 	args                  RunArgs
@@ -132,9 +147,19 @@ func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
 	if theJob.dockerImage != "" {
 		container = &met.Docker{
 			Image: theJob.dockerImage,
+			ForcePullImage: theJob.forcePull,
+			Parameters: []met.DockerParameter(theJob.dockerParams),
 		}
 	}
-	run, err := met.NewRun(theJob.cpus, theJob.disk, theJob.mem)
+	var ucr *met.UCR
+	if theJob.ucrImage != "" {
+		var err error
+		if ucr, err = met.NewUCR(theJob.ucrImage); err != nil {
+			return nil, err
+		}
+		ucr.SetForcePull(theJob.forcePull)
+	}
+	run, err := met.NewRun(theJob.cpus, theJob.mem, theJob.disk)
 
 	if err != nil {
 		return nil, err
@@ -143,6 +168,9 @@ func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
 		return nil, errors.New("max-launch-delay must be greater than 1")
 	}
 	run.SetMaxLaunchDelay(theJob.maxLaunchDelay)
+	if theJob.user != "" {
+		run.SetUser(theJob.user)
+	}
 
 	if len(theJob.constraints) > 0 {
 		run.SetPlacement(&met.Placement{Constraints: []met.Constraint(theJob.constraints)})
@@ -159,6 +187,16 @@ func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
 	if len(theJob.artifacts) > 0 {
 		run.SetArtifacts([]met.Artifact(theJob.artifacts))
 	}
+	for _, name := range sortedNames(theJob.envSecrets) {
+		run.SetEnvSecret(name, run.AddSecret(theJob.envSecrets[name]))
+	}
+	for _, containerPath := range sortedNames(theJob.secretVolumes) {
+		vol, err := met.NewSecretVolume(containerPath, run.AddSecret(theJob.secretVolumes[containerPath]))
+		if err != nil {
+			return nil, err
+		}
+		run.Volumes = append(run.Volumes, *vol)
+	}
 
 	var description string
 	if theJob.description != "" {
@@ -176,8 +214,10 @@ func (theJob *JobCreateConfig) makeJob() (*met.Job, error) {
 	if err != nil {
 		return nil, err
 
-	} else if container != nil {
-		newJob.GetRun().SetDocker(container).SetCmd(theJob.cmd)
+	}
+	newJob.GetRun().SetDocker(container).SetUCR(ucr).SetCmd(theJob.cmd)
+	if err = newJob.Validate(); err != nil {
+		return nil, err
 	}
 	log.Debugf("JobCreateRuntime: %+v", theJob)
 	return newJob, nil
@@ -200,24 +240,35 @@ func (theJob *JobCreateRuntime) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
 	if theJob.labels == nil {
 		theJob.labels = make(map[string]string)
 	}
+	if theJob.envSecrets == nil {
+		theJob.envSecrets = make(map[string]string)
+	}
+	if theJob.secretVolumes == nil {
+		theJob.secretVolumes = make(map[string]string)
+	}
 
 	log.Debugf("nvlist: %+v", theJob.env)
 	flags.StringVar((*string)(&theJob.JobID), "job-id", "", "Job Id")
 	flags.StringVar(&theJob.description, "description", "", "Job Description - optional")
 	flags.StringVar((*string)(&theJob.dockerImage), "docker-image", "", "Docker Image")
+	flags.Var(&theJob.dockerParams, "docker-param", "key=value . Adds a docker run parameter to Job.Run.Docker.Parameters.  You can call more than once")
+	flags.StringVar(&theJob.ucrImage, "ucr-image", "", "Docker image run by the universal container runtime instead of docker")
+	flags.BoolVar(&theJob.forcePull, "force-pull", false, "Pull the docker or ucr image even if it is cached on the agent")
 	flags.Float64Var(&theJob.cpus, "cpus", DefaultCPUs, "cpus")
 	flags.IntVar(&theJob.mem, "memory", DefaultMemory, "memory")
 	flags.IntVar(&theJob.disk, "disk", DefaultDisk, "disk")
 	flags.StringVar(&theJob.restartPolicy, "restart-policy", "NEVER", "Restart policy on job failure: NEVER or ALWAYS")
 	flags.IntVar(&theJob.activeDeadlineSeconds, "restart-active-deadline-seconds", 0, "If the job fails, how long should we try to restart the job. If no value is set, this means forever.")
-	flags.Var(&theJob.constraints, "constraint", "Add Constraint used to construct Job->Run->[]Constraint")
+	flags.Var(&theJob.constraints, "constraint", "attribute OPERATOR [value] e.g. 'rack IS rack-1' or 'hostname UNIQUE'. Adds Constraint used to construct Job->Run->[]Constraint")
 	flags.Var(&theJob.volumes, "volume", "/host:/container:{RO|RW} . Adds Volume passed to metrononome->Job->Run->Volumes. You can call more than once")
 	flags.Var(&theJob.artifacts, "artifact", `uri=xxx  executable={true|false}  cache={true|false} extract={true|false} executable={true|false}
 	                                cache,extract,executable are optional.  uri is required`)
 	flags.Var(&theJob.args, "arg", "Adds Arg metrononome->Job->Run->Args. You can call more than once")
 	flags.Var(&theJob.env, "env", "VAR=VAL . Adds Volume passed on to Job.Run.[]Volumes.  You can call more than once")
+	flags.Var(&theJob.envSecrets, "env-secret", "VAR=secret . Sets VAR from the DC/OS secret store path 'secret'.  You can call more than once")
+	flags.Var(&theJob.secretVolumes, "secret-volume", "/container/path=secret . Mounts the DC/OS secret store path 'secret' as a file.  You can call more than once")
 	flags.Var(&theJob.labels, "label", "Location=xxx; Owner=yyy")
-	flags.StringVar(&theJob.user, "user", "root", "user to run as")
+	flags.StringVar(&theJob.user, "user", "", "user to run as.  Defaults to the image's user")
 	flags.StringVar(&theJob.cmd, "cmd", "", "Command to run")
 	flags.IntVar(&theJob.maxLaunchDelay, "max-launch-delay", 900, "Max Launch delay.  minimum 1")
 	if !theJob.disableRunNow {
@@ -230,8 +281,14 @@ func (theJob *JobCreateRuntime) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
 func (theJob *JobCreateRuntime) Validate() error {
 	if theJob.JobID == "" {
 		return errors.New("Missing JobId")
-	} else if theJob.cmd == "" && theJob.dockerImage == "" {
-		return errors.New("Need command or docker image")
+	} else if theJob.cmd == "" && theJob.dockerImage == "" && theJob.ucrImage == "" {
+		return errors.New("Need command, docker image or ucr image")
+	} else if theJob.dockerImage != "" && theJob.ucrImage != "" {
+		return errors.New("docker-image and ucr-image are mutually exclusive")
+	} else if len(theJob.dockerParams) > 0 && theJob.dockerImage == "" {
+		return errors.New("docker-param requires docker-image")
+	} else if theJob.forcePull && theJob.dockerImage == "" && theJob.ucrImage == "" {
+		return errors.New("force-pull requires docker-image or ucr-image")
 	} else if theJob.cpus <= 0.0 || theJob.mem <= 0 || theJob.disk <= 0 {
 		return errors.New("cpus, memory, and disk must all be > 0")
 	}
@@ -340,19 +397,29 @@ func (theJob *JobDelete) Execute(runtime *Runtime) (interface{}, error) {
 // JobGet - Get a job via command line.
 //   - Implements CommandParse & CommandExecute interfaces
 //   - GET /v1/jobs/$jobId
-type JobGet JobID
+type JobGet struct {
+	JobID
+	embed EmbedList
+}
+
+// FlagSet - job-id and the detail to embed
+func (theJob *JobGet) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theJob.JobID.FlagSet(flags)
+	flags.Var(&theJob.embed, "embed", "Comma separated detail to embed: history,historySummary,activeRuns,schedules or none. Default historySummary,activeRuns,schedules")
+	return flags
+}
 
 // Usage - CommandParse implementation
 func (theJob *JobGet) Usage(writer io.Writer) {
 	flags := flag.NewFlagSet("job get", flag.ExitOnError)
-	(*JobID)(theJob).FlagSet(flags)
+	theJob.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
 // Parse - the command line flags
 func (theJob *JobGet) Parse(args []string) (exec CommandExec, err error) {
 	flags := flag.NewFlagSet("job get", flag.ExitOnError)
-	(*JobID)(theJob).FlagSet(flags)
+	theJob.FlagSet(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -363,7 +430,7 @@ func (theJob *JobGet) Parse(args []string) (exec CommandExec, err error) {
 	}()
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*JobID)(theJob).Validate(); err != nil {
+	} else if err = theJob.JobID.Validate(); err != nil {
 		panic(err)
 	} else {
 		return theJob, nil
@@ -371,25 +438,219 @@ func (theJob *JobGet) Parse(args []string) (exec CommandExec, err error) {
 }
 // Execute - get the job from metronome
 func (theJob *JobGet) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.GetJob(string(*theJob))
+	return runtime.client.QueryJob(string(theJob.JobID), theJob.embed.Query(met.DefaultJobQuery()))
+}
+
+// JobDiff - compare a job definition file with the job in Metronome
+//   - Implements CommandParse & CommandExecute interfaces
+//   - GET /v1/jobs/$jobId
+type JobDiff struct {
+	JobID
+	file    string
+	desired met.Job
+}
+
+// FlagSet - the file and, optionally, the job it is compared with
+func (theJob *JobDiff) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar((*string)(&theJob.JobID), "job-id", "", "Job Id.  Defaults to the id in the file")
+	flags.StringVar(&theJob.file, "f", "", "Job definition (json or yaml) to compare with Metronome")
+	return flags
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobDiff) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "job diff\n\tChanges that would turn the job in Metronome into the file's job.  Runtime detail is ignored\n")
+	flags := flag.NewFlagSet("job diff", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Validate - a readable job definition and a job id
+func (theJob *JobDiff) Validate() error {
+	if theJob.file == "" {
+		return errors.New("-f required")
+	}
+	data, err := ioutil.ReadFile(theJob.file)
+	if err != nil {
+		return err
+	}
+	jobs, err := met.LoadJobs(data)
+	if err != nil {
+		return fmt.Errorf("%s: %s", theJob.file, err)
+	} else if len(jobs) != 1 {
+		return fmt.Errorf("%s: has %d jobs; diff needs exactly one", theJob.file, len(jobs))
+	}
+	theJob.desired = jobs[0]
+	if theJob.JobID == "" {
+		theJob.JobID = JobID(theJob.desired.ID)
+	}
+	return theJob.JobID.Validate()
+}
+
+// Parse - the command line flags
+func (theJob *JobDiff) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job diff", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	return theJob, nil
+}
+
+// Execute - the changes from the job in Metronome to the file's job.  Schedules are compared when the file has them
+func (theJob *JobDiff) Execute(runtime *Runtime) (interface{}, error) {
+	var query *met.JobQuery
+	if theJob.desired.Schedules != nil {
+		query = &met.JobQuery{Embed: []met.Embed{met.EmbedSchedules}}
+	}
+	live, err := runtime.client.QueryJob(string(theJob.JobID), query)
+	if err != nil {
+		return nil, err
+	}
+	return live.Diff(&theJob.desired)
+}
+
+// JobRender - render a templated job spec file into the jobs it defines
+//   - Implements CommandParse & CommandExecute interfaces
+//   - Offline; nothing is sent to Metronome
+type JobRender struct {
+	file     string
+	values   RunArgs
+	sets     RunArgs
+	env      string
+	overlays RunArgs
+}
+
+// FlagSet - the spec file, its values and overlays
+func (theJob *JobRender) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theJob.file, "f", "", "Job spec file.  A job definition (json or yaml) that is a Go text/template")
+	flags.Var(&theJob.values, "values", "Values file (yaml or json).  You can call more than once; later files win")
+	flags.Var(&theJob.sets, "set", "key=value . Overrides a value; dots address nested values e.g. image.tag=1.4.2.  You can call more than once")
+	flags.StringVar(&theJob.env, "env", "", "Environment whose values, from the values files' environments section, are merged over the rest")
+	flags.Var(&theJob.overlays, "overlay", "Partial job definition merged into the rendered jobs.  You can call more than once")
+	return flags
+}
+
+// Usage - CommandParse implementation
+func (theJob *JobRender) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "job render\n\tThe jobs a templated spec file renders to.  Each is validated\n")
+	flags := flag.NewFlagSet("job render", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Validate - a spec file
+func (theJob *JobRender) Validate() error {
+	if theJob.file == "" {
+		return errors.New("-f required")
+	}
+	return nil
+}
+
+// Parse - the command line flags
+func (theJob *JobRender) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job render", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theJob.Validate(); err != nil {
+		panic(err)
+	}
+	return theJob, nil
 }
 
-// JobList - no arg type to list all the jobs in the system via command line
+// Offline - OfflineCommand implementation.  Rendering needs only the files
+func (theJob *JobRender) Offline() bool {
+	return true
+}
+
+// Config - the values and overlays the flags select
+func (theJob *JobRender) Config() jobspec.Config {
+	return jobspec.Config{
+		ValueFiles:  theJob.values,
+		Sets:        theJob.sets,
+		Environment: theJob.env,
+		Overlays:    theJob.overlays,
+	}
+}
+
+// Execute - the rendered jobs.  Fails when one of them isn't valid
+func (theJob *JobRender) Execute(runtime *Runtime) (interface{}, error) {
+	jobs, err := theJob.Config().RenderFile(theJob.file)
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		if err := jobs[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%s: job %s: %s", theJob.file, jobs[i].ID, err)
+		}
+	}
+	return jobs, nil
+}
+
+// JobList - type to list all the jobs in the system via command line
 //  - Implements CommandParse/CommandExecute interfaces
 //  - GET /v1/jobs
-type JobList int
+type JobList struct {
+	embed EmbedList
+}
+
+// FlagSet - the detail to embed
+func (theJob *JobList) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.Var(&theJob.embed, "embed", "Comma separated detail to embed: history,historySummary,activeRuns,schedules or none. Default historySummary,activeRuns,schedules")
+	return flags
+}
 
 // Usage - CommandParse implementation
 func (theJob *JobList) Usage(writer io.Writer) {
 	fmt.Fprintf(writer, "job ls\n\tList all jobs\n")
+	flags := flag.NewFlagSet("job ls", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
 }
-// Parse - nothing to parse.  Implements CommandParse
-func (theJob *JobList) Parse([] string) (CommandExec, error) {
+// Parse - optional --embed.  Implements CommandParse
+func (theJob *JobList) Parse(args [] string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("job ls", flag.ExitOnError)
+	theJob.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	}
 	return theJob, nil
 }
-// Execute - get the jobs from Metronome
+// Execute - get the jobs from Metronome.  Schedules are embedded by default for the table's SCHEDULES column
 func (theJob *JobList) Execute(runtime *Runtime) (interface{}, error) {
-	jobs, err := runtime.client.Jobs()
+	query := met.DefaultJobsQuery()
+	query.Embed = append(query.Embed, met.EmbedSchedules)
+	jobs, err := runtime.client.QueryJobs(theJob.embed.Query(query))
 	if err != nil {
 		return nil, err
 	}
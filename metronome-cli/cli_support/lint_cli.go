@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/lint"
+)
+
+// FailNone - `lint -fail-on` value that never fails
+const FailNone = "none"
+
+// RuleLoad - the rule reported for definition files that can't be loaded
+const RuleLoad = "load"
+
+// Lint - checks job definition files, or the jobs in Metronome, against the lint rules
+//   - Findings are written to stdout in the -output format
+//   - Fails when a finding is at least as serious as -fail-on
+type Lint struct {
+	path   string
+	config string
+	live   bool
+	failOn string
+	linter *lint.Linter
+}
+
+// FlagSet - what to lint, the rules and when to fail
+func (theLint *Lint) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theLint.path, "f", "", "Job definition file or a directory of them (.json, .yaml, .yml)")
+	flags.BoolVar(&theLint.live, "live", false, "Lint every job in Metronome instead of files")
+	flags.StringVar(&theLint.config, "config", "", "Lint config (yaml or json) configuring built-in rules and adding custom rules")
+	flags.StringVar(&theLint.failOn, "fail-on", string(lint.SeverityError), "Fail when a finding is at least this severe.  One of warning,error,none")
+	return flags
+}
+
+// Validate - files or -live, a readable config and a known -fail-on
+func (theLint *Lint) Validate() error {
+	if (theLint.path == "") == !theLint.live {
+		return errors.New("one of -f or -live required")
+	}
+	if theLint.failOn != FailNone {
+		if err := lint.Severity(theLint.failOn).Validate(); err != nil {
+			return fmt.Errorf("-fail-on: %s", err)
+		}
+	}
+	config := lint.Config{}
+	if theLint.config != "" {
+		data, err := ioutil.ReadFile(theLint.config)
+		if err != nil {
+			return err
+		}
+		if config, err = lint.LoadConfig(data); err != nil {
+			return fmt.Errorf("%s: %s", theLint.config, err)
+		}
+	}
+	linter, err := lint.New(config)
+	if err != nil {
+		return fmt.Errorf("%s: %s", theLint.config, err)
+	}
+	theLint.linter = linter
+	return nil
+}
+
+// Usage - lint usage
+func (theLint *Lint) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "lint\n\tCheck job definitions against policy.  Built-in rules: %s\n", strings.Join(lint.Builtins(), ","))
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	theLint.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - lint flags.  Returns self as CommandExec when valid
+func (theLint *Lint) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	theLint.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theLint.Validate(); err != nil {
+		panic(err)
+	}
+	return theLint, nil
+}
+
+// Offline - OfflineCommand implementation.  Only -live needs Metronome
+func (theLint *Lint) Offline() bool {
+	return !theLint.live
+}
+
+// Execute - writes the findings.  Errors when one is as severe as -fail-on
+func (theLint *Lint) Execute(runtime *Runtime) (interface{}, error) {
+	var findings []lint.Finding
+	if theLint.live {
+		jobs, err := runtime.client.QueryJobs(&met.JobQuery{Embed: []met.Embed{met.EmbedSchedules}})
+		if err != nil {
+			return nil, err
+		}
+		findings = theLint.linter.Lint(*jobs...)
+	} else {
+		var err error
+		if findings, err = theLint.lintFiles(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeOutput(os.Stdout, runtime.Output, findings); err != nil {
+		return nil, err
+	}
+	if theLint.failOn != FailNone && lint.Failed(findings, lint.Severity(theLint.failOn)) {
+		return nil, fmt.Errorf("%d findings; failing on %s", len(findings), theLint.failOn)
+	}
+	return nil, nil
+}
+
+// lintFiles - findings for -f, a file or every definition file under a directory.  Hidden files and directories and
+// the -config file are skipped.  A file that isn't a job definition is an error when named by -f and a warning when
+// found in a directory, where values files, overlays and schemas may sit beside the jobs
+func (theLint *Lint) lintFiles() ([]lint.Finding, error) {
+	var config string
+	if theLint.config != "" {
+		config, _ = filepath.Abs(theLint.config)
+	}
+	var files []string
+	err := filepath.Walk(theLint.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == theLint.path {
+			if !info.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !In(strings.ToLower(filepath.Ext(path)), []string{".json", ".yaml", ".yml"}) {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && abs == config {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	findings := []lint.Finding{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		jobs, err := loadDefinitions(data)
+		if err != nil {
+			severity := lint.SeverityError
+			if file != theLint.path {
+				severity = lint.SeverityWarning
+			}
+			findings = append(findings, lint.Finding{Rule: RuleLoad, Severity: severity, File: file, Message: err.Error()})
+			continue
+		}
+		for _, finding := range theLint.linter.Lint(jobs...) {
+			finding.File = file
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// loadDefinitions - the jobs in data.  Documents without a job id aren't job definitions
+func loadDefinitions(data []byte) ([]met.Job, error) {
+	jobs, err := met.LoadJobs(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, errors.New("not a job definition: no jobs")
+	}
+	for i := range jobs {
+		if jobs[i].ID == "" {
+			return nil, fmt.Errorf("not a job definition: job %d has no id", i+1)
+		}
+	}
+	return jobs, nil
+}
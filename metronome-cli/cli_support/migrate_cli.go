@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/chronos"
+	log "github.com/behance/go-logrus"
+)
+
+// MigrateTopLevel - cli menu for migrating jobs from other schedulers
+type MigrateTopLevel struct {
+	subcommand string
+	task       CommandParse
+}
+
+// Usage - migrate toplevel usage
+func (theMigrate *MigrateTopLevel) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "migrate {chronos}\n")
+	fmt.Fprintln(writer, `
+	  chronos <options>  | Convert Chronos jobs to Metronome jobs and schedules.  --apply creates them
+	`)
+}
+
+// Parse - parses out the source scheduler and delegates to its CommandParse
+func (theMigrate *MigrateTopLevel) Parse(args []string) (exec CommandExec, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			fmt.Fprintln(buf, r.(error).Error())
+			fmt.Fprintf(buf, "\nmigrate %s usage:\n", theMigrate.subcommand)
+			if theMigrate.task != nil {
+				theMigrate.task.Usage(buf)
+			}
+			theMigrate.Usage(buf)
+			err = errors.New(buf.String())
+		}
+	}()
+	if len(args) == 0 {
+		panic(errors.New("migrate subcommand required"))
+	}
+	theMigrate.subcommand = args[0]
+	switch theMigrate.subcommand {
+	case "chronos":
+		theMigrate.task = CommandParse(new(MigrateChronos))
+	case "help", "--help":
+		panic(errors.New("Please help"))
+	default:
+		panic(fmt.Errorf("migrate Don't understand '%s'", theMigrate.subcommand))
+	}
+	exec, err = theMigrate.task.Parse(args[1:])
+	if err != nil {
+		panic(err)
+	}
+	return exec, nil
+}
+
+// MigrateChronos - converts a file of Chronos jobs (GET /scheduler/jobs output) and optionally creates them
+type MigrateChronos struct {
+	file  string
+	apply bool
+}
+
+// MigrationResult - a converted Chronos job and, with --apply, what happened when it was created
+type MigrationResult struct {
+	chronos.Migration
+	Applied    bool   `json:"applied"`
+	ApplyError string `json:"applyError,omitempty"`
+}
+
+// FlagSet - file and apply flags
+func (theMigrate *MigrateChronos) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theMigrate.file, "f", "", "File of Chronos job json.  A single job or a list")
+	flags.BoolVar(&theMigrate.apply, "apply", false, "Create the converted jobs and schedules.  Jobs with errors are skipped")
+	return flags
+}
+
+// Validate - a file is required
+func (theMigrate *MigrateChronos) Validate() error {
+	if theMigrate.file == "" {
+		return errors.New("-f required")
+	}
+	return nil
+}
+
+// Usage - MigrateChronos flags
+func (theMigrate *MigrateChronos) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("migrate chronos", flag.ExitOnError)
+	theMigrate.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - MigrateChronos flags but returns self as CommandExec when valid
+func (theMigrate *MigrateChronos) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("migrate chronos", flag.ExitOnError)
+	theMigrate.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theMigrate.Validate(); err != nil {
+		panic(err)
+	}
+	return theMigrate, nil
+}
+
+// Execute - converts every job in the file and, with --apply, creates each convertible job followed by its schedules
+func (theMigrate *MigrateChronos) Execute(runtime *Runtime) (interface{}, error) {
+	data, err := ioutil.ReadFile(theMigrate.file)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := chronos.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", theMigrate.file, err)
+	}
+	migrations := chronos.ConvertAll(jobs, time.Now())
+	results := make([]MigrationResult, 0, len(migrations))
+	for _, migration := range migrations {
+		result := MigrationResult{Migration: migration}
+		if theMigrate.apply && migration.OK() {
+			if err := createJob(runtime, migration.Job, migration.Schedules); err != nil {
+				result.ApplyError = err.Error()
+			} else {
+				result.Applied = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// createJob - creates the job then each of its schedules
+func createJob(runtime *Runtime, job *met.Job, schedules []met.Schedule) error {
+	log.Debugf("creating %s with %d schedules", job.ID, len(schedules))
+	if _, err := runtime.client.CreateJob(job); err != nil {
+		return err
+	}
+	for i := range schedules {
+		if _, err := runtime.client.CreateSchedule(job.ID, &schedules[i]); err != nil {
+			return fmt.Errorf("job created but schedule %s failed: %s", schedules[i].ID, err)
+		}
+	}
+	return nil
+}
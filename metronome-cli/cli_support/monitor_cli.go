@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/monitor"
+	log "github.com/behance/go-logrus"
+)
+
+// Monitor - checks jobs for missed schedule starts, overdue runs and stale successes
+//   - once: the violations are written to stdout in the -output format and the command fails when there are any
+//   - with -watch: checks forever, writing new violations to stdout as json lines
+//   - either way new violations are posted to each -webhook
+type Monitor struct {
+	jobIDs   RunArgs
+	window   time.Duration
+	grace    time.Duration
+	webhooks RunArgs
+	watch    time.Duration
+}
+
+// FlagSet - the jobs, how far back to look and where to report
+func (theMonitor *Monitor) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.Var(&theMonitor.jobIDs, "job-id", "Job to check.  You can call more than once.  Defaults to every job")
+	flags.DurationVar(&theMonitor.window, "window", monitor.DefaultWindow, "How far back schedule fire times are checked for runs")
+	flags.DurationVar(&theMonitor.grace, "grace", monitor.DefaultGrace, "How long after a fire time its run may start")
+	flags.Var(&theMonitor.webhooks, "webhook", "URL new violations are posted to as json.  You can call more than once")
+	flags.DurationVar(&theMonitor.watch, "watch", 0, "Keep checking at this interval, reporting each violation once until it clears")
+	return flags
+}
+
+// Validate - a window longer than the grace
+func (theMonitor *Monitor) Validate() error {
+	if theMonitor.grace <= 0 || theMonitor.window <= theMonitor.grace {
+		return errors.New("-window must be longer than a positive -grace")
+	}
+	if theMonitor.watch < 0 {
+		return errors.New("-watch must not be negative")
+	}
+	return nil
+}
+
+// Usage - monitor usage
+func (theMonitor *Monitor) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "monitor\n\tReport missed schedule starts, runs over their %s label and last successes older than their %s label\n", monitor.MaxDurationLabel, monitor.SLALabel)
+	flags := flag.NewFlagSet("monitor", flag.ExitOnError)
+	theMonitor.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - monitor flags.  Returns self as CommandExec when valid
+func (theMonitor *Monitor) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("monitor", flag.ExitOnError)
+	theMonitor.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theMonitor.Validate(); err != nil {
+		panic(err)
+	}
+	return theMonitor, nil
+}
+
+// Execute - check once or, with -watch, until interrupted
+func (theMonitor *Monitor) Execute(runtime *Runtime) (interface{}, error) {
+	checker := &monitor.Monitor{
+		Client:  runtime.client,
+		Options: monitor.Options{Window: theMonitor.window, Grace: theMonitor.grace},
+		JobIDs:  theMonitor.jobIDs,
+	}
+	for _, url := range theMonitor.webhooks {
+		checker.Notifiers = append(checker.Notifiers, &monitor.WebhookNotifier{URL: url})
+	}
+	if theMonitor.watch > 0 {
+		checker.Notifiers = append(checker.Notifiers, &monitor.WriterNotifier{Writer: os.Stdout})
+		stop := make(chan struct{})
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			sig := <-signals
+			log.Infof("%s; stopping", sig)
+			close(stop)
+		}()
+		checker.Run(theMonitor.watch, stop)
+		return nil, nil
+	}
+	violations, err := checker.Check()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOutput(os.Stdout, runtime.Output, violations); err != nil {
+		return nil, err
+	}
+	if err := checker.Notify(violations); err != nil {
+		return nil, err
+	}
+	if len(violations) > 0 {
+		return nil, fmt.Errorf("%d violations", len(violations))
+	}
+	return nil, nil
+}
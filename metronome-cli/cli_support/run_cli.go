@@ -17,12 +17,13 @@ type RunsTopLevel JobTopLevel
 
 // Usage - CommandParse implementation
 func (theRun *RunsTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "run {start|stop|ls|get} <options>:\n")
+	fmt.Fprintf(writer, "run {start|stop|ls|history|get} <options>:\n")
 	fmt.Fprintln(writer, `
-	  start <options>  | Start a Job that has a schedule.
-	  stop  <options>  | Stop a Job
-	  ls               | Status a Job -- currently only returns 'ACTIVE' jobs
-	  get <options>    | Get a Job run status.
+	  start <options>   | Start a Job that has a schedule.
+	  stop  <options>   | Stop a Job
+	  ls    <options>   | Status a Job -- only returns runs that haven't finished
+	  history <options> | Finished runs of a Job, newest first
+	  get <options>     | Get a Job run status.
 
 	  Call run <action> help for more on a sub-command
 	`)
@@ -53,6 +54,8 @@ func (theRun *RunsTopLevel) Parse(args [] string) (exec CommandExec, err error)
 	switch theRun.subcommand {
 	case "ls":
 		theRun.task = CommandParse(new(RunLs))
+	case "history":
+		theRun.task = CommandParse(new(RunHistory))
 	case "get":
 		theRun.task = CommandParse(new(RunStatusJob))
 	case "start":
@@ -77,14 +80,24 @@ func (theRun *RunsTopLevel) Parse(args [] string) (exec CommandExec, err error)
 	}
 }
 
-// RunLs - Get all the runs for a job via cli
+// RunLs - Get the active runs for a job via cli
 // GET /v1/jobs/$jobId/runs
-type RunLs JobID
+type RunLs struct {
+	JobID
+	RunFilterFlags
+}
+
+// FlagSet - job-id and filters
+func (theRun *RunLs) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.JobID.FlagSet(flags)
+	theRun.RunFilterFlags.FlagSet(flags, "Comma separated run statuses to keep: INITIAL,STARTING,ACTIVE")
+	return flags
+}
 
 // Usage - RunLs usage
 func (theRun *RunLs) Usage(writer io.Writer) {
-	flags := flag.NewFlagSet("job ls", flag.ExitOnError)
-	(*JobID)(theRun).FlagSet(flags)
+	flags := flag.NewFlagSet("run ls", flag.ExitOnError)
+	theRun.FlagSet(flags)
 	flags.SetOutput(writer)
 	flags.PrintDefaults()
 }
@@ -93,7 +106,7 @@ func (theRun *RunLs) Usage(writer io.Writer) {
 //   - implements CommandParse
 func (theRun *RunLs) Parse(args []string) (_ CommandExec, err error) {
 	flags := flag.NewFlagSet("run ls", flag.ExitOnError)
-	(*JobID)(theRun).FlagSet(flags)
+	theRun.FlagSet(flags)
 	defer func() {
 		if r := recover(); r != nil {
 			buf := new(bytes.Buffer)
@@ -104,7 +117,7 @@ func (theRun *RunLs) Parse(args []string) (_ CommandExec, err error) {
 	}()
 	if err = flags.Parse(args); err != nil {
 		panic(err)
-	} else if err = (*JobID)(theRun).Validate(); err != nil {
+	} else if err = theRun.JobID.Validate(); err != nil {
 		panic(err)
 	} else {
 		return theRun, nil
@@ -112,7 +125,52 @@ func (theRun *RunLs) Parse(args []string) (_ CommandExec, err error) {
 }
 // Execute the Metronome API
 func (theRun *RunLs) Execute(runtime *Runtime) (interface{}, error) {
-	return runtime.client.Runs(string(*theRun),0)
+	return runtime.client.ActiveRuns(string(theRun.JobID), theRun.Filter())
+}
+
+// RunHistory - Get the finished runs for a job via cli
+// GET /v1/jobs/$jobId?embed=history
+type RunHistory RunLs
+
+// FlagSet - job-id and filters
+func (theRun *RunHistory) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.JobID.FlagSet(flags)
+	theRun.RunFilterFlags.FlagSet(flags, "Comma separated outcomes to keep: SUCCESS,FAILED")
+	return flags
+}
+
+// Usage - RunHistory usage
+func (theRun *RunHistory) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("run history", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+// Parse - parse flags
+//   - need a job-id
+//   - implements CommandParse
+func (theRun *RunHistory) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("run history", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theRun.JobID.Validate(); err != nil {
+		panic(err)
+	} else {
+		return theRun, nil
+	}
+}
+// Execute the Metronome API
+func (theRun *RunHistory) Execute(runtime *Runtime) (interface{}, error) {
+	return runtime.client.RunHistory(string(theRun.JobID), theRun.Filter())
 }
 
 // RunStartJob - cli actuator to run POST /v1/jobs/$jobId/runs
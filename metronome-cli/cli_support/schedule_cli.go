@@ -8,6 +8,7 @@ import (
 	"errors"
 	"bytes"
 	"io"
+	"time"
 )
 
 // Scheduling related cli support structures implementing CommandParse and CommandExecute to run Metronome scheduling related
@@ -32,13 +33,14 @@ type SchedTopLevel struct {
 }
 // Usage - schedule toplevel usage
 func (theSchedule *SchedTopLevel) Usage(writer io.Writer) {
-	fmt.Fprintf(writer, "schedule {create|delete|update|get|ls}  \n")
+	fmt.Fprintf(writer, "schedule {create|delete|update|get|ls|next}  \n")
 	fmt.Fprintln(writer, `
 	  create  <options>  | Create a Schedule for a Job
 	  delete  <options>  | Delete a Schedule for a Job
 	  update  <options>  | Update a Schedule for a Job
 	  get     <options>  | Get a single Schedule for a Job
 	  ls                 | Get all Schedules for a Job
+	  next    <options>  | Preview upcoming fire times for a Job's Schedules or an ad-hoc --cron
 	`)
 }
 // Parse - parses out actions, delegates deeper parsing to action specific CommandParse implementations
@@ -80,6 +82,9 @@ func (theSchedule *SchedTopLevel) Parse(args [] string) (exec CommandExec, err e
 	case "update":
 		// PUT /v1/jobs/$jobId/schedules/$scheduleId
 		theSchedule.task = CommandParse(new(JobSchedUpdate))
+	case "next":
+		// GET /v1/jobs/$jobId/schedules[/$scheduleId] unless --cron is given
+		theSchedule.task = CommandParse(new(JobSchedNext))
 	case "help", "--help":
 		panic(errors.New("Please help"))
 	default:
@@ -330,6 +335,146 @@ func (theSched *JobSched) Validate() error {
 		return errors.New("-starting-deadline-seconds must be > 1")
 	}
 
-	return nil
+	return theSched.Schedule.Validate()
 }
 
+// JobSchedNext - cli type previewing the next fire times of a job's schedules or of an ad-hoc cron expression
+type JobSchedNext struct {
+	JobID
+	SchedID
+	cron  string
+	tz    string
+	count int
+}
+// ScheduleForecast - upcoming fire times of one schedule
+type ScheduleForecast struct {
+	JobID    string     `json:"jobId,omitempty"`
+	SchedID  string     `json:"schedId,omitempty"`
+	Cron     string     `json:"cron"`
+	Timezone string     `json:"timezone"`
+	Next     []FireTime `json:"next"`
+	Warnings []string   `json:"warnings,omitempty"`
+}
+// FireTime - a fire time in the schedule's timezone and in local time
+type FireTime struct {
+	Schedule string `json:"schedule"`
+	Local    string `json:"local"`
+}
+// FlagSet - job-id and optional sched-id, or cron and tz for ad-hoc expressions
+func (theSched *JobSchedNext) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theSched.JobID.FlagSet(flags)
+	theSched.SchedID.FlagSet(flags)
+	flags.StringVar(&theSched.cron, "cron", "", "Ad-hoc cron expression to preview instead of a job's schedules")
+	flags.StringVar(&theSched.tz, "tz", "", "Time zone for --cron.  Defaults to UTC")
+	flags.IntVar(&theSched.count, "count", 10, "Number of fire times to show")
+	return flags
+}
+// Validate - need either a job-id or a cron expression but not both
+func (theSched *JobSchedNext) Validate() error {
+	if theSched.count < 1 {
+		return errors.New("count must be at least 1")
+	} else if theSched.cron != "" {
+		if theSched.JobID != "" || theSched.SchedID != "" {
+			return errors.New("--cron can't be combined with --job-id or --sched-id")
+		}
+		return nil
+	} else if theSched.tz != "" {
+		return errors.New("--tz only applies to --cron")
+	}
+	return theSched.JobID.Validate()
+}
+// Usage - JobSchedNext flags
+func (theSched *JobSchedNext) Usage(writer io.Writer) {
+	flags := flag.NewFlagSet("schedule next", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+// Parse - JobSchedNext flags but returns self as CommandExec when valid
+func (theSched *JobSchedNext) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schedule next", flag.ExitOnError)
+	theSched.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theSched.Validate(); err != nil {
+		panic(err)
+	} else {
+		return theSched, nil
+	}
+}
+// Execute - fetches the schedule(s) unless previewing an ad-hoc cron, then computes their next fire times
+func (theSched *JobSchedNext) Execute(runtime *Runtime) (interface{}, error) {
+	var schedules []met.Schedule
+	if theSched.cron != "" {
+		schedules = []met.Schedule{{Cron: theSched.cron, Timezone: theSched.tz, Enabled: true}}
+	} else if theSched.SchedID != "" {
+		sched, err := runtime.client.GetSchedule(string(theSched.JobID), string(theSched.SchedID))
+		if err != nil {
+			return nil, err
+		}
+		schedules = []met.Schedule{*sched}
+	} else {
+		scheds, err := runtime.client.Schedules(string(theSched.JobID))
+		if err != nil {
+			return nil, err
+		}
+		schedules = *scheds
+	}
+	now := time.Now()
+	forecasts := make([]ScheduleForecast, 0, len(schedules))
+	for i := range schedules {
+		forecast, err := forecastSchedule(&schedules[i], now, theSched.count)
+		if err != nil {
+			return nil, err
+		}
+		forecast.JobID = string(theSched.JobID)
+		forecasts = append(forecasts, *forecast)
+	}
+	return forecasts, nil
+}
+// forecastSchedule - next fire times for a schedule with warnings for schedules that won't behave as expected
+func forecastSchedule(sched *met.Schedule, from time.Time, count int) (*ScheduleForecast, error) {
+	loc, err := sched.Location()
+	if err != nil {
+		return nil, fmt.Errorf("schedule '%s' timezone: %s", sched.ID, err)
+	}
+	times, err := sched.NextRuns(from, count)
+	if err != nil {
+		return nil, fmt.Errorf("schedule '%s': %s", sched.ID, err)
+	}
+	forecast := &ScheduleForecast{
+		SchedID:  sched.ID,
+		Cron:     sched.Cron,
+		Timezone: loc.String(),
+		Next:     make([]FireTime, 0, len(times)),
+	}
+	for _, t := range times {
+		forecast.Next = append(forecast.Next, FireTime{
+			Schedule: t.Format(time.RFC3339),
+			Local:    t.Local().Format(time.RFC3339),
+		})
+	}
+	if !sched.Enabled {
+		forecast.Warnings = append(forecast.Warnings, "schedule is disabled and will not fire")
+	}
+	if len(times) == 0 {
+		forecast.Warnings = append(forecast.Warnings, "expression never fires again")
+	} else if len(times) < count {
+		forecast.Warnings = append(forecast.Warnings, fmt.Sprintf("expression stops firing after %s", times[len(times)-1].Format(time.RFC3339)))
+	}
+	for i := 1; i < len(times); i++ {
+		if times[i].Sub(times[i-1]) <= time.Minute {
+			forecast.Warnings = append(forecast.Warnings, "fires every minute, Metronome's finest granularity; a run that takes longer than a minute will overlap the next")
+			break
+		}
+	}
+	return forecast, nil
+}
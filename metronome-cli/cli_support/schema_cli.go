@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Schema types `schema -type` accepts
+const (
+	SchemaJob      = "job"
+	SchemaSchedule = "schedule"
+)
+
+// SchemaPrint - writes the JSON Schema for job or schedule definition files
+type SchemaPrint struct {
+	schemaType string
+	output     string
+}
+
+// FlagSet - the schema type and output file
+func (theSchema *SchemaPrint) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theSchema.schemaType, "type", SchemaJob, "Schema to write.  One of job,schedule")
+	flags.StringVar(&theSchema.output, "o", "", "Write the schema to a file instead of stdout")
+	return flags
+}
+
+// Validate - a known schema type
+func (theSchema *SchemaPrint) Validate() error {
+	if !In(theSchema.schemaType, []string{SchemaJob, SchemaSchedule}) {
+		return fmt.Errorf("-type must be one of %s,%s not '%s'", SchemaJob, SchemaSchedule, theSchema.schemaType)
+	}
+	return nil
+}
+
+// Usage - schema usage
+func (theSchema *SchemaPrint) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "schema\n\tWrite the JSON Schema for job or schedule definition files\n")
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	theSchema.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - schema flags.  Returns self as CommandExec when valid
+func (theSchema *SchemaPrint) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	theSchema.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theSchema.Validate(); err != nil {
+		panic(err)
+	}
+	return theSchema, nil
+}
+
+// Offline - OfflineCommand implementation.  The schema comes from the models, not the cluster
+func (theSchema *SchemaPrint) Offline() bool {
+	return true
+}
+
+// Execute - writes the schema
+func (theSchema *SchemaPrint) Execute(runtime *Runtime) (interface{}, error) {
+	schema := met.JobSchema()
+	if theSchema.schemaType == SchemaSchedule {
+		schema = met.ScheduleSchema()
+	}
+	doc, err := schema.MarshalIndent()
+	if err != nil {
+		return nil, err
+	}
+	if theSchema.output != "" {
+		err = ioutil.WriteFile(theSchema.output, doc, 0644)
+	} else {
+		_, err = os.Stdout.Write(doc)
+	}
+	return nil, err
+}
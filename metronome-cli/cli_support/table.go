@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/backfill"
+	"github.com/adobe-platform/go-metronome/metronome/dag"
+	"github.com/adobe-platform/go-metronome/metronome/lint"
+	"github.com/adobe-platform/go-metronome/metronome/monitor"
+)
+
+// Output formats selected by the global -output flag
+const (
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+	OutputTable = "table"
+)
+
+// WriteTable - render results that have a tabular form.  Returns false when the result only renders as json
+func WriteTable(writer io.Writer, result interface{}) bool {
+	tw := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	switch result := result.(type) {
+	case *met.Schedule:
+		scheduleTable(tw, []met.Schedule{*result})
+	case *[]met.Schedule:
+		scheduleTable(tw, *result)
+	case []met.Schedule:
+		scheduleTable(tw, result)
+	case *met.Job:
+		jobTable(tw, []met.Job{*result})
+	case *[]met.Job:
+		jobTable(tw, *result)
+	case []ScheduleForecast:
+		forecastTable(tw, result)
+	case []met.Change:
+		changeTable(tw, result)
+	case []lint.Finding:
+		findingTable(tw, result)
+	case *dag.State:
+		stateTable(tw, result)
+	case []backfill.FireTime:
+		fireTimeTable(tw, result)
+	case []backfill.Result:
+		backfillTable(tw, result)
+	case []monitor.Violation:
+		violationTable(tw, result)
+	default:
+		return false
+	}
+	tw.Flush()
+	return true
+}
+
+// writeOutput - a result as json, yaml or, when it has a table form, a table.  For commands that write their result
+// before failing
+func writeOutput(writer io.Writer, output string, result interface{}) error {
+	switch output {
+	case OutputTable:
+		if WriteTable(writer, result) {
+			return nil
+		}
+	case OutputYAML:
+		doc, err := met.ToYAML(result)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(doc)
+		return err
+	}
+	doc, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(doc))
+	return err
+}
+
+// explain - a schedule's english description or why it couldn't be produced
+func explain(sched *met.Schedule) string {
+	text, err := sched.Explain()
+	if err != nil {
+		return "invalid: " + err.Error()
+	}
+	return text
+}
+
+func scheduleTable(writer io.Writer, schedules []met.Schedule) {
+	fmt.Fprintln(writer, "ID\tCRON\tTIMEZONE\tENABLED\tDESCRIPTION")
+	for i := range schedules {
+		sched := &schedules[i]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%t\t%s\n", sched.ID, sched.Cron, sched.Timezone, sched.Enabled, explain(sched))
+	}
+}
+
+func jobTable(writer io.Writer, jobs []met.Job) {
+	fmt.Fprintln(writer, "ID\tCPUS\tMEM\tDISK\tSCHEDULES")
+	for _, job := range jobs {
+		var cpus float64
+		var mem, disk int
+		if job.Run != nil {
+			cpus, mem, disk = job.Run.Cpus, job.Run.Mem, job.Run.Disk
+		}
+		scheds := make([]string, 0, len(job.Schedules))
+		for _, sched := range job.Schedules {
+			if sched != nil {
+				scheds = append(scheds, fmt.Sprintf("%s: %s", sched.ID, explain(sched)))
+			}
+		}
+		if len(scheds) == 0 {
+			scheds = append(scheds, "-")
+		}
+		fmt.Fprintf(writer, "%s\t%v\t%d\t%d\t%s\n", job.ID, cpus, mem, disk, strings.Join(scheds, "; "))
+	}
+}
+
+func forecastTable(writer io.Writer, forecasts []ScheduleForecast) {
+	fmt.Fprintln(writer, "SCHEDULE\tCRON\tTIMEZONE\tFIRES\tLOCAL")
+	for _, forecast := range forecasts {
+		for _, fire := range forecast.Next {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", forecast.SchedID, forecast.Cron, forecast.Timezone, fire.Schedule, fire.Local)
+		}
+		for _, warning := range forecast.Warnings {
+			fmt.Fprintf(writer, "%s\t%s\t%s\twarning: %s\t\n", forecast.SchedID, forecast.Cron, forecast.Timezone, warning)
+		}
+	}
+}
+
+func changeTable(writer io.Writer, changes []met.Change) {
+	fmt.Fprintln(writer, "PATH\tFROM\tTO")
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", change.Path, changeValue(change.From), changeValue(change.To))
+	}
+}
+
+func findingTable(writer io.Writer, findings []lint.Finding) {
+	fmt.Fprintln(writer, "SEVERITY\tRULE\tFILE\tJOB\tPATH\tMESSAGE")
+	for _, finding := range findings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", finding.Severity, finding.Rule, dash(finding.File), dash(finding.Job), dash(finding.Path), finding.Message)
+	}
+}
+
+func fireTimeTable(writer io.Writer, fires []backfill.FireTime) {
+	fmt.Fprintln(writer, "SCHEDULE\tTIME\tRUN")
+	for _, fire := range fires {
+		run := fire.RunID
+		if run == "" {
+			run = "missed"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", fire.Schedule, fire.Time.Format(time.RFC3339), run)
+	}
+}
+
+func backfillTable(writer io.Writer, results []backfill.Result) {
+	fmt.Fprintln(writer, "SCHEDULE\tTIME\tJOB\tRUN\tSTATUS\tERROR")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Schedule, result.Time.Format(time.RFC3339), result.BackfillJob, dash(result.RunID), dash(string(result.Status)), dash(result.Error))
+	}
+}
+
+func violationTable(writer io.Writer, violations []monitor.Violation) {
+	fmt.Fprintln(writer, "KIND\tJOB\tSCHEDULE\tRUN\tAT\tMESSAGE")
+	for _, violation := range violations {
+		when := "-"
+		if !violation.At.IsZero() {
+			when = violation.At.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", violation.Kind, violation.Job, dash(violation.Schedule), dash(violation.RunID), when, violation.Message)
+	}
+}
+
+func stateTable(writer io.Writer, state *dag.State) {
+	names := make([]string, 0, len(state.Steps))
+	for name := range state.Steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(writer, "STEP\tJOB\tSTATUS\tRUN\tATTEMPTS\tERROR")
+	for _, name := range names {
+		step := state.Steps[name]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\t%s\n", name, step.Job, step.Status, dash(step.RunID), step.Attempts, dash(step.Error))
+	}
+}
+
+// dash - s, or - when it is empty
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// changeValue - a changed value as json, or - when the value is absent
+func changeValue(value interface{}) string {
+	if value == nil {
+		return "-"
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
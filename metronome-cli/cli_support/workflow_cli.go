@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/adobe-platform/go-metronome/metronome/dag"
+	log "github.com/behance/go-logrus"
+)
+
+// WorkflowTopLevel - top-level cli menu for workflows, DAGs of jobs run in dependency order
+type WorkflowTopLevel struct {
+	subcommand string
+	task       CommandParse
+}
+
+// Usage - workflow toplevel usage
+func (theWorkflow *WorkflowTopLevel) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "workflow {run|validate|status}  \n")
+	fmt.Fprintln(writer, `
+	  run       <options>  | Run a workflow's jobs, each after the jobs it depends on succeed
+	  validate  <options>  | Check a workflow file
+	  status    <options>  | Show a workflow's saved progress
+	`)
+}
+
+// Parse - parses out actions, delegates deeper parsing to action specific CommandParse implementations
+func (theWorkflow *WorkflowTopLevel) Parse(args []string) (exec CommandExec, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			fmt.Fprintln(buf, r.(error).Error())
+			fmt.Fprintf(buf, "\nworkflow %s usage:\n", theWorkflow.subcommand)
+			if theWorkflow.task != nil {
+				theWorkflow.task.Usage(buf)
+			}
+			theWorkflow.Usage(buf)
+			err = errors.New(buf.String())
+		}
+	}()
+	if len(args) == 0 {
+		panic(errors.New("sub command required"))
+	}
+	theWorkflow.subcommand = args[0]
+	switch theWorkflow.subcommand {
+	case "run":
+		theWorkflow.task = CommandParse(new(WorkflowRun))
+	case "validate":
+		theWorkflow.task = CommandParse(new(WorkflowValidate))
+	case "status":
+		theWorkflow.task = CommandParse(new(WorkflowStatus))
+	case "help", "--help":
+		panic(errors.New("Please help"))
+	default:
+		panic(fmt.Errorf("workflow Don't understand '%s'", theWorkflow.subcommand))
+	}
+	var subcommandArgs []string
+	if len(args) > 1 {
+		subcommandArgs = args[1:]
+	}
+	log.Debugf("workflow %s args: %+v", theWorkflow.subcommand, subcommandArgs)
+	exec, err = theWorkflow.task.Parse(subcommandArgs)
+	if err != nil {
+		panic(fmt.Errorf("WorkflowTopLevel parse failed %+v", err))
+	}
+	return exec, nil
+}
+
+// WorkflowFile - the -f flag naming a workflow file
+type WorkflowFile struct {
+	file     string
+	workflow *dag.Workflow
+}
+
+// FlagSet - the -f flag
+func (theFile *WorkflowFile) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theFile.file, "f", "", "Workflow file (yaml or json): a name and steps, each a job with the steps it comes after")
+	return flags
+}
+
+// Validate - loads and checks the workflow
+func (theFile *WorkflowFile) Validate() error {
+	if theFile.file == "" {
+		return errors.New("-f required")
+	}
+	data, err := ioutil.ReadFile(theFile.file)
+	if err != nil {
+		return err
+	}
+	if theFile.workflow, err = dag.LoadWorkflow(data); err != nil {
+		return fmt.Errorf("%s: %s", theFile.file, err)
+	}
+	return nil
+}
+
+// WorkflowRun - runs a workflow, saving its progress to a state file
+type WorkflowRun struct {
+	WorkflowFile
+	state  string
+	resume bool
+}
+
+// FlagSet - the workflow, its state file and whether to resume
+func (theRun *WorkflowRun) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	theRun.WorkflowFile.FlagSet(flags)
+	flags.StringVar(&theRun.state, "state", "", "State file the progress is saved to.  Defaults to <workflow name>.state.json")
+	flags.BoolVar(&theRun.resume, "resume", false, "Continue from the state file: succeeded steps aren't run again, running steps are waited for and failed steps are retried")
+	return flags
+}
+
+// Validate - a valid workflow.  An existing state file needs -resume, so a finished workflow isn't silently continued
+func (theRun *WorkflowRun) Validate() error {
+	if err := theRun.WorkflowFile.Validate(); err != nil {
+		return err
+	}
+	if theRun.state == "" {
+		theRun.state = theRun.workflow.Name + ".state.json"
+	}
+	_, err := os.Stat(theRun.state)
+	if theRun.resume && err != nil {
+		return fmt.Errorf("-resume: %s", err)
+	} else if !theRun.resume && err == nil {
+		return fmt.Errorf("%s exists; use -resume to continue the workflow or remove it to start again", theRun.state)
+	}
+	return nil
+}
+
+// Usage - workflow run usage
+func (theRun *WorkflowRun) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "workflow run\n\tStart each step's job once the steps it comes after succeed and wait for the workflow to finish\n")
+	flags := flag.NewFlagSet("workflow run", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - the command line flags
+func (theRun *WorkflowRun) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("workflow run", flag.ExitOnError)
+	theRun.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theRun.Validate(); err != nil {
+		panic(err)
+	}
+	return theRun, nil
+}
+
+// Execute - runs the workflow until it finishes or is interrupted, returning its final state.  Interrupting leaves
+// the running jobs going; -resume picks them up again
+func (theRun *WorkflowRun) Execute(runtime *Runtime) (interface{}, error) {
+	runner := &dag.Runner{Client: runtime.client, Workflow: theRun.workflow, StateFile: theRun.state}
+	if theRun.resume {
+		state, err := dag.LoadState(theRun.state)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.Resume(theRun.workflow); err != nil {
+			return nil, fmt.Errorf("%s: %s", theRun.state, err)
+		}
+		runner.State = state
+	}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		if sig, ok := <-signals; ok {
+			log.Infof("%s; saving the workflow state", sig)
+			close(stop)
+		}
+	}()
+	state, err := runner.Run(stop)
+	if err != nil {
+		return nil, fmt.Errorf("%s; progress is in %s", err, theRun.state)
+	}
+	return state, nil
+}
+
+// WorkflowValidate - checks a workflow file
+type WorkflowValidate struct {
+	WorkflowFile
+}
+
+// Usage - workflow validate usage
+func (theValidate *WorkflowValidate) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "workflow validate\n\tCheck a workflow: known steps, valid policies and no cycles\n")
+	flags := flag.NewFlagSet("workflow validate", flag.ExitOnError)
+	theValidate.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - the command line flags
+func (theValidate *WorkflowValidate) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("workflow validate", flag.ExitOnError)
+	theValidate.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theValidate.Validate(); err != nil {
+		panic(err)
+	}
+	return theValidate, nil
+}
+
+// Offline - OfflineCommand implementation.  Validating needs only the file
+func (theValidate *WorkflowValidate) Offline() bool {
+	return true
+}
+
+// Execute - the workflow, as loaded
+func (theValidate *WorkflowValidate) Execute(runtime *Runtime) (interface{}, error) {
+	return theValidate.workflow, nil
+}
+
+// WorkflowStatus - shows a saved workflow state
+type WorkflowStatus struct {
+	state string
+}
+
+// FlagSet - the state file
+func (theStatus *WorkflowStatus) FlagSet(flags *flag.FlagSet) *flag.FlagSet {
+	flags.StringVar(&theStatus.state, "state", "", "State file written by workflow run")
+	return flags
+}
+
+// Validate - a state file
+func (theStatus *WorkflowStatus) Validate() error {
+	if theStatus.state == "" {
+		return errors.New("-state required")
+	}
+	return nil
+}
+
+// Usage - workflow status usage
+func (theStatus *WorkflowStatus) Usage(writer io.Writer) {
+	fmt.Fprintf(writer, "workflow status\n\tEach step's status, run and error from a state file\n")
+	flags := flag.NewFlagSet("workflow status", flag.ExitOnError)
+	theStatus.FlagSet(flags)
+	flags.SetOutput(writer)
+	flags.PrintDefaults()
+}
+
+// Parse - the command line flags
+func (theStatus *WorkflowStatus) Parse(args []string) (_ CommandExec, err error) {
+	flags := flag.NewFlagSet("workflow status", flag.ExitOnError)
+	theStatus.FlagSet(flags)
+	defer func() {
+		if r := recover(); r != nil {
+			buf := new(bytes.Buffer)
+			flags.SetOutput(buf)
+			fmt.Fprintln(buf, err.Error())
+			err = errors.New(buf.String())
+		}
+	}()
+	if err = flags.Parse(args); err != nil {
+		panic(err)
+	} else if err = theStatus.Validate(); err != nil {
+		panic(err)
+	}
+	return theStatus, nil
+}
+
+// Offline - OfflineCommand implementation.  The state is read from the file
+func (theStatus *WorkflowStatus) Offline() bool {
+	return true
+}
+
+// Execute - the saved state
+func (theStatus *WorkflowStatus) Execute(runtime *Runtime) (interface{}, error) {
+	return dag.LoadState(theStatus.state)
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	//"errors"
 	"encoding/json"
+	met "github.com/adobe-platform/go-metronome/metronome"
 	cli "github.com/adobe-platform/go-metronome/metronome-cli/cli_support"
 )
 
@@ -20,6 +21,13 @@ func init() {
 		"job": cli.CommandParse(new(cli.JobTopLevel)),
 		"run": cli.CommandParse(new(cli.RunsTopLevel)),
 		"schedule": cli.CommandParse(new(cli.SchedTopLevel)),
+		"migrate": cli.CommandParse(new(cli.MigrateTopLevel)),
+		"convert": cli.CommandParse(new(cli.ConvertTopLevel)),
+		"schema": cli.CommandParse(new(cli.SchemaPrint)),
+		"lint": cli.CommandParse(new(cli.Lint)),
+		"workflow": cli.CommandParse(new(cli.WorkflowTopLevel)),
+		"backfill": cli.CommandParse(new(cli.Backfill)),
+		"monitor": cli.CommandParse(new(cli.Monitor)),
 		"metrics": cli.CommandParse(new(cli.Metrics)),
 		"ping": cli.CommandParse(new(cli.Ping)),
 	}
@@ -33,6 +41,13 @@ func usage(msg string) {
 		"job",
 		"run",
 		"schedule",
+		"migrate",
+		"convert",
+		"schema",
+		"lint",
+		"workflow",
+		"backfill",
+		"monitor",
 		"metrics",
 		"ping",
 
@@ -105,11 +120,32 @@ func main() {
 		} else if executor, err := commands[action].Parse(executorArgs); err != nil {
 			log.Fatalf("%s failed because %+v", action, err)
 		} else {
+			if offline, ok := executor.(cli.OfflineCommand); ok {
+				runtime.Offline = offline.Offline()
+			}
+			if err := runtime.Connect(); err != nil {
+				usage(err.Error())
+			}
 			if result, err2 := executor.Execute(runtime); err2 != nil {
 				log.Fatalf("action %s execution failed because %+v", action, err2)
 			} else {
 				log.Debugf("Result type: %T", result)
+				if result == nil {
+					// the command wrote its own output
+					return
+				}
 
+				if runtime.Output == cli.OutputTable && cli.WriteTable(os.Stdout, result) {
+					return
+				}
+				if runtime.Output == cli.OutputYAML {
+					if doc, err := met.ToYAML(result); err != nil {
+						log.Fatalf("action %s result can't be shown as yaml because %+v", action, err)
+					} else {
+						os.Stdout.Write(doc)
+					}
+					return
+				}
 				switch result.(type){
 				case json.RawMessage:
 					var f interface{}
@@ -0,0 +1,129 @@
+// metronome-sync keeps Metronome's jobs and schedules in line with a directory of job definition files, typically
+// a git checkout kept current by something else (a cron'd git pull, git-sync, ...).
+//
+// Every -interval it loads the directory, plans the creates, updates and, with -prune, deletes that make
+// Metronome match and applies them.  Status and the last sync's errors are served as json on -listen; /healthz
+// answers 503 while the last sync failed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/jobspec"
+	"github.com/adobe-platform/go-metronome/metronome/reconcile"
+	log "github.com/behance/go-logrus"
+)
+
+// stringList - a repeatable string flag
+type stringList []string
+
+func (list *stringList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *stringList) Set(value string) error {
+	*list = append(*list, value)
+	return nil
+}
+
+func main() {
+	var (
+		config    = met.NewDefaultConfig()
+		authToken string
+		dir       string
+		interval  time.Duration
+		listen    string
+		once      bool
+		debug     bool
+		render    jobspec.Config
+		values    stringList
+		sets      stringList
+		overlays  stringList
+		syncer    = &reconcile.Syncer{}
+	)
+	flags := flag.NewFlagSet("metronome-sync", flag.ExitOnError)
+	flags.StringVar(&config.URL, "metronome-url", "http://localhost:9000", "Set the Metronome address")
+	flags.StringVar(&authToken, "authorization", "", "Authorization token")
+	flags.StringVar(&config.User, "user", "", "user")
+	flags.StringVar(&config.Pw, "password", "", "password")
+	flags.StringVar(&dir, "dir", "", "Directory of job definition files (.json, .yaml, .yml).  Hidden files and directories are skipped")
+	flags.DurationVar(&interval, "interval", time.Minute, "Time between syncs")
+	flags.BoolVar(&syncer.Options.Prune, "prune", false, "Delete jobs labelled as managed by this sync that are no longer in the directory")
+	flags.StringVar(&syncer.Options.ManagedBy, "managed-by", reconcile.DefaultManagedBy, "Value of the "+reconcile.ManagedByLabel+" label given to synced jobs and looked for by -prune")
+	flags.BoolVar(&syncer.DryRun, "dry-run", false, "Plan and report the actions without applying them")
+	flags.StringVar(&listen, "listen", ":8080", "Address serving the sync status.  Empty disables it")
+	flags.BoolVar(&once, "once", false, "Sync once, print the status and exit non-zero when the sync failed")
+	flags.Var(&values, "values", "Values file; the job files are rendered as job spec templates.  You can call more than once")
+	flags.Var(&sets, "set", "key=value . Overrides a template value.  You can call more than once")
+	flags.StringVar(&render.Environment, "env", "", "Environment whose template values are merged over the rest")
+	flags.Var(&overlays, "overlay", "Partial job definition merged into every rendered job.  You can call more than once")
+	flags.BoolVar(&debug, "debug", false, "Turn on debug")
+	flags.Parse(os.Args[1:])
+
+	log.SetOutput(os.Stderr)
+	if debug {
+		log.SetLevel(log.DebugLevel)
+		config.Debug = true
+	}
+	if dir == "" {
+		flags.Usage()
+		log.Fatalf("-dir required")
+	}
+	if interval <= 0 {
+		log.Fatalf("-interval must be positive")
+	}
+	if authToken != "" {
+		if strings.Contains(authToken, "token=") {
+			config.AuthToken = authToken
+		} else {
+			config.AuthToken = fmt.Sprintf("token=%s", authToken)
+		}
+	}
+	render.ValueFiles, render.Sets, render.Overlays = values, sets, overlays
+
+	client, err := met.NewClient(config)
+	if err != nil {
+		log.Fatalf("can't reach Metronome at %s: %s", config.URL, err)
+	}
+	syncer.Client = client
+	syncer.Load = func() ([]met.Job, error) {
+		return reconcile.LoadDir(dir, &render)
+	}
+
+	if once {
+		status := syncer.Sync()
+		if doc, err := met.ToYAML(status); err == nil {
+			os.Stdout.Write(doc)
+		}
+		if !status.Healthy() {
+			os.Exit(1)
+		}
+		return
+	}
+	if listen != "" {
+		go func() {
+			log.Infof("serving sync status on %s", listen)
+			if err := http.ListenAndServe(listen, syncer); err != nil {
+				log.Fatalf("status server: %s", err)
+			}
+		}()
+	}
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Infof("%s; stopping after the current sync", sig)
+		close(stop)
+	}()
+	log.Infof("syncing %s to %s every %s", dir, config.URL, interval)
+	syncer.Run(interval, stop)
+}
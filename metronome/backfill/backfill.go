@@ -0,0 +1,214 @@
+// Package backfill finds the fire times of a job's schedules that have no run, e.g. because Metronome was down, and
+// starts the missing runs.
+//
+// Metronome can't pass anything to a single run, so each missing run is started from a copy of the job whose
+// environment carries the fire time (LogicalTimeEnv) and schedule (ScheduleEnv) the run stands in for.  The copy has
+// no schedules and is labelled BackfillOfLabel.  Copies are kept so that Find counts their successful and active runs
+// as the runs of their fire times, making a second backfill of the same window start nothing new; once a copy is
+// deleted its fire time looks missed again.
+package backfill
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Environment variables set on backfill runs
+const (
+	// LogicalTimeEnv - the fire time the run stands in for, RFC3339 in the schedule's timezone
+	LogicalTimeEnv = "METRONOME_LOGICAL_TIME"
+	// ScheduleEnv - the id of the schedule that should have fired
+	ScheduleEnv = "METRONOME_SCHEDULE_ID"
+)
+
+// BackfillOfLabel - label naming the job a backfill job copies
+const BackfillOfLabel = "backfill-of"
+
+// DefaultTolerance - how long after a fire time a run may have been created and still count as its run
+const DefaultTolerance = 5 * time.Minute
+
+// MaxFireTimes - the most fire times a window may hold, a guard against backfilling a per-minute schedule for years
+const MaxFireTimes = 10000
+
+// FireTime - when one of a job's schedules fired, or should have
+type FireTime struct {
+	Schedule string    `json:"schedule"`
+	Time     time.Time `json:"time"`
+	// RunID - the run created for it.  Empty when it was missed
+	RunID string `json:"runId,omitempty"`
+	// BackfillJob - the backfill job RunID belongs to.  Empty for a run of the job itself
+	BackfillJob string `json:"backfillJob,omitempty"`
+}
+
+// FireTimes - when the enabled schedules fire from from up to but excluding to, oldest first.  Times are in each
+// schedule's timezone.  When schedule isn't empty only that schedule is used
+func FireTimes(schedules []*met.Schedule, schedule string, from time.Time, to time.Time) ([]FireTime, error) {
+	var fires []FireTime
+	for _, sched := range schedules {
+		if sched == nil || !sched.Enabled || (schedule != "" && sched.ID != schedule) {
+			continue
+		}
+		loc, err := sched.Location()
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %s", sched.ID, err)
+		}
+		expr, err := sched.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("schedule %s: %s", sched.ID, err)
+		}
+		// Next is strictly after, so start just before from to include it
+		for at := expr.Next(from.Add(-time.Nanosecond).In(loc)); !at.IsZero() && at.Before(to); at = expr.Next(at) {
+			if len(fires) == MaxFireTimes {
+				return nil, fmt.Errorf("more than %d fire times between %s and %s", MaxFireTimes, from.Format(time.RFC3339), to.Format(time.RFC3339))
+			}
+			fires = append(fires, FireTime{Schedule: sched.ID, Time: at})
+		}
+	}
+	sort.Stable(byTime(fires))
+	return fires, nil
+}
+
+// Match - set the RunID of each fire time a run was created for: one created at the fire time or up to tolerance
+// after it.  A run is matched to one fire time at most.  Fire times must be oldest first
+func Match(fires []FireTime, runs []met.HistoryStatus, tolerance time.Duration) {
+	runs = append([]met.HistoryStatus{}, runs...)
+	sort.Stable(oldestFirst(runs))
+	used := make([]bool, len(runs))
+	for i := range fires {
+		fire := &fires[i]
+		for j, run := range runs {
+			if used[j] || run.CreatedAt.Before(fire.Time) {
+				continue
+			}
+			if !run.CreatedAt.Before(fire.Time.Add(tolerance)) {
+				break
+			}
+			fire.RunID = run.ID
+			used[j] = true
+			break
+		}
+	}
+}
+
+// Missing - the fire times without a run
+func Missing(fires []FireTime) []FireTime {
+	var missing []FireTime
+	for _, fire := range fires {
+		if fire.RunID == "" {
+			missing = append(missing, fire)
+		}
+	}
+	return missing
+}
+
+// Backfilled - the fire times of jobID that backfill jobs among jobs have a successful or active run for, with
+// RunID and BackfillJob set.  jobs should embed history and active runs.  Fire times whose backfill runs all failed
+// aren't included, so they are backfilled again
+func Backfilled(jobID string, jobs []met.Job) []FireTime {
+	var fires []FireTime
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Labels == nil || (*job.Labels)[BackfillOfLabel] != jobID || job.Run == nil {
+			continue
+		}
+		fireTime, err := time.Parse(time.RFC3339, job.Run.Env[LogicalTimeEnv])
+		if err != nil {
+			continue
+		}
+		fire := FireTime{Schedule: job.Run.Env[ScheduleEnv], Time: fireTime, BackfillJob: job.ID}
+		for _, run := range job.ActiveRuns {
+			if run != nil && !run.Status.IsTerminal() {
+				fire.RunID = run.ID
+			}
+		}
+		if fire.RunID == "" && job.History != nil && len(job.History.SuccessfulFinishedRuns) > 0 {
+			fire.RunID = job.History.SuccessfulFinishedRuns[0].ID
+		}
+		if fire.RunID != "" {
+			fires = append(fires, fire)
+		}
+	}
+	sort.Stable(byTime(fires))
+	return fires
+}
+
+// MatchBackfilled - give each missing fire time the backfill run Backfilled found for it
+func MatchBackfilled(fires []FireTime, backfilled []FireTime) {
+	for i := range fires {
+		fire := &fires[i]
+		for _, done := range backfilled {
+			if fire.RunID == "" && fire.Schedule == done.Schedule && fire.Time.Equal(done.Time) {
+				fire.RunID, fire.BackfillJob = done.RunID, done.BackfillJob
+			}
+		}
+	}
+}
+
+// Query - a new query for the detail Backfilled needs embedded in each job
+func Query() *met.JobQuery {
+	return &met.JobQuery{Embed: []met.Embed{met.EmbedHistory, met.EmbedActiveRuns}}
+}
+
+// Find - the fire times of a job's schedules from from up to to, each matched with the run created for it or, failing
+// that, a run of a backfill job kept for it (see Backfilled).  Finished runs come from the job's history, so fire
+// times older than the runs Metronome keeps look missed
+func Find(client met.Metronome, job *met.Job, schedule string, from time.Time, to time.Time, tolerance time.Duration) ([]FireTime, error) {
+	if !from.Before(to) {
+		return nil, fmt.Errorf("the window's end %s isn't after its start %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	fires, err := FireTimes(job.Schedules, schedule, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(fires) == 0 {
+		return fires, nil
+	}
+	filter := &met.RunFilter{Since: from, Until: to.Add(tolerance)}
+	history, err := client.RunHistory(job.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+	active, err := client.ActiveRuns(job.ID, filter)
+	if err != nil {
+		return nil, err
+	}
+	runs := *history
+	for _, run := range *active {
+		runs = append(runs, met.HistoryStatus{ID: run.ID, CreatedAt: run.CreatedAt, Status: run.Status})
+	}
+	Match(fires, runs, tolerance)
+	if len(Missing(fires)) > 0 {
+		jobs, err := client.QueryJobs(Query())
+		if err != nil {
+			return nil, err
+		}
+		MatchBackfilled(fires, Backfilled(job.ID, *jobs))
+	}
+	return fires, nil
+}
+
+// JobID - the id of the job backfilling fire.  e.g. reports-backfill-nightly-20261019-0200
+func JobID(jobID string, fire FireTime) string {
+	return strings.ToLower(fmt.Sprintf("%s-backfill-%s-%s", jobID, fire.Schedule, fire.Time.Format("20060102-1504")))
+}
+
+// byTime - sort.Interface ordering fire times oldest first
+type byTime []FireTime
+
+func (fires byTime) Len() int           { return len(fires) }
+func (fires byTime) Swap(i, j int)      { fires[i], fires[j] = fires[j], fires[i] }
+func (fires byTime) Less(i, j int) bool { return fires[i].Time.Before(fires[j].Time) }
+
+// oldestFirst - sort.Interface ordering runs by creation time, oldest first
+type oldestFirst []met.HistoryStatus
+
+func (runs oldestFirst) Len() int           { return len(runs) }
+func (runs oldestFirst) Swap(i, j int)      { runs[i], runs[j] = runs[j], runs[i] }
+func (runs oldestFirst) Less(i, j int) bool { return runs[i].CreatedAt.Before(runs[j].CreatedAt.Time) }
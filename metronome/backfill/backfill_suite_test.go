@@ -0,0 +1,13 @@
+package backfill_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestBackfill(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Backfill Suite")
+}
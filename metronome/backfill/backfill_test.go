@@ -0,0 +1,295 @@
+package backfill_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/backfill"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeMetronome - jobs created for backfills whose runs finish with outcome once polled
+type fakeMetronome struct {
+	met.Metronome
+	mutex    sync.Mutex
+	history  []met.HistoryStatus
+	active   []met.JobStatus
+	outcome  map[string]met.RunStatus
+	created  map[string]*met.Job
+	updated  []string
+	deleted  []string
+	runs     map[string]string
+	finished map[string]met.RunStatus
+}
+
+func newFake() *fakeMetronome {
+	return &fakeMetronome{
+		outcome:  map[string]met.RunStatus{},
+		created:  map[string]*met.Job{},
+		runs:     map[string]string{},
+		finished: map[string]met.RunStatus{},
+	}
+}
+
+// QueryJobs - the backfill jobs left, each with the last outcome polled in its history
+func (fake *fakeMetronome) QueryJobs(query *met.JobQuery) (*[]met.Job, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	var jobs []met.Job
+	for id, created := range fake.created {
+		job := *created.DeepCopy()
+		job.History = &met.History{}
+		runID := fmt.Sprintf("run-%s", id)
+		switch status, ok := fake.finished[runID]; {
+		case !ok:
+		case status.IsSuccess():
+			job.History.SuccessfulFinishedRuns = []met.HistoryStatus{{ID: runID}}
+		default:
+			job.History.FailedFinishedRuns = []met.HistoryStatus{{ID: runID}}
+		}
+		jobs = append(jobs, job)
+	}
+	return &jobs, nil
+}
+
+func (fake *fakeMetronome) RunHistory(jobID string, filter *met.RunFilter) (*[]met.HistoryStatus, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	history := filter.FilterHistory(fake.history)
+	return &history, nil
+}
+
+func (fake *fakeMetronome) ActiveRuns(jobID string, filter *met.RunFilter) (*[]met.JobStatus, error) {
+	active := filter.FilterActive(fake.active)
+	return &active, nil
+}
+
+func (fake *fakeMetronome) CreateJob(job *met.Job) (*met.Job, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if _, ok := fake.created[job.ID]; ok {
+		return nil, errors.New("job " + job.ID + " exists")
+	}
+	fake.created[job.ID] = job
+	return job, nil
+}
+
+func (fake *fakeMetronome) UpdateJob(jobID string, job *met.Job) (interface{}, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if _, ok := fake.created[job.ID]; !ok {
+		return nil, errors.New("no job " + job.ID)
+	}
+	fake.created[job.ID] = job
+	fake.updated = append(fake.updated, job.ID)
+	return job, nil
+}
+
+func (fake *fakeMetronome) DeleteJob(jobID string) (interface{}, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	delete(fake.created, jobID)
+	fake.deleted = append(fake.deleted, jobID)
+	return nil, nil
+}
+
+func (fake *fakeMetronome) StartJob(jobID string) (interface{}, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	if _, ok := fake.created[jobID]; !ok {
+		return nil, errors.New("no job " + jobID)
+	}
+	runID := fmt.Sprintf("run-%s", jobID)
+	fake.runs[runID] = jobID
+	return met.JobStatus{ID: runID, JobID: jobID}, nil
+}
+
+func (fake *fakeMetronome) StatusJob(jobID string, runID string) (*met.JobStatus, error) {
+	fake.mutex.Lock()
+	defer fake.mutex.Unlock()
+	status, ok := fake.outcome[jobID]
+	if !ok {
+		status = met.RunSuccess
+	}
+	fake.finished[runID] = status
+	return &met.JobStatus{ID: runID, JobID: jobID, Status: status}, nil
+}
+
+func at(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	Expect(err).ToNot(HaveOccurred())
+	return t
+}
+
+func stamp(value string) met.Timestamp {
+	return met.Timestamp{Time: at(value)}
+}
+
+func reportsJob() *met.Job {
+	labels := met.Labels{"owner": "data", "managed-by": "metronome-sync"}
+	return &met.Job{
+		ID:     "reports",
+		Labels: &labels,
+		Run:    &met.Run{Cpus: 0.5, Mem: 128, MaxLaunchDelay: 3600, Cmd: "report", Env: map[string]string{"MODE": "full"}},
+		Schedules: []*met.Schedule{
+			{ID: "nightly", Cron: "0 2 * * *", Timezone: "America/New_York", Enabled: true},
+			{ID: "hourly", Cron: "30 */6 * * *", Enabled: true},
+			{ID: "off", Cron: "* * * * *", Enabled: false},
+		},
+	}
+}
+
+var _ = Describe("FireTimes", func() {
+	It("lists the enabled schedules' fire times in the window, oldest first", func() {
+		fires, err := FireTimes(reportsJob().Schedules, "", at("2026-10-18T06:00:00Z"), at("2026-10-19T06:30:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		var times []string
+		for _, fire := range fires {
+			times = append(times, fire.Schedule+" "+fire.Time.UTC().Format(time.RFC3339))
+		}
+		Expect(times).To(Equal([]string{
+			"nightly 2026-10-18T06:00:00Z",
+			"hourly 2026-10-18T06:30:00Z",
+			"hourly 2026-10-18T12:30:00Z",
+			"hourly 2026-10-18T18:30:00Z",
+			"hourly 2026-10-19T00:30:00Z",
+			"nightly 2026-10-19T06:00:00Z",
+		}))
+		Expect(fires[0].Time.Location().String()).To(Equal("America/New_York"))
+	})
+	It("lists one schedule", func() {
+		fires, err := FireTimes(reportsJob().Schedules, "nightly", at("2026-10-18T06:00:00Z"), at("2026-10-20T00:00:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fires).To(HaveLen(2))
+	})
+	It("refuses huge windows", func() {
+		schedules := []*met.Schedule{{ID: "minutely", Cron: "* * * * *", Enabled: true}}
+		_, err := FireTimes(schedules, "", at("2026-01-01T00:00:00Z"), at("2026-12-31T00:00:00Z"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Match", func() {
+	It("matches each run to one fire time within the tolerance", func() {
+		fires := []FireTime{
+			{Schedule: "a", Time: at("2026-10-18T02:00:00Z")},
+			{Schedule: "b", Time: at("2026-10-18T02:00:00Z")},
+			{Schedule: "a", Time: at("2026-10-18T03:00:00Z")},
+			{Schedule: "a", Time: at("2026-10-18T04:00:00Z")},
+		}
+		runs := []met.HistoryStatus{
+			{ID: "late", CreatedAt: stamp("2026-10-18T03:20:00Z")},
+			{ID: "first", CreatedAt: stamp("2026-10-18T02:00:01Z")},
+			{ID: "early", CreatedAt: stamp("2026-10-18T03:59:00Z")},
+		}
+		Match(fires, runs, 5*time.Minute)
+		Expect(fires[0].RunID).To(Equal("first"))
+		Expect(Missing(fires)).To(Equal([]FireTime{fires[1], fires[2], fires[3]}))
+	})
+})
+
+var _ = Describe("Find", func() {
+	It("finds fire times without runs", func() {
+		fake := newFake()
+		fake.history = []met.HistoryStatus{{ID: "r1", CreatedAt: stamp("2026-10-18T06:30:02Z"), Status: met.RunFailed}}
+		fake.active = []met.JobStatus{{ID: "r2", CreatedAt: stamp("2026-10-19T00:31:00Z"), Status: met.RunActive}}
+		fires, err := Find(fake, reportsJob(), "hourly", at("2026-10-18T06:00:00Z"), at("2026-10-19T06:00:00Z"), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fires).To(HaveLen(4))
+		Expect(fires[0].RunID).To(Equal("r1"))
+		Expect(fires[3].RunID).To(Equal("r2"))
+		Expect(Missing(fires)).To(HaveLen(2))
+	})
+	It("counts the successful and active runs of kept backfill jobs", func() {
+		job := reportsJob()
+		fires, err := FireTimes(job.Schedules, "hourly", at("2026-10-18T06:00:00Z"), at("2026-10-19T06:00:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+		done, failed, running := Job(job, fires[0]), Job(job, fires[1]), Job(job, fires[2])
+		done.History = &met.History{SuccessfulFinishedRuns: []met.HistoryStatus{{ID: "b1"}}}
+		failed.History = &met.History{FailedFinishedRuns: []met.HistoryStatus{{ID: "b2"}}}
+		running.ActiveRuns = []*met.ActiveRun{{ID: "b3", Status: met.RunActive}}
+		other := Job(&met.Job{ID: "other", Run: job.Run}, fires[3])
+		other.History = done.History
+		jobs := []met.Job{*done, *failed, *running, *other, *job}
+		backfilled := Backfilled("reports", jobs)
+		Expect(backfilled).To(HaveLen(2))
+		Expect(backfilled[0].BackfillJob).To(Equal(done.ID))
+		Expect(backfilled[0].RunID).To(Equal("b1"))
+		Expect(backfilled[1].RunID).To(Equal("b3"))
+		MatchBackfilled(fires, backfilled)
+		Expect(Missing(fires)).To(Equal([]FireTime{fires[1], fires[3]}))
+	})
+	It("rejects empty windows", func() {
+		_, err := Find(newFake(), reportsJob(), "", at("2026-10-19T06:00:00Z"), at("2026-10-18T06:00:00Z"), 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Runner", func() {
+	var fires []FireTime
+	BeforeEach(func() {
+		var err error
+		fires, err = FireTimes(reportsJob().Schedules, "", at("2026-10-18T06:00:00Z"), at("2026-10-18T13:00:00Z"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("copies the job without schedules, with the fire time in its environment", func() {
+		job := Job(reportsJob(), fires[0])
+		Expect(job.ID).To(Equal("reports-backfill-nightly-20261018-0200"))
+		Expect(job.Schedules).To(BeNil())
+		Expect(*job.Labels).To(Equal(met.Labels{"owner": "data", BackfillOfLabel: "reports"}))
+		Expect(job.Run.Env).To(Equal(map[string]string{
+			"MODE":         "full",
+			LogicalTimeEnv: "2026-10-18T02:00:00-04:00",
+			ScheduleEnv:    "nightly",
+		}))
+		Expect(reportsJob().Run.Env).To(HaveLen(1))
+		Expect(job.Validate()).To(Succeed())
+	})
+	It("runs each missed fire time from a kept job, so backfilling again only reruns failures", func() {
+		fake := newFake()
+		fake.outcome["reports-backfill-hourly-20261018-1230"] = met.RunFailed
+		runner := &Runner{Client: fake, Job: reportsJob(), Concurrency: 2, PollInterval: time.Millisecond}
+		results := runner.Run(fires)
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].RunID).To(Equal("run-reports-backfill-nightly-20261018-0200"))
+		Expect(results[0].BackfillJob).To(Equal("reports-backfill-nightly-20261018-0200"))
+		Expect(results[0].Status).To(Equal(met.RunSuccess))
+		Expect(results[2].Status).To(Equal(met.RunFailed))
+		Expect(Failed(results)).To(Equal([]Result{results[2]}))
+		Expect(fake.deleted).To(BeEmpty())
+		Expect(fake.created).To(HaveLen(3))
+
+		again, err := Find(fake, reportsJob(), "", at("2026-10-18T06:00:00Z"), at("2026-10-18T13:00:00Z"), 0)
+		Expect(err).ToNot(HaveOccurred())
+		missing := Missing(again)
+		Expect(missing).To(HaveLen(1))
+		Expect(missing[0].Time.Equal(at("2026-10-18T12:30:00Z"))).To(BeTrue())
+
+		delete(fake.outcome, "reports-backfill-hourly-20261018-1230")
+		Expect(Failed(runner.Run(missing))).To(BeEmpty())
+		Expect(fake.updated).To(Equal([]string{"reports-backfill-hourly-20261018-1230"}))
+		again, err = Find(fake, reportsJob(), "", at("2026-10-18T06:00:00Z"), at("2026-10-18T13:00:00Z"), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Missing(again)).To(BeEmpty())
+	})
+	It("deletes the jobs when asked, so their fire times look missed again", func() {
+		fake := newFake()
+		runner := &Runner{Client: fake, Job: reportsJob(), DeleteJobs: true, PollInterval: time.Millisecond}
+		Expect(Failed(runner.Run(fires))).To(BeEmpty())
+		sort.Strings(fake.deleted)
+		Expect(fake.deleted).To(Equal([]string{
+			"reports-backfill-hourly-20261018-0630",
+			"reports-backfill-hourly-20261018-1230",
+			"reports-backfill-nightly-20261018-0200",
+		}))
+		again, err := Find(fake, reportsJob(), "", at("2026-10-18T06:00:00Z"), at("2026-10-18T13:00:00Z"), 0)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(Missing(again)).To(HaveLen(3))
+	})
+})
@@ -0,0 +1,168 @@
+package backfill
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/reconcile"
+	log "github.com/behance/go-logrus"
+)
+
+// DefaultPollInterval - how often a backfill run's status is checked
+const DefaultPollInterval = 10 * time.Second
+
+// Result - the backfill run of a missed fire time.  FireTime.RunID is the backfill run and FireTime.BackfillJob the job
+// it was started from
+type Result struct {
+	FireTime
+	Status met.RunStatus `json:"status,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// Runner - starts runs for missed fire times of Job
+type Runner struct {
+	Client met.Metronome
+	Job    *met.Job
+	// Concurrency - how many backfill runs go at once.  Defaults to 1, one after the other
+	Concurrency int
+	// PollInterval - how often run statuses are checked.  Defaults to DefaultPollInterval
+	PollInterval time.Duration
+	// DeleteJobs - delete each backfill job once its run finishes.  Find then no longer sees the run, so backfilling
+	// the same window again runs the fire time again
+	DeleteJobs bool
+}
+
+// Run - backfill each fire time, oldest first, waiting for each run to finish.  Results are in the order of fires
+func (runner *Runner) Run(fires []FireTime) []Result {
+	concurrency := runner.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	results := make([]Result, len(fires))
+	slots := make(chan struct{}, concurrency)
+	var wait sync.WaitGroup
+	for i, fire := range fires {
+		slots <- struct{}{}
+		wait.Add(1)
+		go func(i int, fire FireTime) {
+			defer func() {
+				<-slots
+				wait.Done()
+			}()
+			results[i] = runner.run(fire)
+		}(i, fire)
+	}
+	wait.Wait()
+	return results
+}
+
+// Failed - the results whose run didn't succeed
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, result := range results {
+		if result.Error != "" || !result.Status.IsSuccess() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Job - the job backfilling fire: a copy of job with no schedules, labelled BackfillOfLabel, with the fire
+// time and schedule in its environment
+func Job(job *met.Job, fire FireTime) *met.Job {
+	out := job.DeepCopy()
+	out.ID = JobID(job.ID, fire)
+	out.Schedules = nil
+	out.ActiveRuns = nil
+	out.History = nil
+	out.HistorySummary = nil
+	labels := met.Labels{}
+	if out.Labels != nil {
+		labels = *out.Labels
+	}
+	// metronome-sync would prune a copy that looked like one of its jobs
+	delete(labels, reconcile.ManagedByLabel)
+	labels[BackfillOfLabel] = job.ID
+	out.Labels = &labels
+	if out.Run == nil {
+		out.Run = &met.Run{}
+	}
+	env := make(map[string]string, len(out.Run.Env)+2)
+	for name, value := range out.Run.Env {
+		env[name] = value
+	}
+	env[LogicalTimeEnv] = fire.Time.Format(time.RFC3339)
+	env[ScheduleEnv] = fire.Schedule
+	out.Run.Env = env
+	return out
+}
+
+func (runner *Runner) run(fire FireTime) Result {
+	job := Job(runner.Job, fire)
+	fire.BackfillJob = job.ID
+	result := Result{FireTime: fire}
+	if err := job.Validate(); err != nil {
+		result.Error = fmt.Sprintf("job %s: %s", job.ID, err)
+		return result
+	}
+	if _, err := runner.Client.CreateJob(job); err != nil {
+		// kept from an earlier backfill whose run failed
+		if _, updateErr := runner.Client.UpdateJob(job.ID, job); updateErr != nil {
+			result.Error = fmt.Sprintf("can't create job %s: %s", job.ID, err)
+			return result
+		}
+	}
+	status, runID, err := runner.start(job.ID)
+	result.RunID, result.Status = runID, status
+	at := fire.Time.Format(time.RFC3339)
+	if err != nil {
+		result.Error = err.Error()
+		log.Errorf("backfill of %s at %s: %s", fire.Schedule, at, err)
+	} else if status.IsSuccess() {
+		log.Infof("backfill of %s at %s: run %s of %s succeeded", fire.Schedule, at, runID, job.ID)
+	} else {
+		log.Errorf("backfill of %s at %s: run %s of %s failed", fire.Schedule, at, runID, job.ID)
+	}
+	if runner.DeleteJobs {
+		if _, err := runner.Client.DeleteJob(job.ID); err != nil {
+			log.Warnf("can't delete backfill job %s: %s", job.ID, err)
+		}
+	}
+	return result
+}
+
+// start - start a run of jobID and wait for its outcome
+func (runner *Runner) start(jobID string) (met.RunStatus, string, error) {
+	started, err := runner.Client.StartJob(jobID)
+	if err != nil {
+		return "", "", fmt.Errorf("can't start %s: %s", jobID, err)
+	}
+	var runID string
+	switch run := started.(type) {
+	case met.JobStatus:
+		runID = run.ID
+	case *met.JobStatus:
+		if run != nil {
+			runID = run.ID
+		}
+	}
+	if runID == "" {
+		return "", "", errors.New("no run id in the response")
+	}
+	interval := runner.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	for {
+		status, err := met.RunOutcome(runner.Client, jobID, runID)
+		if err != nil {
+			log.Warnf("can't get the status of run %s of %s: %s", runID, jobID, err)
+		} else if status.IsTerminal() {
+			return status, runID, nil
+		}
+		time.Sleep(interval)
+	}
+}
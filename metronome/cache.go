@@ -0,0 +1,105 @@
+package metronome
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiResponse - the parts of an http response apiCall needs.  Bodies are read in full so they can be cached.
+type apiResponse struct {
+	status        int
+	statusText    string
+	header        http.Header
+	contentLength int64
+	body          []byte
+	stored        time.Time
+}
+
+// etag - the ETag validator metronome sent, if any
+func (resp *apiResponse) etag() string {
+	return resp.header.Get("ETag")
+}
+
+// lastModified - the Last-Modified validator metronome sent, if any
+func (resp *apiResponse) lastModified() string {
+	return resp.header.Get("Last-Modified")
+}
+
+// hasValidators - true when the response can be revalidated with a conditional GET
+func (resp *apiResponse) hasValidators() bool {
+	return resp.etag() != "" || resp.lastModified() != ""
+}
+
+// responseCache - GET responses keyed by url+query
+//  - responses carrying ETag or Last-Modified are stored for any read and always revalidated with a conditional GET
+//  - responses without validators are stored for definition reads only (see definitionRead) and served from the
+//    cache until they are older than ttl
+//  - any create/update/delete made through the owning client invalidates every entry
+type responseCache struct {
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]*apiResponse
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]*apiResponse),
+	}
+}
+
+// definitionRead - whether the read is of job and schedule definitions only, so a response without validators can be
+// served for ttl without asking metronome.  Runs, job reads embedding run state (history, historySummary,
+// activeRuns), metrics and ping are always asked for, unless metronome answers 304, so callers polling a run never
+// see it stale
+func definitionRead(uri string, query url.Values) bool {
+	if !strings.HasPrefix(uri, MetronomeAPIJobList) {
+		return false
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(uri, MetronomeAPIJobList), "/"), "/")
+	switch {
+	case len(parts) == 1:
+		// the job list or a job
+	case len(parts) <= 3 && parts[1] == "schedules":
+		return true
+	default:
+		return false
+	}
+	for _, embed := range query["embed"] {
+		if embed != string(EmbedSchedules) {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup - the entry stored under key and whether it can be returned without asking metronome, which only entries
+// of definition reads without validators can
+func (cache *responseCache) lookup(key string, now time.Time, definition bool) (*apiResponse, bool) {
+	cache.Lock()
+	defer cache.Unlock()
+	entry := cache.entries[key]
+	if entry == nil {
+		return nil, false
+	}
+	return entry, definition && !entry.hasValidators() && now.Sub(entry.stored) < cache.ttl
+}
+
+// put - store a copy of entry under key.  Entries already stored are never changed, so they can be read unlocked
+func (cache *responseCache) put(key string, entry *apiResponse) {
+	stored := *entry
+	stored.stored = time.Now()
+	cache.Lock()
+	defer cache.Unlock()
+	cache.entries[key] = &stored
+}
+
+// invalidate - drop all entries.  Called after the client changes state on metronome
+func (cache *responseCache) invalidate() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.entries = make(map[string]*apiResponse)
+}
@@ -0,0 +1,141 @@
+package metronome_test
+
+import (
+	"net/http"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	ghttp "github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Response cache", func() {
+	var (
+		config_stub Config
+		client      Metronome
+		server      *ghttp.Server
+		job         = Job{ID: "foo.bar", Run: &Run{Cpus: 0.2, Mem: 128, Disk: 128}}
+		definition  = &JobQuery{Embed: []Embed{EmbedSchedules}}
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs"),
+			),
+		)
+		config_stub = Config{
+			URL:            server.URL(),
+			RequestTimeout: 5,
+			CacheResponses: true,
+			CacheTTL:       60,
+		}
+		client, _ = NewClient(config_stub)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Serves reads without validators from the cache until the ttl expires", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			),
+		)
+		first, err := client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		second, err := client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Revalidates reads carrying an ETag", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job, http.Header{"ETag": []string{`"v1"`}}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+				ghttp.RespondWith(http.StatusNotModified, nil),
+			),
+		)
+		_, err := client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		cached, err := client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(cached.ID).To(Equal("foo.bar"))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Invalidates after the client updates a job", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			),
+		)
+		_, err := client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.UpdateJob("foo.bar", &job)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.QueryJob("foo.bar", definition)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(4))
+	})
+
+	It("Revalidates job reads embedding run state", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job, http.Header{"ETag": []string{`"v1"`}}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar"),
+				ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+				ghttp.RespondWith(http.StatusNotModified, nil),
+			),
+		)
+		first, err := client.GetJob("foo.bar")
+		Expect(err).ShouldNot(HaveOccurred())
+		second, err := client.GetJob("foo.bar")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(second).To(Equal(first))
+		// the reachability check and both reads
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Always asks metronome for runs and job reads embedding run state without validators", func() {
+		status := JobStatus{ID: "20161017", Status: "ACTIVE"}
+		server.AppendHandlers(
+			ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, job),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, status),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, status),
+		)
+		_, err := client.GetJob("foo.bar")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.GetJob("foo.bar")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.StatusJob("foo.bar", "20161017")
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = client.StatusJob("foo.bar", "20161017")
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(5))
+	})
+})
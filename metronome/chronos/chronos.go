@@ -0,0 +1,403 @@
+// Package chronos converts Chronos job definitions into Metronome jobs and schedules.
+//
+// Chronos and Metronome overlap but don't line up exactly: Chronos dependencies
+// (parents), executors, async jobs and several container options have no Metronome
+// equivalent.  Conversion never silently drops data - every field that couldn't be
+// mapped is listed in the Migration's Unmapped report.
+package chronos
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	duration "github.com/ChannelMeter/iso8601duration"
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Chronos defaults applied when a job leaves resources unset
+const (
+	DefaultCpus = 0.1
+	DefaultMem  = 128
+	DefaultDisk = 256
+	// DefaultMaxLaunchDelay - Chronos has no equivalent; Metronome's default
+	DefaultMaxLaunchDelay = 3600
+)
+
+// Job - a Chronos job as returned by GET /scheduler/jobs
+type Job struct {
+	Name                  string     `json:"name"`
+	Command               string     `json:"command"`
+	Shell                 *bool      `json:"shell,omitempty"`
+	Arguments             []string   `json:"arguments,omitempty"`
+	Description           string     `json:"description,omitempty"`
+	Owner                 string     `json:"owner,omitempty"`
+	OwnerName             string     `json:"ownerName,omitempty"`
+	Schedule              string     `json:"schedule,omitempty"`
+	ScheduleTimeZone      string     `json:"scheduleTimeZone,omitempty"`
+	Parents               []string   `json:"parents,omitempty"`
+	Epsilon               string     `json:"epsilon,omitempty"`
+	Retries               int        `json:"retries,omitempty"`
+	Disabled              bool       `json:"disabled,omitempty"`
+	Concurrent            bool       `json:"concurrent,omitempty"`
+	Async                 bool       `json:"async,omitempty"`
+	SoftError             bool       `json:"softError,omitempty"`
+	HighPriority          bool       `json:"highPriority,omitempty"`
+	DataProcessingJobType bool       `json:"dataProcessingJobType,omitempty"`
+	Executor              string     `json:"executor,omitempty"`
+	ExecutorFlags         string     `json:"executorFlags,omitempty"`
+	RunAsUser             string     `json:"runAsUser,omitempty"`
+	Cpus                  float64    `json:"cpus,omitempty"`
+	Mem                   float64    `json:"mem,omitempty"`
+	Disk                  float64    `json:"disk,omitempty"`
+	URIs                  []string   `json:"uris,omitempty"`
+	Fetch                 []Fetch    `json:"fetch,omitempty"`
+	EnvironmentVariables  []EnvVar   `json:"environmentVariables,omitempty"`
+	Constraints           [][]string `json:"constraints,omitempty"`
+	Container             *Container `json:"container,omitempty"`
+}
+
+// Fetch - a Chronos fetch uri
+type Fetch struct {
+	URI        string `json:"uri"`
+	Executable bool   `json:"executable,omitempty"`
+	Extract    bool   `json:"extract,omitempty"`
+	Cache      bool   `json:"cache,omitempty"`
+	OutputFile string `json:"output_file,omitempty"`
+}
+
+// EnvVar - a Chronos environment variable
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Container - a Chronos container block
+type Container struct {
+	Type           string      `json:"type,omitempty"`
+	Image          string      `json:"image,omitempty"`
+	Network        string      `json:"network,omitempty"`
+	ForcePullImage bool        `json:"forcePullImage,omitempty"`
+	Volumes        []Volume    `json:"volumes,omitempty"`
+	Parameters     []Parameter `json:"parameters,omitempty"`
+}
+
+// Volume - a Chronos container volume
+type Volume struct {
+	ContainerPath string `json:"containerPath"`
+	HostPath      string `json:"hostPath"`
+	Mode          string `json:"mode,omitempty"`
+}
+
+// Parameter - a docker command line parameter
+type Parameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Migration - the Metronome job and schedules for one Chronos job with what couldn't be carried over
+type Migration struct {
+	// Chronos - the Chronos job name
+	Chronos   string         `json:"chronos"`
+	Job       *met.Job       `json:"job"`
+	Schedules []met.Schedule `json:"schedules,omitempty"`
+	// Unmapped - Chronos settings with no Metronome equivalent.  The job is still usable
+	Unmapped []string `json:"unmapped,omitempty"`
+	// Errors - problems that make the converted job unusable as is.  Apply skips these
+	Errors []string `json:"errors,omitempty"`
+}
+
+// OK - true when the migration can be applied
+func (m *Migration) OK() bool {
+	return len(m.Errors) == 0
+}
+
+func (m *Migration) unmapped(format string, args ...interface{}) {
+	m.Unmapped = append(m.Unmapped, fmt.Sprintf(format, args...))
+}
+
+func (m *Migration) error(format string, args ...interface{}) {
+	m.Errors = append(m.Errors, fmt.Sprintf(format, args...))
+}
+
+// Parse - decodes either a single Chronos job or a list of them
+func Parse(data []byte) ([]Job, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, err
+		}
+		return []Job{job}, nil
+	}
+	var jobs []Job
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ConvertAll - convert every job.  now anchors Chronos schedules without a start time
+func ConvertAll(jobs []Job, now time.Time) []Migration {
+	migrations := make([]Migration, 0, len(jobs))
+	for i := range jobs {
+		migrations = append(migrations, *Convert(&jobs[i], now))
+	}
+	return migrations
+}
+
+var invalidIDChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+var repeatedDashes = regexp.MustCompile(`-{2,}`)
+
+// JobID - a Metronome job id derived from a Chronos name e.g. "Nightly ETL_v2" becomes "nightly-etl-v2"
+func JobID(name string) string {
+	id := invalidIDChars.ReplaceAllString(strings.ToLower(name), "-")
+	id = repeatedDashes.ReplaceAllString(id, "-")
+	segments := strings.Split(id, ".")
+	kept := segments[:0]
+	for _, segment := range segments {
+		if segment = strings.Trim(segment, "-"); segment != "" {
+			kept = append(kept, segment)
+		}
+	}
+	return strings.Join(kept, ".")
+}
+
+// Convert - map a Chronos job to a Metronome job and schedules
+func Convert(job *Job, now time.Time) *Migration {
+	m := &Migration{Chronos: job.Name}
+	id := JobID(job.Name)
+	if id != job.Name {
+		m.unmapped("name '%s' renamed to job id '%s'", job.Name, id)
+	}
+	run := &met.Run{
+		Cpus:           orDefault(job.Cpus, DefaultCpus),
+		Mem:            int(orDefault(job.Mem, DefaultMem)),
+		Disk:           int(orDefault(job.Disk, DefaultDisk)),
+		MaxLaunchDelay: DefaultMaxLaunchDelay,
+		User:           job.RunAsUser,
+		Volumes:        []met.Volume{},
+	}
+	m.Job = &met.Job{ID: id, Description: job.Description, Run: run}
+	if job.Owner != "" {
+		m.Job.Labels = &met.Labels{"owner": job.Owner}
+	}
+	if job.OwnerName != "" {
+		m.unmapped("ownerName '%s'", job.OwnerName)
+	}
+
+	m.convertCommand(job, run)
+	m.convertEnv(job, run)
+	m.convertFetch(job, run)
+	m.convertContainer(job, run)
+	m.convertConstraints(job, run)
+
+	restart := &met.Restart{Policy: "NEVER"}
+	if job.Retries > 0 {
+		restart.Policy = "ON_FAILURE"
+		m.unmapped("retries %d: Metronome retries ON_FAILURE until restart.activeDeadlineSeconds rather than a fixed count", job.Retries)
+	}
+	run.Restart = restart
+
+	m.convertSchedule(job, now)
+
+	for _, flag := range []struct {
+		set  bool
+		name string
+	}{
+		{job.Async, "async"},
+		{job.SoftError, "softError"},
+		{job.HighPriority, "highPriority"},
+		{job.DataProcessingJobType, "dataProcessingJobType"},
+		{job.Executor != "", "executor " + job.Executor},
+		{job.ExecutorFlags != "", "executorFlags " + job.ExecutorFlags},
+	} {
+		if flag.set {
+			m.unmapped("%s", flag.name)
+		}
+	}
+
+	m.invalid("", m.Job.Validate())
+	for i := range m.Schedules {
+		m.invalid(fmt.Sprintf("schedules[%d].", i), m.Schedules[i].Validate())
+	}
+	return m
+}
+
+// invalid - record each validation failure as an error
+func (m *Migration) invalid(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(met.ValidationErrors); ok {
+		for _, fe := range errs {
+			m.error("%s%s", prefix, fe.Error())
+		}
+		return
+	}
+	m.error("%s%s", prefix, err.Error())
+}
+
+func orDefault(val float64, def float64) float64 {
+	if val == 0 {
+		return def
+	}
+	return val
+}
+
+// convertCommand - shell jobs run command through sh; otherwise command is the executable and arguments follow it
+func (m *Migration) convertCommand(job *Job, run *met.Run) {
+	shell := job.Shell == nil || *job.Shell
+	if shell {
+		run.Cmd = job.Command
+		if len(job.Arguments) > 0 {
+			m.unmapped("arguments %v ignored because shell is true", job.Arguments)
+		}
+		return
+	}
+	if job.Command != "" {
+		run.Args = append(run.Args, job.Command)
+	}
+	run.Args = append(run.Args, job.Arguments...)
+}
+
+func (m *Migration) convertEnv(job *Job, run *met.Run) {
+	if len(job.EnvironmentVariables) == 0 {
+		return
+	}
+	run.Env = make(map[string]string, len(job.EnvironmentVariables))
+	for _, env := range job.EnvironmentVariables {
+		if _, dup := run.Env[env.Name]; dup {
+			m.unmapped("environment variable %s repeated; the last value is kept", env.Name)
+		}
+		run.Env[env.Name] = env.Value
+	}
+}
+
+func (m *Migration) convertFetch(job *Job, run *met.Run) {
+	for _, uri := range job.URIs {
+		run.Artifacts = append(run.Artifacts, met.Artifact{URI: uri, Extract: true})
+	}
+	for _, fetch := range job.Fetch {
+		run.Artifacts = append(run.Artifacts, met.Artifact{URI: fetch.URI, Executable: fetch.Executable, Extract: fetch.Extract, Cache: fetch.Cache})
+		if fetch.OutputFile != "" {
+			m.unmapped("fetch %s output_file %s", fetch.URI, fetch.OutputFile)
+		}
+	}
+}
+
+func (m *Migration) convertContainer(job *Job, run *met.Run) {
+	container := job.Container
+	if container == nil {
+		return
+	}
+	if container.Type != "" && !strings.EqualFold(container.Type, "DOCKER") {
+		m.unmapped("container type %s", container.Type)
+	} else if container.Image == "" {
+		m.error("container has no image")
+	} else {
+		run.Docker = &met.Docker{Image: container.Image, ForcePullImage: container.ForcePullImage}
+		for _, param := range container.Parameters {
+			run.Docker.AddParameter(param.Key, param.Value)
+		}
+	}
+	if container.Network != "" && !strings.EqualFold(container.Network, "HOST") {
+		m.unmapped("container network %s: Metronome runs containers with host networking", container.Network)
+	}
+	for _, vol := range container.Volumes {
+		mode := strings.ToUpper(vol.Mode)
+		if mode == "" {
+			mode = "RW"
+		}
+		volume, err := met.NewVolume(vol.ContainerPath, vol.HostPath, mode)
+		if err != nil {
+			m.unmapped("volume %s:%s: %s", vol.HostPath, vol.ContainerPath, err)
+			continue
+		}
+		run.Volumes = append(run.Volumes, *volume)
+	}
+}
+
+// convertConstraints - Chronos constraints are [attribute, operator, value] triples.  UNIQUE may leave out the value
+func (m *Migration) convertConstraints(job *Job, run *met.Run) {
+	for _, triple := range job.Constraints {
+		if len(triple) != 2 && len(triple) != 3 {
+			m.unmapped("constraint %v", triple)
+			continue
+		}
+		var op met.Operator
+		switch strings.ToUpper(triple[1]) {
+		case "EQUALS", "EQ":
+			op = met.EQ
+		case "LIKE":
+			op = met.LIKE
+		case "UNLIKE":
+			op = met.UNLIKE
+		case "GROUP_BY":
+			op = met.GROUPBY
+		case "UNIQUE":
+			op = met.UNIQUE
+		default:
+			m.unmapped("constraint %v: operator %s", triple, triple[1])
+			continue
+		}
+		constraint := met.Constraint{Attribute: triple[0], Operator: op}
+		if len(triple) == 3 {
+			constraint.Value = triple[2]
+		}
+		if err := constraint.Validate(); err != nil {
+			m.unmapped("constraint %v: %s", triple, err)
+			continue
+		}
+		if run.Placement == nil {
+			run.Placement = &met.Placement{}
+		}
+		run.Placement.Constraints = append(run.Placement.Constraints, constraint)
+	}
+}
+
+func (m *Migration) convertSchedule(job *Job, now time.Time) {
+	if len(job.Parents) > 0 {
+		m.unmapped("parents %s: Metronome has no job dependencies so no schedule was created", strings.Join(job.Parents, ","))
+		return
+	}
+	if job.Schedule == "" {
+		m.unmapped("no schedule: the job only runs when started")
+		return
+	}
+	scheds, err := met.ConvertIso8601ToSchedules(job.Schedule, now)
+	if err != nil {
+		m.error("schedule: %s", err)
+		return
+	}
+	if job.ScheduleTimeZone != "" {
+		m.unmapped("scheduleTimeZone %s: schedules are in UTC at the start time's offset", job.ScheduleTimeZone)
+	}
+	deadline := 0
+	if job.Epsilon != "" {
+		if eps, err := duration.FromString(job.Epsilon); err != nil {
+			m.unmapped("epsilon %s: %s", job.Epsilon, err)
+		} else {
+			deadline = int(eps.ToDuration() / time.Second)
+		}
+	}
+	for i := range scheds {
+		sched := &scheds[i]
+		sched.ID = "default"
+		if len(scheds) > 1 {
+			sched.ID = fmt.Sprintf("default-%d", i+1)
+		}
+		sched.Enabled = !job.Disabled
+		sched.ConcurrencyPolicy = "FORBID"
+		if job.Concurrent {
+			sched.ConcurrencyPolicy = "ALLOW"
+		}
+		if deadline > 0 {
+			sched.StartingDeadlineSeconds = deadline
+		}
+		m.Schedules = append(m.Schedules, *sched)
+	}
+}
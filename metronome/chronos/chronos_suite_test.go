@@ -0,0 +1,13 @@
+package chronos_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestChronos(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Chronos Suite")
+}
@@ -0,0 +1,118 @@
+package chronos_test
+
+import (
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/chronos"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const chronosJobs = `[
+  {
+    "name": "Nightly_ETL",
+    "command": "python etl.py --full",
+    "owner": "data@example.com",
+    "schedule": "R/2017-01-01T02:30:00Z/P1D",
+    "epsilon": "PT5M",
+    "retries": 2,
+    "cpus": 0.5,
+    "mem": 512,
+    "environmentVariables": [{"name": "STAGE", "value": "prod"}],
+    "uris": ["http://example.com/etl.tgz"],
+    "constraints": [["rack", "EQUALS", "rack-1"], ["hostname", "GROUP_BY", ""], ["hostname", "UNIQUE"], ["rack", "CLUSTER", "rack-2"]],
+    "container": {
+      "type": "DOCKER",
+      "image": "example/etl:1.2",
+      "network": "BRIDGE",
+      "volumes": [{"containerPath": "/data", "hostPath": "/mnt/data", "mode": "RO"}]
+    }
+  },
+  {
+    "name": "etl-report",
+    "command": "report",
+    "shell": false,
+    "arguments": ["--since", "1d"],
+    "parents": ["Nightly_ETL"]
+  },
+  {
+    "name": "too-often",
+    "command": "true",
+    "schedule": "R/2017-01-01T00:00:00Z/PT30S"
+  }
+]`
+
+var _ = Describe("Chronos", func() {
+	now, _ := time.Parse(time.RFC3339, "2017-06-01T12:00:00Z")
+	var migrations []Migration
+
+	BeforeEach(func() {
+		jobs, err := Parse([]byte(chronosJobs))
+		Expect(err).To(BeNil())
+		Expect(jobs).To(HaveLen(3))
+		migrations = ConvertAll(jobs, now)
+	})
+
+	It("Maps the job and its schedule", func() {
+		m := migrations[0]
+		Expect(m.OK()).To(BeTrue(), "%v", m.Errors)
+		job := m.Job
+		Expect(job.ID).To(Equal("nightly-etl"))
+		Expect((*job.Labels)["owner"]).To(Equal("data@example.com"))
+		Expect(job.Run.Cmd).To(Equal("python etl.py --full"))
+		Expect(job.Run.Cpus).To(Equal(0.5))
+		Expect(job.Run.Mem).To(Equal(512))
+		Expect(job.Run.Disk).To(Equal(DefaultDisk))
+		Expect(job.Run.Env).To(Equal(map[string]string{"STAGE": "prod"}))
+		Expect(job.Run.Docker.Image).To(Equal("example/etl:1.2"))
+		Expect(job.Run.Volumes).To(Equal([]met.Volume{{ContainerPath: "/data", HostPath: "/mnt/data", Mode: met.RO}}))
+		Expect(job.Run.Artifacts).To(Equal([]met.Artifact{{URI: "http://example.com/etl.tgz", Extract: true}}))
+		Expect(job.Run.Placement.Constraints).To(Equal([]met.Constraint{
+			{Attribute: "rack", Operator: met.EQ, Value: "rack-1"},
+			{Attribute: "hostname", Operator: met.GROUPBY},
+			{Attribute: "hostname", Operator: met.UNIQUE},
+		}))
+		Expect(job.Run.Restart.Policy).To(Equal("ON_FAILURE"))
+
+		Expect(m.Schedules).To(HaveLen(1))
+		sched := m.Schedules[0]
+		Expect(sched.ID).To(Equal("default"))
+		Expect(sched.Cron).To(Equal("30 2 * * *"))
+		Expect(sched.StartingDeadlineSeconds).To(Equal(300))
+		Expect(sched.ConcurrencyPolicy).To(Equal("FORBID"))
+		Expect(sched.Enabled).To(BeTrue())
+	})
+
+	It("Reports what doesn't map", func() {
+		Expect(migrations[0].Unmapped).To(ConsistOf(
+			ContainSubstring("renamed to job id 'nightly-etl'"),
+			ContainSubstring("retries 2"),
+			ContainSubstring("operator CLUSTER"),
+			ContainSubstring("network BRIDGE"),
+		))
+	})
+
+	It("Keeps dependent jobs without a schedule", func() {
+		m := migrations[1]
+		Expect(m.OK()).To(BeTrue(), "%v", m.Errors)
+		Expect(m.Job.Run.Cmd).To(BeEmpty())
+		Expect(m.Job.Run.Args).To(Equal([]string{"report", "--since", "1d"}))
+		Expect(m.Schedules).To(BeEmpty())
+		Expect(m.Unmapped).To(ConsistOf(ContainSubstring("parents Nightly_ETL")))
+	})
+
+	It("Flags schedules Metronome can't run", func() {
+		m := migrations[2]
+		Expect(m.OK()).To(BeFalse())
+		Expect(m.Errors).To(ConsistOf(ContainSubstring("one minute granularity")))
+	})
+
+	It("Accepts a single job", func() {
+		jobs, err := Parse([]byte(`{"name": "one", "command": "true"}`))
+		Expect(err).To(BeNil())
+		Expect(jobs).To(HaveLen(1))
+		Expect(JobID("A..b--c_")).To(Equal("a.b-c"))
+	})
+})
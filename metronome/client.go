@@ -32,17 +32,25 @@ type Metronome interface {
 	DeleteJob(jobID string) (interface{}, error)
 	// GET /v1/jobs/$jobId
 	GetJob(jobID string) (*Job, error)
+	// GET /v1/jobs/$jobId?embed=...
+	QueryJob(jobID string, query *JobQuery) (*Job, error)
 	// GET /v1/jobs
 	Jobs() (*[]Job, error)
+	// GET /v1/jobs?embed=...
+	QueryJobs(query *JobQuery) (*[]Job, error)
 	// PUT /v1/jobs/$jobId
 	UpdateJob(jobID string, job *Job) (interface{}, error)
 	//
-	// schedules
+	// runs
 	// GET /v1/jobs/$jobId/runs
 	// Technically, this rev of Runs() is a hack to get functionality from the undocumented api
 	//   - since is milliseconds from epoch
-
+	// Deprecated: use ActiveRuns and RunHistory
 	Runs(jobID string, statusSince int64) (*Job, error)
+	// GET /v1/jobs/$jobId/runs
+	ActiveRuns(jobID string, filter *RunFilter) (*[]JobStatus, error)
+	// GET /v1/jobs/$jobId?embed=history
+	RunHistory(jobID string, filter *RunFilter) (*[]HistoryStatus, error)
 	// POST /v1/jobs/$jobId/runs
 	StartJob(jobID string) (interface{}, error)
 	// GET /v1/jobs/$jobId/runs/$runId
@@ -80,6 +88,7 @@ type Client struct {
 	url    *url.URL
 	config Config
 	http   *http.Client
+	cache  *responseCache
 }
 
 // NewClient returns a new  client, initialzed with the provided config
@@ -108,6 +117,10 @@ func NewClient(config Config) (Metronome, error) {
 	if err != nil {
 		return nil, errors.New("Could not reach metronome cluster: " + err.Error())
 	}
+	// enable after the reachability check so the probe doesn't seed the cache
+	if config.CacheResponses {
+		client.cache = newResponseCache(time.Duration(config.CacheTTL) * time.Second)
+	}
 
 	return client, nil
 }
@@ -150,20 +163,21 @@ func (client *Client) apiCall(method string, uri string, queryParams map[string]
 	log.Debugf("apiCall ... method: %v url: %v queryParams: %+v", method, uri, queryParams)
 
 	url, _ := client.buildURL(uri, queryParams)
-	status, response, err := client.httpCall(method, url, body)
+	response, err := client.fetch(method, uri, url, body)
 
 	if err != nil {
 		return 0, err
 	}
-	log.Debugf("%s result status: %+v", uri, response.Status)
-	log.Debugf("Headers: %+v", response.Header)
-	if response.ContentLength > 0 {
-		ct := response.Header["Content-Type"]
+	status := response.status
+	log.Debugf("%s result status: %+v", uri, response.statusText)
+	log.Debugf("Headers: %+v", response.header)
+	if response.contentLength > 0 {
+		ct := response.header["Content-Type"]
 		log.Debugf("content-type: %s", ct)
 		switch ct[0] {
 		case "application/json":
 			var msg json.RawMessage
-			err = json.NewDecoder(response.Body).Decode(&msg)
+			err = json.Unmarshal(response.body, &msg)
 			// decode as a raw json message which will fail if the message isn't good json
 			if err == nil {
 				switch result.(type) {
@@ -187,12 +201,8 @@ func (client *Client) apiCall(method string, uri string, queryParams map[string]
 			}
 
 		case "text/plain; charset=utf-8":
-			htmlData, err := ioutil.ReadAll(response.Body)
-			if err != nil {
-				return status, err
-			}
 			v := result.(*string)
-			*v = string(htmlData)
+			*v = string(response.body)
 
 		default:
 			return status, fmt.Errorf("Unknown content-type %s", ct[0])
@@ -201,10 +211,43 @@ func (client *Client) apiCall(method string, uri string, queryParams map[string]
 
 	// TODO: Handle error status codes
 	if status < 200 || status > 299 {
-		return status, errors.New(response.Status)
+		return status, errors.New(response.statusText)
 	}
 	return status, nil
 }
+
+// fetch - make the http call, answering reads from the response cache when it is enabled
+func (client *Client) fetch(method string, uri string, url *url.URL, body string) (*apiResponse, error) {
+	if client.cache == nil {
+		return client.httpCall(method, url, body, nil)
+	}
+	if method != HTTPGet {
+		// the call may have changed metronome's state even if it failed
+		defer client.cache.invalidate()
+		return client.httpCall(method, url, body, nil)
+	}
+	key := url.String()
+	definition := definitionRead(uri, url.Query())
+	cached, fresh := client.cache.lookup(key, time.Now(), definition)
+	if fresh {
+		log.Debugf("cache hit %s", key)
+		return cached, nil
+	}
+	response, err := client.httpCall(method, url, body, cached)
+	if err != nil {
+		return nil, err
+	}
+	if response.status == http.StatusNotModified && cached != nil {
+		log.Debugf("cache revalidated %s", key)
+		client.cache.put(key, cached)
+		return cached, nil
+	}
+	if response.status == http.StatusOK && (definition || response.hasValidators()) {
+		client.cache.put(key, response)
+	}
+	return response, nil
+}
+
 func (client *Client) buildURL(reqPath string, queryParams map[string][]string) (*url.URL, error) {
 	// make copy of client url
 	base := *client.url
@@ -251,20 +294,41 @@ func (client *Client) newRequest(method string, url *url.URL, body string) (*htt
 	return request, nil
 }
 
-func (client *Client) httpCall(method string, url *url.URL, body string) (int, *http.Response, error) {
+// httpCall - send the request and read the response.  When a cached response is supplied, its validators
+// are sent so metronome can answer 304 Not Modified
+func (client *Client) httpCall(method string, url *url.URL, body string, cached *apiResponse) (*apiResponse, error) {
 	request, err := client.newRequest(method, url, body)
 
 	if err != nil {
-		return 0, nil, err
+		return nil, err
+	}
+	if cached != nil {
+		if etag := cached.etag(); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		if modified := cached.lastModified(); modified != "" {
+			request.Header.Set("If-Modified-Since", modified)
+		}
 	}
 
 	response, err := client.http.Do(request)
 
 	if err != nil {
-		return 0, nil, err
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	return response.StatusCode, response, nil
+	return &apiResponse{
+		status:        response.StatusCode,
+		statusText:    response.Status,
+		header:        response.Header,
+		contentLength: response.ContentLength,
+		body:          data,
+	}, nil
 }
 
 // TODO: this better
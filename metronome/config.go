@@ -10,6 +10,10 @@ type Config struct {
 	RequestTimeout int
 	/* allow unverified tls (self-signed certs) defaults to false */
 	AllowUnverifiedTLS bool
+	/* cache reads: those with an ETag or Last-Modified are revalidated, job and schedule definitions without are kept for CacheTTL.  defaults to false */
+	CacheResponses bool
+	/* seconds a cached read stays fresh when metronome sends no ETag or Last-Modified */
+	CacheTTL int
 
 	AuthToken string
 	User      string
@@ -1,9 +1,57 @@
 package metronome
 
-// A Container defines a metronome container
-type Container struct {
-	Type    string              `json:"type,omitempty"`
-	Image   string              `json:"image,omitempty"`
-	Network string              `json:"network,omitempty"`
-	Volumes []map[string]string `json:"volumes,omitempty"`
+// DockerParameter - a docker run command line option.  Key is the option name without leading dashes
+type DockerParameter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra Extra `json:"-"`
+}
+
+// Image kinds supported by the universal container runtime
+const (
+	ImageKindDocker = "docker"
+	ImageKindAppc   = "appc"
+)
+
+// UCR - run the job in Mesos' universal container runtime.  Mutually exclusive with Run.Docker
+type UCR struct {
+	Image      UCRImage `json:"image"`
+	Privileged bool     `json:"privileged,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra Extra `json:"-"`
+}
+
+// UCRImage - the image UCR runs.  Kind defaults to docker
+type UCRImage struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind,omitempty"`
+	ForcePull bool   `json:"forcePull,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra Extra `json:"-"`
+}
+
+// NewUCR - create UCR settings for a docker image
+func NewUCR(image string) (*UCR, error) {
+	if len(image) == 0 {
+		return nil, required("UCR.Image.ID requires a value")
+	}
+	return &UCR{Image: UCRImage{ID: image, Kind: ImageKindDocker}}, nil
+}
+
+// GetImage - the image id
+func (ucr *UCR) GetImage() string {
+	return ucr.Image.ID
+}
+
+// SetForcePull - pull the image even when the agent has it cached
+func (ucr *UCR) SetForcePull(force bool) *UCR {
+	ucr.Image.ForcePull = force
+	return ucr
+}
+
+// SetPrivileged - run the container in privileged mode
+func (ucr *UCR) SetPrivileged(privileged bool) *UCR {
+	ucr.Privileged = privileged
+	return ucr
 }
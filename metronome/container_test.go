@@ -0,0 +1,55 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const dockerOptionsRun = `{"cpus":1,"mem":128,"disk":0,"maxLaunchDelay":3600,"volumes":[],"docker":{"image":"foo/bla:test","forcePullImage":true,"parameters":[{"key":"memory-swap","value":"-1"}],"privileged":true}}`
+const ucrRun = `{"cpus":1,"mem":128,"disk":0,"maxLaunchDelay":3600,"volumes":[],"ucr":{"image":{"id":"foo/bla:test","kind":"docker","forcePull":true},"privileged":true}}`
+
+var _ = Describe("Containers", func() {
+	It("Round trips docker options", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(dockerOptionsRun), &run)).To(BeNil())
+		Expect(*run.Docker).To(Equal(*(&Docker{Image: "foo/bla:test"}).SetForcePullImage(true).AddParameter("memory-swap", "-1").SetPrivileged(true)))
+		Expect(run.Validate()).To(BeNil())
+		b, err := json.Marshal(&run)
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(dockerOptionsRun))
+	})
+
+	It("Round trips ucr", func() {
+		ucr, err := NewUCR("foo/bla:test")
+		Expect(err).To(BeNil())
+		run := Run{Cpus: 1, Mem: 128, MaxLaunchDelay: 3600, Volumes: []Volume{}}
+		run.SetUCR(ucr.SetForcePull(true).SetPrivileged(true))
+		Expect(run.Validate()).To(BeNil())
+		b, err := json.Marshal(&run)
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(ucrRun))
+		var back Run
+		Expect(json.Unmarshal(b, &back)).To(BeNil())
+		Expect(back.GetUCR().GetImage()).To(Equal("foo/bla:test"))
+	})
+
+	It("Omits unset docker options", func() {
+		b, err := json.Marshal(&Docker{Image: "alpine"})
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(`{"image":"alpine"}`))
+	})
+
+	It("Validates containers", func() {
+		run := Run{Cpus: 1, Mem: 128, MaxLaunchDelay: 3600,
+			Docker: &Docker{Image: "alpine", Parameters: []DockerParameter{{Value: "x"}}},
+			UCR:    &UCR{Image: UCRImage{Kind: "oci"}},
+		}
+		Expect(fields(run.Validate())).To(ConsistOf("docker.parameters[0].key", "ucr", "ucr.image.id", "ucr.image.kind"))
+		_, err := NewUCR("")
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,143 @@
+package metronome
+
+import "encoding/json"
+
+// DeepCopy - a copy of the job sharing no pointers, slices or maps with the original
+func (theJob *Job) DeepCopy() *Job {
+	if theJob == nil {
+		return nil
+	}
+	out := *theJob
+	if theJob.Labels != nil {
+		labels := Labels(copyStrings(*theJob.Labels))
+		out.Labels = &labels
+	}
+	out.Run = theJob.Run.DeepCopy()
+	if theJob.Schedules != nil {
+		out.Schedules = make([]*Schedule, len(theJob.Schedules))
+		for i, sched := range theJob.Schedules {
+			out.Schedules[i] = sched.DeepCopy()
+		}
+	}
+	if theJob.ActiveRuns != nil {
+		out.ActiveRuns = make([]*ActiveRun, len(theJob.ActiveRuns))
+		for i, active := range theJob.ActiveRuns {
+			if active != nil {
+				run := *active
+				run.Tasks = append([]TaskStatus(nil), active.Tasks...)
+				out.ActiveRuns[i] = &run
+			}
+		}
+	}
+	if theJob.History != nil {
+		history := *theJob.History
+		history.SuccessfulFinishedRuns = append([]HistoryStatus(nil), theJob.History.SuccessfulFinishedRuns...)
+		history.FailedFinishedRuns = append([]HistoryStatus(nil), theJob.History.FailedFinishedRuns...)
+		out.History = &history
+	}
+	if theJob.HistorySummary != nil {
+		summary := *theJob.HistorySummary
+		out.HistorySummary = &summary
+	}
+	out.Extra = theJob.Extra.copy()
+	return &out
+}
+
+// DeepCopy - a copy of the run sharing no pointers, slices or maps with the original
+func (runner *Run) DeepCopy() *Run {
+	if runner == nil {
+		return nil
+	}
+	out := *runner
+	if runner.Artifacts != nil {
+		out.Artifacts = append([]Artifact{}, runner.Artifacts...)
+		for i := range out.Artifacts {
+			out.Artifacts[i].Extra = runner.Artifacts[i].Extra.copy()
+		}
+	}
+	if runner.Args != nil {
+		out.Args = append([]string{}, runner.Args...)
+	}
+	if runner.Docker != nil {
+		docker := *runner.Docker
+		if runner.Docker.Parameters != nil {
+			docker.Parameters = append([]DockerParameter{}, runner.Docker.Parameters...)
+			for i := range docker.Parameters {
+				docker.Parameters[i].Extra = runner.Docker.Parameters[i].Extra.copy()
+			}
+		}
+		docker.Extra = runner.Docker.Extra.copy()
+		out.Docker = &docker
+	}
+	if runner.UCR != nil {
+		ucr := *runner.UCR
+		ucr.Image.Extra = runner.UCR.Image.Extra.copy()
+		ucr.Extra = runner.UCR.Extra.copy()
+		out.UCR = &ucr
+	}
+	out.Env = copyStrings(runner.Env)
+	out.EnvSecrets = copyStrings(runner.EnvSecrets)
+	if runner.Secrets != nil {
+		out.Secrets = make(map[string]SecretSource, len(runner.Secrets))
+		for name, secret := range runner.Secrets {
+			secret.Extra = secret.Extra.copy()
+			out.Secrets[name] = secret
+		}
+	}
+	if runner.Placement != nil {
+		placement := *runner.Placement
+		if runner.Placement.Constraints != nil {
+			placement.Constraints = append([]Constraint{}, runner.Placement.Constraints...)
+			for i := range placement.Constraints {
+				placement.Constraints[i].Extra = runner.Placement.Constraints[i].Extra.copy()
+			}
+		}
+		placement.Extra = runner.Placement.Extra.copy()
+		out.Placement = &placement
+	}
+	if runner.Restart != nil {
+		restart := *runner.Restart
+		restart.Extra = runner.Restart.Extra.copy()
+		out.Restart = &restart
+	}
+	if runner.Volumes != nil {
+		out.Volumes = append([]Volume{}, runner.Volumes...)
+		for i := range out.Volumes {
+			out.Volumes[i].Extra = runner.Volumes[i].Extra.copy()
+		}
+	}
+	out.Extra = runner.Extra.copy()
+	return &out
+}
+
+// DeepCopy - a copy of the schedule sharing nothing with the original
+func (sched *Schedule) DeepCopy() *Schedule {
+	if sched == nil {
+		return nil
+	}
+	out := *sched
+	out.Extra = sched.Extra.copy()
+	return &out
+}
+
+func (extra Extra) copy() Extra {
+	if extra == nil {
+		return nil
+	}
+	out := make(Extra, len(extra))
+	for name, value := range extra {
+		out[name] = append(json.RawMessage(nil), value...)
+	}
+	return out
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
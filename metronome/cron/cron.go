@@ -0,0 +1,347 @@
+// Package cron parses the cron expressions accepted by Metronome schedules and
+// computes the times at which they fire.
+//
+// An expression has five fields - minute hour day-of-month month day-of-week -
+// optionally followed by a year, which is what metronome.ImmediateCrontab emits.
+// Each field accepts *, ?, single values, ranges (a-b), lists (a,b,c) and steps
+// (*/n, a/n, a-b/n).  Months and days of the week may be given by their three
+// letter english names and Sunday is both 0 and 7.  The macros @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly are also accepted.
+package cron
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind - identifies a field of an expression
+type Kind int
+
+const (
+	// Minute - 0-59
+	Minute Kind = iota
+	// Hour - 0-23
+	Hour
+	// DayOfMonth - 1-31
+	DayOfMonth
+	// Month - 1-12 or JAN-DEC
+	Month
+	// DayOfWeek - 0-7 or SUN-SAT, both 0 and 7 are Sunday
+	DayOfWeek
+	// Year - 1970-2099
+	Year
+)
+
+// MinYear, MaxYear - the range of the year field and of the search for fire times
+const (
+	MinYear = 1970
+	MaxYear = 2099
+)
+
+var kindNames = [...]string{"minute", "hour", "day-of-month", "month", "day-of-week", "year"}
+
+func (kind Kind) String() string {
+	return kindNames[kind]
+}
+
+type bounds struct {
+	min, max int
+	names    []string
+}
+
+var fieldBounds = [...]bounds{
+	Minute:     {0, 59, nil},
+	Hour:       {0, 23, nil},
+	DayOfMonth: {1, 31, nil},
+	Month:      {1, 12, []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	DayOfWeek:  {0, 7, []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+	Year:       {MinYear, MaxYear, nil},
+}
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var whitespaceRe = regexp.MustCompile(`\s+`)
+
+// Field - one parsed field of an expression
+type Field struct {
+	Kind Kind
+	// Text - the field as written
+	Text string
+	// Any - true for * and ?.  Values still lists every value in range
+	Any bool
+	// Values - the sorted values the field matches.  Day of week 7 is folded into 0
+	Values  []int
+	allowed []bool
+}
+
+// Contains - does the field match the value
+func (field *Field) Contains(val int) bool {
+	idx := val - fieldBounds[field.Kind].min
+	return idx >= 0 && idx < len(field.allowed) && field.allowed[idx]
+}
+
+// Expression - a parsed cron expression
+type Expression struct {
+	Minute     Field
+	Hour       Field
+	DayOfMonth Field
+	Month      Field
+	DayOfWeek  Field
+	Year       Field
+	// Macro - the @macro the expression was expanded from, if any
+	Macro  string
+	source string
+}
+
+// Error - describes why an expression could not be parsed
+type Error struct {
+	Expr    string
+	Field   string
+	Message string
+}
+
+func (err *Error) Error() string {
+	if err.Field == "" {
+		return fmt.Sprintf("cron '%s': %s", err.Expr, err.Message)
+	}
+	return fmt.Sprintf("cron '%s': %s field: %s", err.Expr, err.Field, err.Message)
+}
+
+// Parse - parse a cron expression
+func Parse(expr string) (*Expression, error) {
+	source := strings.TrimSpace(expr)
+	if source == "" {
+		return nil, &Error{Expr: expr, Message: "empty expression"}
+	}
+	spec := source
+	macro := ""
+	if strings.HasPrefix(source, "@") {
+		expanded, ok := macros[strings.ToLower(source)]
+		if !ok {
+			return nil, &Error{Expr: expr, Message: "unknown macro " + source}
+		}
+		macro = strings.ToLower(source)
+		spec = expanded
+	}
+	texts := whitespaceRe.Split(spec, -1)
+	if len(texts) < 5 || len(texts) > 6 {
+		return nil, &Error{Expr: expr, Message: fmt.Sprintf("expected 5 or 6 fields, found %d", len(texts))}
+	}
+	if len(texts) == 5 {
+		texts = append(texts, "*")
+	}
+	var fields [6]Field
+	for i, text := range texts {
+		field, err := parseField(Kind(i), text)
+		if err != nil {
+			return nil, &Error{Expr: expr, Field: Kind(i).String(), Message: err.Error()}
+		}
+		fields[i] = field
+	}
+	return &Expression{
+		Minute:     fields[Minute],
+		Hour:       fields[Hour],
+		DayOfMonth: fields[DayOfMonth],
+		Month:      fields[Month],
+		DayOfWeek:  fields[DayOfWeek],
+		Year:       fields[Year],
+		Macro:      macro,
+		source:     source,
+	}, nil
+}
+
+// MustParse - Parse that panics on error.  For expressions known at compile time
+func MustParse(expr string) *Expression {
+	e, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String - the expression as written
+func (e *Expression) String() string {
+	return e.source
+}
+
+func parseField(kind Kind, text string) (Field, error) {
+	b := fieldBounds[kind]
+	field := Field{Kind: kind, Text: text, allowed: make([]bool, b.max-b.min+1)}
+	if text == "*" || text == "?" {
+		if text == "?" && kind != DayOfMonth && kind != DayOfWeek {
+			return field, fmt.Errorf("? is only allowed for day-of-month and day-of-week")
+		}
+		field.Any = true
+		for i := range field.allowed {
+			field.allowed[i] = true
+		}
+	} else {
+		for _, part := range strings.Split(text, ",") {
+			if err := field.addPart(part, b); err != nil {
+				return field, err
+			}
+		}
+	}
+	if kind == DayOfWeek && field.allowed[7] {
+		field.allowed[0] = true
+		field.allowed[7] = false
+	}
+	for i, ok := range field.allowed {
+		if ok {
+			field.Values = append(field.Values, i+b.min)
+		}
+	}
+	return field, nil
+}
+
+func (field *Field) addPart(part string, b bounds) error {
+	if part == "" {
+		return fmt.Errorf("empty list element")
+	}
+	rng, step := part, 1
+	if idx := strings.Index(part, "/"); idx >= 0 {
+		rng = part[:idx]
+		n, err := strconv.Atoi(part[idx+1:])
+		if err != nil || n < 1 {
+			return fmt.Errorf("bad step in '%s'", part)
+		}
+		step = n
+	}
+	var lo, hi int
+	var err error
+	switch {
+	case rng == "*" || rng == "?":
+		lo, hi = b.min, b.max
+		if field.Kind == DayOfWeek {
+			hi = 6
+		}
+	case strings.Contains(rng, "-"):
+		ends := strings.SplitN(rng, "-", 2)
+		if lo, err = value(ends[0], b); err != nil {
+			return err
+		}
+		if hi, err = value(ends[1], b); err != nil {
+			return err
+		}
+		if hi < lo {
+			return fmt.Errorf("range '%s' is backwards", rng)
+		}
+	default:
+		if lo, err = value(rng, b); err != nil {
+			return err
+		}
+		hi = lo
+		if step > 1 {
+			hi = b.max
+			if field.Kind == DayOfWeek {
+				hi = 6
+			}
+		}
+	}
+	for v := lo; v <= hi; v += step {
+		field.allowed[v-b.min] = true
+	}
+	return nil
+}
+
+func value(text string, b bounds) (int, error) {
+	for i, name := range b.names {
+		if strings.EqualFold(text, name) {
+			return i + b.min, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", text)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("%d is outside %d-%d", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// matchesDay - vixie cron semantics: when both day fields are restricted either may match
+func (e *Expression) matchesDay(t time.Time) bool {
+	dom := e.DayOfMonth.Contains(t.Day())
+	dow := e.DayOfWeek.Contains(int(t.Weekday()))
+	switch {
+	case e.DayOfMonth.Any && e.DayOfWeek.Any:
+		return true
+	case e.DayOfMonth.Any:
+		return dow
+	case e.DayOfWeek.Any:
+		return dom
+	}
+	return dom || dow
+}
+
+// Next - the first fire time strictly after from, evaluated in from's location.
+// Wall clock times skipped by a daylight saving change do not fire; times repeated
+// by one fire once, at their first occurrence.  Returns the zero time if the
+// expression never fires again before the end of MaxYear
+func (e *Expression) Next(from time.Time) time.Time {
+	loc := from.Location()
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for day.Year() <= MaxYear {
+		if !e.Year.Contains(day.Year()) {
+			day = time.Date(day.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !e.Month.Contains(int(day.Month())) {
+			day = time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if e.matchesDay(day) {
+			for _, h := range e.Hour.Values {
+				for _, m := range e.Minute.Values {
+					t, ok := wallTime(day.Year(), day.Month(), day.Day(), h, m, loc)
+					if ok && t.After(from) {
+						return t
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// NextN - up to n fire times after from.  Fewer are returned if the expression stops firing
+func (e *Expression) NextN(from time.Time, n int) []time.Time {
+	var times []time.Time
+	for len(times) < n {
+		from = e.Next(from)
+		if from.IsZero() {
+			break
+		}
+		times = append(times, from)
+	}
+	return times
+}
+
+// wallTime - the earliest instant showing the given wall clock in loc.  ok is false when
+// the wall clock never occurs, i.e. it falls in a daylight saving gap
+func wallTime(year int, month time.Month, day int, hour int, min int, loc *time.Location) (time.Time, bool) {
+	t := time.Date(year, month, day, hour, min, 0, 0, loc)
+	if t.Hour() != hour || t.Minute() != min || t.Day() != day {
+		return t, false
+	}
+	for _, back := range []time.Duration{time.Hour, 30 * time.Minute} {
+		earlier := t.Add(-back)
+		if earlier.Hour() == hour && earlier.Minute() == min && earlier.Day() == day {
+			return earlier, true
+		}
+	}
+	return t, true
+}
@@ -0,0 +1,109 @@
+package cron_test
+
+import (
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome/cron"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron", func() {
+	at := func(s string, tz string) time.Time {
+		loc, err := time.LoadLocation(tz)
+		Expect(err).To(BeNil())
+		t, err := time.Parse(time.RFC3339, s)
+		Expect(err).To(BeNil())
+		return t.In(loc)
+	}
+	next := func(expr string, tz string, from string, n int) []string {
+		e, err := Parse(expr)
+		Expect(err).To(BeNil())
+		var out []string
+		for _, t := range e.NextN(at(from, tz), n) {
+			out = append(out, t.Format(time.RFC3339))
+		}
+		return out
+	}
+
+	It("Handles steps, ranges and names", func() {
+		Expect(next("0 */4 * * MON-FRI", "UTC", "2017-03-03T21:00:00Z", 3)).To(Equal([]string{
+			"2017-03-06T00:00:00Z",
+			"2017-03-06T04:00:00Z",
+			"2017-03-06T08:00:00Z",
+		}))
+		Expect(next("30 9 1,15 jan,jul *", "UTC", "2017-01-10T00:00:00Z", 3)).To(Equal([]string{
+			"2017-01-15T09:30:00Z",
+			"2017-07-01T09:30:00Z",
+			"2017-07-15T09:30:00Z",
+		}))
+	})
+
+	It("Parses each field", func() {
+		e, err := Parse("*/20 9-17/4 1,15 * ?")
+		Expect(err).To(BeNil())
+		Expect(e.Minute.Values).To(Equal([]int{0, 20, 40}))
+		Expect(e.Hour.Values).To(Equal([]int{9, 13, 17}))
+		Expect(e.DayOfMonth.Contains(15)).To(BeTrue())
+		Expect(e.DayOfMonth.Contains(2)).To(BeFalse())
+		Expect(e.Month.Contains(12)).To(BeTrue())
+		Expect(e.Year.Contains(MaxYear)).To(BeTrue())
+		Expect(e.String()).To(Equal("*/20 9-17/4 1,15 * ?"))
+	})
+
+	It("Matches either day field when both are restricted", func() {
+		Expect(next("0 0 13 * 5", "UTC", "2017-10-01T00:00:00Z", 3)).To(Equal([]string{
+			"2017-10-06T00:00:00Z",
+			"2017-10-13T00:00:00Z",
+			"2017-10-20T00:00:00Z",
+		}))
+	})
+
+	It("Expands macros and treats 7 as Sunday", func() {
+		Expect(MustParse("@WEEKLY").Macro).To(Equal("@weekly"))
+		Expect(next("@weekly", "UTC", "2017-10-04T12:00:00Z", 1)).To(Equal([]string{"2017-10-08T00:00:00Z"}))
+		Expect(next("0 0 * * 7", "UTC", "2017-10-04T12:00:00Z", 1)).To(Equal([]string{"2017-10-08T00:00:00Z"}))
+	})
+
+	It("Stops at the year field", func() {
+		Expect(next("0 12 1 1 * 2018", "UTC", "2017-06-01T00:00:00Z", 5)).To(Equal([]string{"2018-01-01T12:00:00Z"}))
+		Expect(next("0 0 30 2 *", "UTC", "2017-06-01T00:00:00Z", 1)).To(BeEmpty())
+		Expect(MustParse("0 0 30 2 *").Next(at("2017-06-01T00:00:00Z", "UTC")).IsZero()).To(BeTrue())
+	})
+
+	It("Skips wall times removed by daylight saving and fires once when they repeat", func() {
+		Expect(next("30 2 * * *", "America/New_York", "2017-03-11T00:00:00Z", 2)).To(Equal([]string{
+			"2017-03-11T02:30:00-05:00",
+			"2017-03-13T02:30:00-04:00",
+		}))
+		Expect(next("30 1 * * *", "America/New_York", "2017-11-04T12:00:00Z", 2)).To(Equal([]string{
+			"2017-11-05T01:30:00-04:00",
+			"2017-11-06T01:30:00-05:00",
+		}))
+	})
+
+	It("Rejects bad expressions", func() {
+		for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * FOO *", "5-1 * * * *", "*/0 * * * *", "@reboot", "? * * * *"} {
+			_, err := Parse(expr)
+			Expect(err).ToNot(BeNil(), expr)
+		}
+		_, err := Parse("60 * * * *")
+		Expect(err.Error()).To(Equal("cron '60 * * * *': minute field: " + err.(*Error).Message))
+		Expect(func() { MustParse("bogus") }).To(Panic())
+	})
+
+	It("Describes expressions in english", func() {
+		describe := func(expr string) string {
+			return MustParse(expr).Describe()
+		}
+		Expect(describe("0 */4 * * 1-5")).To(Equal("every 4 hours on weekdays, at minute 0"))
+		Expect(describe("* * * * *")).To(Equal("every minute"))
+		Expect(describe("*/15 9-17 * * *")).To(Equal("every 15 minutes, during hours 9 through 17"))
+		Expect(describe("30 9,17 * * sat,sun")).To(Equal("on weekends, at 09:30 and 17:30"))
+		Expect(describe("@daily")).To(Equal("every day, at 00:00"))
+		Expect(describe("0 12 1,15 jan,jul *")).To(Equal("on days 1 and 15 of the month in January and July, at 12:00"))
+		Expect(describe("0 0 13 * 5")).To(Equal("on day 13 of the month or on Friday, at 00:00"))
+		Expect(describe("5 4 * * * 2018")).To(Equal("every day in 2018, at 04:05"))
+	})
+})
@@ -0,0 +1,150 @@
+package cron
+
+import (
+	"fmt"
+	"strings"
+)
+
+var monthNames = [...]string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+var dayNames = [...]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// Describe - render the expression as english e.g. `0 */4 * * 1-5` is
+// "every 4 hours on weekdays, at minute 0"
+func (e *Expression) Describe() string {
+	lead, at := e.describeTime()
+	days := e.describeDays()
+	var parts []string
+	if lead != "" {
+		parts = append(parts, lead)
+	} else if days == "" {
+		parts = append(parts, "every day")
+	}
+	if days != "" {
+		parts = append(parts, days)
+	}
+	if !e.Month.Any {
+		parts = append(parts, "in "+listValues(e.Month.Values, func(v int) string { return monthNames[v] }))
+	}
+	if !e.Year.Any {
+		parts = append(parts, "in "+listValues(e.Year.Values, nil))
+	}
+	text := strings.Join(parts, " ")
+	if at != "" {
+		text += ", " + at
+	}
+	return text
+}
+
+// describeTime - the frequency within a day and the minutes/hours it is pinned to
+func (e *Expression) describeTime() (lead string, at string) {
+	minutes, hours := &e.Minute, &e.Hour
+	minStep, minStepped := step(minutes)
+	hourStep, hourStepped := step(hours)
+	switch {
+	case minutes.Any && hours.Any:
+		return "every minute", ""
+	case minutes.Any:
+		return "every minute", "during " + plural("hour", hours.Values) + " " + listValues(hours.Values, nil)
+	case minStepped && hours.Any:
+		return fmt.Sprintf("every %d minutes", minStep), ""
+	case minStepped:
+		return fmt.Sprintf("every %d minutes", minStep), "during " + plural("hour", hours.Values) + " " + listValues(hours.Values, nil)
+	case hours.Any:
+		return "every hour", "at " + plural("minute", minutes.Values) + " " + listValues(minutes.Values, nil)
+	case hourStepped:
+		return fmt.Sprintf("every %d hours", hourStep), "at " + plural("minute", minutes.Values) + " " + listValues(minutes.Values, nil)
+	case len(minutes.Values)*len(hours.Values) <= 4:
+		var clock []string
+		for _, h := range hours.Values {
+			for _, m := range minutes.Values {
+				clock = append(clock, fmt.Sprintf("%02d:%02d", h, m))
+			}
+		}
+		return "", "at " + joinList(clock)
+	}
+	return "", "at " + plural("minute", minutes.Values) + " " + listValues(minutes.Values, nil) +
+		" past " + plural("hour", hours.Values) + " " + listValues(hours.Values, nil)
+}
+
+func (e *Expression) describeDays() string {
+	dom := "on " + plural("day", e.DayOfMonth.Values) + " " + listValues(e.DayOfMonth.Values, nil) + " of the month"
+	switch {
+	case e.DayOfMonth.Any && e.DayOfWeek.Any:
+		return ""
+	case e.DayOfWeek.Any:
+		return dom
+	case e.DayOfMonth.Any:
+		return describeWeekdays(e.DayOfWeek.Values)
+	}
+	return dom + " or " + describeWeekdays(e.DayOfWeek.Values)
+}
+
+func describeWeekdays(values []int) string {
+	switch fmt.Sprint(values) {
+	case "[1 2 3 4 5]":
+		return "on weekdays"
+	case "[0 6]":
+		return "on weekends"
+	}
+	return "on " + listValues(values, func(v int) string { return dayNames[v] })
+}
+
+// step - the n in */n when the field's values are evenly spaced from its minimum to its maximum
+func step(field *Field) (int, bool) {
+	b := fieldBounds[field.Kind]
+	vals := field.Values
+	if field.Any || len(vals) < 2 || vals[0] != b.min {
+		return 0, false
+	}
+	n := vals[1] - vals[0]
+	for i := 2; i < len(vals); i++ {
+		if vals[i]-vals[i-1] != n {
+			return 0, false
+		}
+	}
+	max := b.max
+	if field.Kind == DayOfWeek {
+		max = 6
+	}
+	if n < 2 || vals[len(vals)-1]+n <= max {
+		return 0, false
+	}
+	return n, true
+}
+
+// listValues - "1, 5 and 9" with runs of three or more collapsed to "1 through 5"
+func listValues(values []int, name func(int) string) string {
+	if name == nil {
+		name = func(v int) string { return fmt.Sprint(v) }
+	}
+	var items []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			items = append(items, name(values[i])+" through "+name(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				items = append(items, name(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return joinList(items)
+}
+
+func joinList(items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+func plural(word string, values []int) string {
+	if len(values) == 1 {
+		return word
+	}
+	return word + "s"
+}
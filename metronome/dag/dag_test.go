@@ -0,0 +1,279 @@
+package dag_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/dag"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeRun struct {
+	job     string
+	outcome met.RunStatus
+	polls   int
+}
+
+// fakeMetronome - runs finish with the next of their job's outcomes after being polled once; an empty outcome
+// never finishes.  Finished runs are only found in the history, as with Metronome
+type fakeMetronome struct {
+	met.Metronome
+	outcomes map[string][]met.RunStatus
+	runs     map[string]*fakeRun
+	started  []string
+	stopped  []string
+	// checked - called after each history lookup
+	checked func()
+}
+
+func newFake(outcomes map[string][]met.RunStatus) *fakeMetronome {
+	return &fakeMetronome{outcomes: outcomes, runs: make(map[string]*fakeRun)}
+}
+
+func (fake *fakeMetronome) StartJob(jobID string) (interface{}, error) {
+	outcomes, ok := fake.outcomes[jobID]
+	if !ok {
+		return nil, errors.New("no such job " + jobID)
+	}
+	outcome := met.RunSuccess
+	if len(outcomes) > 0 {
+		outcome, fake.outcomes[jobID] = outcomes[0], outcomes[1:]
+	}
+	fake.started = append(fake.started, jobID)
+	id := fmt.Sprintf("%s-%d", jobID, len(fake.started))
+	fake.runs[id] = &fakeRun{job: jobID, outcome: outcome}
+	return met.JobStatus{ID: id, JobID: jobID, Status: met.RunInitial}, nil
+}
+
+func (fake *fakeMetronome) StatusJob(jobID string, runID string) (*met.JobStatus, error) {
+	run, ok := fake.runs[runID]
+	if !ok || run.finished() {
+		return nil, errors.New("not found")
+	}
+	run.polls++
+	return &met.JobStatus{ID: runID, JobID: jobID, Status: met.RunActive}, nil
+}
+
+func (fake *fakeMetronome) RunHistory(jobID string, filter *met.RunFilter) (*[]met.HistoryStatus, error) {
+	history := []met.HistoryStatus{}
+	for id, run := range fake.runs {
+		if run.job == jobID && run.finished() {
+			history = append(history, met.HistoryStatus{ID: id, Status: run.outcome})
+		}
+	}
+	if fake.checked != nil {
+		fake.checked()
+	}
+	return &history, nil
+}
+
+func (fake *fakeMetronome) StopJob(jobID string, runID string) (interface{}, error) {
+	fake.stopped = append(fake.stopped, runID)
+	return nil, nil
+}
+
+func (run *fakeRun) finished() bool {
+	return run.outcome != "" && run.polls > 0
+}
+
+func diamond() *Workflow {
+	return &Workflow{
+		Name:         "nightly",
+		PollInterval: Duration(time.Millisecond),
+		Steps: []Step{
+			{Job: "extract"},
+			{Job: "clean", After: []string{"extract"}},
+			{Job: "index", After: []string{"extract"}},
+			{Job: "report", After: []string{"clean", "index"}},
+		},
+	}
+}
+
+func run(fake *fakeMetronome, workflow *Workflow) (*State, error) {
+	runner := &Runner{Client: fake, Workflow: workflow}
+	return runner.Run(make(chan struct{}))
+}
+
+var _ = Describe("Workflow", func() {
+	It("loads yaml", func() {
+		workflow, err := LoadWorkflow([]byte(`
+name: nightly
+retries: 1
+timeout: 2h
+pollInterval: 30s
+steps:
+  - job: extract
+  - name: load
+    job: loader
+    after: [extract]
+    onFailure: continue
+    retries: 0
+`))
+		Expect(err).To(BeNil())
+		Expect(time.Duration(workflow.Timeout)).To(Equal(2 * time.Hour))
+		Expect(time.Duration(workflow.PollInterval)).To(Equal(30 * time.Second))
+		Expect(workflow.Steps[1].Name).To(Equal("load"))
+		Expect(*workflow.Steps[1].Retries).To(Equal(0))
+		Expect(workflow.Steps[1].OnFailure).To(Equal(PolicyContinue))
+	})
+	It("rejects bad durations", func() {
+		_, err := LoadWorkflow([]byte("name: x\ntimeout: soon\nsteps: [{job: a}]"))
+		Expect(err).ToNot(BeNil())
+	})
+	invalid := map[string]Workflow{
+		"name required":  {Steps: []Step{{Job: "a"}}},
+		"steps required": {Name: "w"},
+		"job required":   {Name: "w", Steps: []Step{{Name: "a"}}},
+		"more than once": {Name: "w", Steps: []Step{{Job: "a"}, {Job: "a"}}},
+		"no step b":      {Name: "w", Steps: []Step{{Job: "a", After: []string{"b"}}}},
+		"onFailure":      {Name: "w", OnFailure: "panic", Steps: []Step{{Job: "a"}}},
+		"a -> c -> b -> a": {Name: "w", Steps: []Step{
+			{Job: "a", After: []string{"c"}},
+			{Job: "b", After: []string{"a"}},
+			{Job: "c", After: []string{"b"}},
+		}},
+	}
+	for message, workflow := range invalid {
+		message, workflow := message, workflow
+		It("rejects a workflow: "+message, func() {
+			err := workflow.Validate()
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring(message))
+		})
+	}
+})
+
+var _ = Describe("Runner", func() {
+	It("runs steps after the steps they come after, fanning in", func() {
+		fake := newFake(map[string][]met.RunStatus{"extract": nil, "clean": nil, "index": nil, "report": nil})
+		state, err := run(fake, diamond())
+		Expect(err).To(BeNil())
+		Expect(state.Succeeded()).To(BeTrue())
+		Expect(fake.started).To(Equal([]string{"extract", "clean", "index", "report"}))
+		Expect(state.Steps["report"].RunID).To(Equal("report-4"))
+	})
+	It("retries failed runs", func() {
+		fake := newFake(map[string][]met.RunStatus{"extract": {met.RunFailed, met.RunSuccess}, "clean": nil, "index": nil, "report": nil})
+		workflow := diamond()
+		workflow.Retries = 1
+		state, err := run(fake, workflow)
+		Expect(err).To(BeNil())
+		Expect(state.Steps["extract"].Attempts).To(Equal(2))
+		Expect(fake.started).To(HaveLen(5))
+	})
+	It("stops starting steps when a step fails", func() {
+		fake := newFake(map[string][]met.RunStatus{
+			"extract": nil, "clean": {met.RunFailed}, "index": nil, "report": nil, "other": nil,
+		})
+		workflow := diamond()
+		workflow.Steps = append(workflow.Steps, Step{Job: "other", After: []string{"index"}})
+		state, err := run(fake, workflow)
+		Expect(err).ToNot(BeNil())
+		Expect(err.Error()).To(ContainSubstring("failed: clean"))
+		Expect(fake.started).To(Equal([]string{"extract", "clean", "index"}))
+		Expect(state.Steps["index"].Status).To(Equal(StepSucceeded))
+		Expect(state.Steps["report"].Status).To(Equal(StepSkipped))
+		Expect(state.Steps["other"].Status).To(Equal(StepPending))
+	})
+	It("carries on with other branches when the policy is continue", func() {
+		fake := newFake(map[string][]met.RunStatus{
+			"extract": nil, "clean": {met.RunFailed}, "index": nil, "report": nil, "other": nil,
+		})
+		workflow := diamond()
+		workflow.OnFailure = PolicyContinue
+		workflow.Steps = append(workflow.Steps, Step{Job: "other", After: []string{"index"}})
+		state, err := run(fake, workflow)
+		Expect(err).ToNot(BeNil())
+		Expect(state.Steps["report"].Status).To(Equal(StepSkipped))
+		Expect(state.Steps["report"].Error).To(ContainSubstring("clean"))
+		Expect(state.Steps["other"].Status).To(Equal(StepSucceeded))
+	})
+	It("fails steps that can't be started", func() {
+		fake := newFake(map[string][]met.RunStatus{})
+		state, err := run(fake, &Workflow{Name: "w", PollInterval: Duration(time.Millisecond), Retries: 2, Steps: []Step{{Job: "missing"}}})
+		Expect(err).ToNot(BeNil())
+		Expect(state.Steps["missing"].Attempts).To(Equal(3))
+		Expect(state.Steps["missing"].Error).To(ContainSubstring("no such job"))
+	})
+	It("stops runs that time out", func() {
+		fake := newFake(map[string][]met.RunStatus{"slow": {""}})
+		timeout := Duration(5 * time.Millisecond)
+		state, err := run(fake, &Workflow{Name: "w", PollInterval: Duration(time.Millisecond), Steps: []Step{{Job: "slow", Timeout: &timeout}}})
+		Expect(err).ToNot(BeNil())
+		Expect(fake.stopped).To(Equal([]string{"slow-1"}))
+		Expect(state.Steps["slow"].Error).To(ContainSubstring("timed out"))
+	})
+	It("returns when stopped, leaving runs going", func() {
+		fake := newFake(map[string][]met.RunStatus{"slow": {""}})
+		stop := make(chan struct{})
+		close(stop)
+		runner := &Runner{Client: fake, Workflow: &Workflow{Name: "w", Steps: []Step{{Job: "slow"}}}}
+		state, err := runner.Run(stop)
+		Expect(err).To(Equal(ErrStopped))
+		Expect(state.Steps["slow"].Status).To(Equal(StepRunning))
+		Expect(fake.stopped).To(BeEmpty())
+	})
+
+	Context("state", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "dag")
+			Expect(err).To(BeNil())
+		})
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+		It("resumes, waiting for in-flight runs and re-running failed steps", func() {
+			file := filepath.Join(dir, "nightly.json")
+			fake := newFake(map[string][]met.RunStatus{"extract": nil, "clean": {met.RunFailed, met.RunSuccess}, "index": {""}, "report": nil})
+			stop := make(chan struct{})
+			runner := &Runner{Client: fake, Workflow: diamond(), StateFile: file}
+			fake.checked = func() {
+				if run := fake.runs["clean-2"]; run != nil && run.finished() {
+					fake.checked = nil
+					close(stop)
+				}
+			}
+			_, err := runner.Run(stop)
+			Expect(err).To(Equal(ErrStopped))
+
+			state, err := LoadState(file)
+			Expect(err).To(BeNil())
+			Expect(state.Steps["clean"].Status).To(Equal(StepFailed))
+			Expect(state.Steps["index"].Status).To(Equal(StepRunning))
+			Expect(state.Steps["report"].Status).To(Equal(StepSkipped))
+
+			fake.runs["index-3"].outcome = met.RunSuccess
+			Expect(state.Resume(diamond())).To(BeNil())
+			Expect(state.Steps["clean"].Status).To(Equal(StepPending))
+			runner = &Runner{Client: fake, Workflow: diamond(), StateFile: file, State: state}
+			state, err = runner.Run(make(chan struct{}))
+			Expect(err).To(BeNil())
+			Expect(fake.started).To(Equal([]string{"extract", "clean", "index", "clean", "report"}))
+		})
+		It("won't resume another workflow", func() {
+			state := NewState(diamond())
+			workflow := diamond()
+			workflow.Name = "weekly"
+			Expect(state.Resume(workflow)).ToNot(BeNil())
+		})
+		It("follows workflow changes", func() {
+			state := NewState(diamond())
+			state.Steps["extract"].Status = StepSucceeded
+			workflow := diamond()
+			workflow.Steps = append(workflow.Steps[:3], Step{Job: "publish", After: []string{"clean"}})
+			Expect(state.Resume(workflow)).To(BeNil())
+			Expect(state.Steps).ToNot(HaveKey("report"))
+			Expect(state.Steps["publish"].Status).To(Equal(StepPending))
+			Expect(state.Steps["extract"].Status).To(Equal(StepSucceeded))
+		})
+	})
+})
@@ -0,0 +1,240 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// ErrStopped - Run was stopped before the workflow finished.  Runs already started carry on; resume the saved state
+// to wait for them and continue
+var ErrStopped = errors.New("stopped before the workflow finished")
+
+// Runner - runs a workflow's steps on Metronome
+type Runner struct {
+	Client   met.Metronome
+	Workflow *Workflow
+	// StateFile - where progress is saved after every change.  Empty keeps it in memory only
+	StateFile string
+	// State - the progress to continue from, see State.Resume.  Nil starts every step afresh
+	State *State
+
+	stopping bool
+}
+
+// Run - start the workflow's steps as the steps they come after succeed and wait for them, until every step has
+// finished or stop is closed.  The error names the steps that failed, were skipped or weren't started
+func (runner *Runner) Run(stop <-chan struct{}) (*State, error) {
+	workflow := runner.Workflow
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+	state := runner.State
+	if state == nil {
+		state = NewState(workflow)
+	}
+	runner.State = state
+	interval := time.Duration(workflow.PollInterval)
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	runner.stopping = false
+	for {
+		runner.poll(state)
+		started := runner.start(state)
+		if err := runner.save(state); err != nil {
+			return state, err
+		}
+		if len(state.With(StepRunning)) == 0 && !started && !runner.ready(state) {
+			break
+		}
+		select {
+		case <-stop:
+			log.Infof("workflow %s stopped; running steps %v carry on", workflow.Name, state.With(StepRunning))
+			return state, ErrStopped
+		case <-time.After(interval):
+		}
+	}
+	if state.Succeeded() {
+		log.Infof("workflow %s succeeded", workflow.Name)
+		return state, nil
+	}
+	return state, runner.failure(state)
+}
+
+// poll - check on the running steps
+func (runner *Runner) poll(state *State) {
+	for i := range runner.Workflow.Steps {
+		step := &runner.Workflow.Steps[i]
+		current := state.Steps[step.name()]
+		if current.Status != StepRunning {
+			continue
+		}
+		status, err := met.RunOutcome(runner.Client, current.Job, current.RunID)
+		if err != nil {
+			log.Warnf("step %s: can't get the status of run %s: %s", step.name(), current.RunID, err)
+		}
+		if !status.IsTerminal() {
+			timeout := runner.Workflow.timeout(step)
+			if timeout <= 0 || time.Since(current.Started) < timeout {
+				continue
+			}
+			if _, err := runner.Client.StopJob(current.Job, current.RunID); err != nil {
+				log.Warnf("step %s: can't stop run %s: %s", step.name(), current.RunID, err)
+			}
+			runner.fail(state, step, fmt.Sprintf("run %s timed out after %s", current.RunID, timeout))
+			continue
+		}
+		if status.IsSuccess() {
+			current.Status = StepSucceeded
+			current.Finished = time.Now()
+			current.Error = ""
+			log.Infof("step %s: run %s of %s succeeded", step.name(), current.RunID, current.Job)
+			continue
+		}
+		runner.fail(state, step, fmt.Sprintf("run %s failed", current.RunID))
+	}
+}
+
+// start - skip the pending steps with an upstream step that didn't succeed and start those whose upstream steps
+// all succeeded.  Whether any run was started
+func (runner *Runner) start(state *State) bool {
+	runner.skip(state)
+	started := false
+	for i := range runner.Workflow.Steps {
+		step := &runner.Workflow.Steps[i]
+		current := state.Steps[step.name()]
+		if current.Status != StepPending {
+			continue
+		}
+		if runner.stopping || !runner.upstreamSucceeded(state, step) {
+			continue
+		}
+		current.Attempts++
+		current.Started = time.Now()
+		current.Finished = time.Time{}
+		current.RunID = ""
+		result, err := runner.Client.StartJob(step.Job)
+		if err == nil {
+			current.RunID = runID(result)
+			if current.RunID == "" {
+				err = errors.New("no run id in the response")
+			}
+		}
+		if err != nil {
+			runner.fail(state, step, fmt.Sprintf("can't start %s: %s", step.Job, err))
+			continue
+		}
+		current.Status = StepRunning
+		started = true
+		log.Infof("step %s: started run %s of %s (attempt %d)", step.name(), current.RunID, step.Job, current.Attempts)
+	}
+	return started
+}
+
+// fail - record a failed attempt.  The step goes back to pending while it has retries left, after which its
+// failure policy applies.  Either way the steps downstream of a failed step are skipped, see skip
+func (runner *Runner) fail(state *State, step *Step, reason string) {
+	current := state.Steps[step.name()]
+	current.Error = reason
+	current.Finished = time.Now()
+	if retries := runner.Workflow.retries(step); current.Attempts <= retries {
+		current.Status = StepPending
+		log.Warnf("step %s: %s; retry %d of %d", step.name(), reason, current.Attempts, retries)
+		return
+	}
+	current.Status = StepFailed
+	log.Errorf("step %s: %s", step.name(), reason)
+	if runner.Workflow.policy(step) == PolicyStop {
+		runner.stopping = true
+	}
+}
+
+// ready - whether a pending step could start now
+func (runner *Runner) ready(state *State) bool {
+	if runner.stopping {
+		return false
+	}
+	for i := range runner.Workflow.Steps {
+		step := &runner.Workflow.Steps[i]
+		if state.Steps[step.name()].Status == StepPending && runner.upstreamSucceeded(state, step) {
+			return true
+		}
+	}
+	return false
+}
+
+func (runner *Runner) upstreamSucceeded(state *State, step *Step) bool {
+	for _, after := range step.After {
+		if state.Steps[after].Status != StepSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// skip - skip the pending steps with an upstream step that failed or was skipped, and theirs in turn
+func (runner *Runner) skip(state *State) {
+	for skipped := true; skipped; {
+		skipped = false
+		for i := range runner.Workflow.Steps {
+			step := &runner.Workflow.Steps[i]
+			current := state.Steps[step.name()]
+			if current.Status != StepPending {
+				continue
+			}
+			if failed := runner.blockedBy(state, step); failed != "" {
+				current.Status = StepSkipped
+				current.Error = fmt.Sprintf("step %s did not succeed", failed)
+				skipped = true
+			}
+		}
+	}
+}
+
+// blockedBy - an upstream step that failed or was skipped, or empty
+func (runner *Runner) blockedBy(state *State, step *Step) string {
+	for _, after := range step.After {
+		if status := state.Steps[after].Status; status == StepFailed || status == StepSkipped {
+			return after
+		}
+	}
+	return ""
+}
+
+func (runner *Runner) save(state *State) error {
+	if runner.StateFile == "" {
+		return nil
+	}
+	if err := state.Save(runner.StateFile); err != nil {
+		return fmt.Errorf("can't save the workflow state to %s: %s", runner.StateFile, err)
+	}
+	return nil
+}
+
+func (runner *Runner) failure(state *State) error {
+	var problems []string
+	for _, status := range []StepStatus{StepFailed, StepSkipped, StepPending} {
+		if names := state.With(status); len(names) > 0 {
+			problems = append(problems, fmt.Sprintf("%s: %s", status, strings.Join(names, ",")))
+		}
+	}
+	return fmt.Errorf("workflow %s failed; %s", runner.Workflow.Name, strings.Join(problems, "; "))
+}
+
+// runID - the run id in StartJob's response
+func runID(result interface{}) string {
+	switch run := result.(type) {
+	case met.JobStatus:
+		return run.ID
+	case *met.JobStatus:
+		if run != nil {
+			return run.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,150 @@
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// StepStatus - where a step is in the workflow
+type StepStatus string
+
+// Step statuses
+const (
+	// StepPending - waiting for the steps it comes after
+	StepPending StepStatus = "pending"
+	// StepRunning - a run was started and hasn't finished
+	StepRunning StepStatus = "running"
+	// StepSucceeded - the last run succeeded
+	StepSucceeded StepStatus = "succeeded"
+	// StepFailed - every attempt failed
+	StepFailed StepStatus = "failed"
+	// StepSkipped - a step it comes after failed
+	StepSkipped StepStatus = "skipped"
+)
+
+// StepState - the progress of one step
+type StepState struct {
+	Status StepStatus `json:"status"`
+	Job    string     `json:"job"`
+	// RunID - the current or last run
+	RunID string `json:"runId,omitempty"`
+	// Attempts - runs started, including retries
+	Attempts int       `json:"attempts,omitempty"`
+	Started  time.Time `json:"started,omitempty"`
+	Finished time.Time `json:"finished,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// State - the progress of a workflow, as saved to the state file
+type State struct {
+	Workflow string                `json:"workflow"`
+	Started  time.Time             `json:"started"`
+	Updated  time.Time             `json:"updated"`
+	Steps    map[string]*StepState `json:"steps"`
+}
+
+// NewState - every step of workflow pending
+func NewState(workflow *Workflow) *State {
+	state := &State{Workflow: workflow.Name, Started: time.Now(), Steps: make(map[string]*StepState)}
+	state.sync(workflow)
+	return state
+}
+
+// LoadState - the state saved in file
+func LoadState(file string) (*State, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	if state.Steps == nil {
+		state.Steps = make(map[string]*StepState)
+	}
+	return &state, nil
+}
+
+// Save - write the state to file.  The file is replaced in one rename so an interruption never leaves half a state
+func (state *State) Save(file string) error {
+	state.Updated = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Resume - prepare a saved state to run workflow again.  Succeeded and running steps are kept, so finished work
+// isn't repeated and in-flight runs are waited for; failed and skipped steps go back to pending.  Steps added to the
+// workflow since are pending and steps removed from it are dropped
+func (state *State) Resume(workflow *Workflow) error {
+	if state.Workflow != workflow.Name {
+		return fmt.Errorf("state is for workflow %s not %s", state.Workflow, workflow.Name)
+	}
+	for _, step := range state.Steps {
+		if step.Status == StepFailed || step.Status == StepSkipped {
+			*step = StepState{Status: StepPending, Job: step.Job}
+		}
+	}
+	state.sync(workflow)
+	return nil
+}
+
+// sync - a state for every step of workflow and none for others.  A step whose job changed starts over
+func (state *State) sync(workflow *Workflow) {
+	names := make(map[string]bool, len(workflow.Steps))
+	for _, step := range workflow.Steps {
+		name := step.name()
+		names[name] = true
+		if current, ok := state.Steps[name]; !ok || current.Job != step.Job {
+			state.Steps[name] = &StepState{Status: StepPending, Job: step.Job}
+		}
+	}
+	for name := range state.Steps {
+		if !names[name] {
+			delete(state.Steps, name)
+		}
+	}
+}
+
+// Succeeded - whether every step succeeded
+func (state *State) Succeeded() bool {
+	for _, step := range state.Steps {
+		if step.Status != StepSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// With - the names of the steps with status, sorted
+func (state *State) With(status StepStatus) []string {
+	var names []string
+	for name, step := range state.Steps {
+		if step.Status == status {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
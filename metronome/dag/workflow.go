@@ -0,0 +1,223 @@
+// Package dag runs Metronome jobs as a workflow: a directed acyclic graph of steps where each step starts a job
+// once the steps it comes after have succeeded.
+//
+// A step with several upstream steps (fan-in) waits for all of them.  A failed run is retried Retries times; once
+// retries are exhausted OnFailure decides what happens: stop starts nothing new and fails the workflow when the
+// running steps finish, continue skips the failed step's downstream steps but lets the other branches run on.
+// Progress is saved to a state file after every change so an interrupted workflow can be resumed, re-attaching to
+// runs that were in flight.
+package dag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Policy - what a step's failure does to the rest of the workflow, once its retries are used up
+type Policy string
+
+// Failure policies
+const (
+	// PolicyStop - start no more steps; the workflow fails once the running steps finish
+	PolicyStop Policy = "stop"
+	// PolicyContinue - skip the steps downstream of the failed one and carry on with the others
+	PolicyContinue Policy = "continue"
+)
+
+// DefaultPollInterval - how often run outcomes are checked when the workflow doesn't say
+const DefaultPollInterval = 10 * time.Second
+
+// Duration - a time.Duration written as a string such as 30s or 1h30m
+type Duration time.Duration
+
+// MarshalJSON - json interface implementation
+func (duration Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(duration).String())
+}
+
+// UnmarshalJSON - json interface implementation
+func (duration *Duration) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as 30s not %s", raw)
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*duration = Duration(d)
+	return nil
+}
+
+// Workflow - the steps and the defaults they inherit
+type Workflow struct {
+	Name string `json:"name"`
+	// OnFailure - the default failure policy.  Defaults to stop
+	OnFailure Policy `json:"onFailure,omitempty"`
+	// Retries - the default number of times a failed run is retried
+	Retries int `json:"retries,omitempty"`
+	// Timeout - the default time a run may take before it is stopped and counted as failed.  Zero waits forever
+	Timeout Duration `json:"timeout,omitempty"`
+	// PollInterval - how often run outcomes are checked.  Defaults to DefaultPollInterval
+	PollInterval Duration `json:"pollInterval,omitempty"`
+	Steps        []Step   `json:"steps"`
+}
+
+// Step - a job to run once every step in After has succeeded
+type Step struct {
+	// Name - identifies the step.  Defaults to Job
+	Name string `json:"name,omitempty"`
+	Job  string `json:"job"`
+	// After - the steps that must succeed first
+	After     []string  `json:"after,omitempty"`
+	OnFailure Policy    `json:"onFailure,omitempty"`
+	Retries   *int      `json:"retries,omitempty"`
+	Timeout   *Duration `json:"timeout,omitempty"`
+}
+
+// LoadWorkflow - a workflow from yaml or json, checked with Validate
+func LoadWorkflow(data []byte) (*Workflow, error) {
+	var workflow Workflow
+	if err := met.FromYAML(data, &workflow); err != nil {
+		return nil, err
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+	return &workflow, nil
+}
+
+// Validate - a name, steps with jobs and unique names, known After steps, known policies and no cycles
+func (workflow *Workflow) Validate() error {
+	if workflow.Name == "" {
+		return errors.New("name required")
+	} else if len(workflow.Steps) == 0 {
+		return errors.New("steps required")
+	} else if err := workflow.OnFailure.validate(); err != nil {
+		return fmt.Errorf("onFailure: %s", err)
+	} else if workflow.Retries < 0 {
+		return errors.New("retries must not be negative")
+	}
+	names := make(map[string]bool, len(workflow.Steps))
+	for i, step := range workflow.Steps {
+		if step.Job == "" {
+			return fmt.Errorf("steps[%d]: job required", i)
+		}
+		name := step.name()
+		if names[name] {
+			return fmt.Errorf("steps[%d]: step %s defined more than once", i, name)
+		}
+		names[name] = true
+		if err := step.OnFailure.validate(); err != nil {
+			return fmt.Errorf("step %s: onFailure: %s", name, err)
+		} else if step.Retries != nil && *step.Retries < 0 {
+			return fmt.Errorf("step %s: retries must not be negative", name)
+		}
+	}
+	for _, step := range workflow.Steps {
+		for _, after := range step.After {
+			if !names[after] {
+				return fmt.Errorf("step %s: after: no step %s", step.name(), after)
+			} else if after == step.name() {
+				return fmt.Errorf("step %s: comes after itself", step.name())
+			}
+		}
+	}
+	if cycle := workflow.cycle(); cycle != nil {
+		return fmt.Errorf("steps form a cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+func (policy Policy) validate() error {
+	switch policy {
+	case "", PolicyStop, PolicyContinue:
+		return nil
+	}
+	return fmt.Errorf("must be one of %s,%s not '%s'", PolicyStop, PolicyContinue, policy)
+}
+
+func (step *Step) name() string {
+	if step.Name == "" {
+		return step.Job
+	}
+	return step.Name
+}
+
+// step - the step named name
+func (workflow *Workflow) step(name string) *Step {
+	for i := range workflow.Steps {
+		if workflow.Steps[i].name() == name {
+			return &workflow.Steps[i]
+		}
+	}
+	return nil
+}
+
+func (workflow *Workflow) policy(step *Step) Policy {
+	if step.OnFailure != "" {
+		return step.OnFailure
+	} else if workflow.OnFailure != "" {
+		return workflow.OnFailure
+	}
+	return PolicyStop
+}
+
+func (workflow *Workflow) retries(step *Step) int {
+	if step.Retries != nil {
+		return *step.Retries
+	}
+	return workflow.Retries
+}
+
+func (workflow *Workflow) timeout(step *Step) time.Duration {
+	if step.Timeout != nil {
+		return time.Duration(*step.Timeout)
+	}
+	return time.Duration(workflow.Timeout)
+}
+
+// cycle - the steps of a cycle, or nil
+func (workflow *Workflow) cycle() []string {
+	const (
+		visiting = iota + 1
+		done
+	)
+	marks := make(map[string]int)
+	var path []string
+	var visit func(step *Step) []string
+	visit = func(step *Step) []string {
+		name := step.name()
+		switch marks[name] {
+		case visiting:
+			for i, p := range path {
+				if p == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		case done:
+			return nil
+		}
+		marks[name] = visiting
+		path = append(path, name)
+		for _, after := range step.After {
+			if cycle := visit(workflow.step(after)); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		marks[name] = done
+		return nil
+	}
+	for i := range workflow.Steps {
+		if cycle := visit(&workflow.Steps[i]); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package metronome
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change - one difference between two jobs or schedules.  Path is the json path of the value e.g. run.docker.image,
+// run.volumes[0].mode or schedules.nightly.cron.  From is nil for additions and To is nil for removals
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// String - `+ path: to`, `- path: from` or `~ path: from -> to`
+func (change Change) String() string {
+	switch {
+	case change.From == nil:
+		return fmt.Sprintf("+ %s: %s", change.Path, jsonText(change.To))
+	case change.To == nil:
+		return fmt.Sprintf("- %s: %s", change.Path, jsonText(change.From))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", change.Path, jsonText(change.From), jsonText(change.To))
+}
+
+func jsonText(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// Diff - what changes turn theJob into other.  Runtime detail (activeRuns, history, historySummary and each
+// schedule's nextRunAt) is ignored, nil and empty collections are alike and schedules are matched by id
+func (theJob *Job) Diff(other *Job) ([]Change, error) {
+	from, err := jobValue(theJob)
+	if err != nil {
+		return nil, err
+	}
+	to, err := jobValue(other)
+	if err != nil {
+		return nil, err
+	}
+	changes := []Change{}
+	diffValues("", from, to, &changes)
+	return changes, nil
+}
+
+// Equal - whether the jobs configure the same thing.  See Diff for what is ignored
+func (theJob *Job) Equal(other *Job) bool {
+	changes, err := theJob.Diff(other)
+	return err == nil && len(changes) == 0
+}
+
+// Diff - what changes turn sched into other, ignoring nextRunAt
+func (sched *Schedule) Diff(other *Schedule) ([]Change, error) {
+	from, err := scheduleValue(sched)
+	if err != nil {
+		return nil, err
+	}
+	to, err := scheduleValue(other)
+	if err != nil {
+		return nil, err
+	}
+	changes := []Change{}
+	diffValues("", from, to, &changes)
+	return changes, nil
+}
+
+// Equal - whether the schedules are the same, ignoring nextRunAt
+func (sched *Schedule) Equal(other *Schedule) bool {
+	changes, err := sched.Diff(other)
+	return err == nil && len(changes) == 0
+}
+
+// jobValue - the job as generic json without runtime detail and with schedules keyed by id
+func jobValue(theJob *Job) (interface{}, error) {
+	if theJob == nil {
+		return nil, nil
+	}
+	var value map[string]interface{}
+	if err := remarshal(theJob, &value); err != nil {
+		return nil, err
+	}
+	delete(value, "activeRuns")
+	delete(value, "history")
+	delete(value, "historySummary")
+	delete(value, "schedules")
+	if len(theJob.Schedules) > 0 {
+		schedules := make(map[string]interface{}, len(theJob.Schedules))
+		for i, sched := range theJob.Schedules {
+			s, err := scheduleValue(sched)
+			if err != nil {
+				return nil, err
+			}
+			id := fmt.Sprintf("[%d]", i)
+			if sched != nil && sched.ID != "" {
+				id = sched.ID
+			}
+			schedules[id] = s
+		}
+		value["schedules"] = schedules
+	}
+	return normalize(value), nil
+}
+
+func scheduleValue(sched *Schedule) (interface{}, error) {
+	if sched == nil {
+		return nil, nil
+	}
+	var value map[string]interface{}
+	if err := remarshal(sched, &value); err != nil {
+		return nil, err
+	}
+	delete(value, "nextRunAt")
+	return normalize(value), nil
+}
+
+func remarshal(in interface{}, out interface{}) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// normalize - drop nulls and empty objects and arrays so they compare equal to absent values
+func normalize(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			if v = normalize(v); v == nil {
+				delete(value, k)
+			} else {
+				value[k] = v
+			}
+		}
+		if len(value) == 0 {
+			return nil
+		}
+	case []interface{}:
+		if len(value) == 0 {
+			return nil
+		}
+		for i, v := range value {
+			value[i] = normalize(v)
+		}
+	}
+	return value
+}
+
+func diffValues(path string, from interface{}, to interface{}, changes *[]Change) {
+	switch {
+	case from == nil && to == nil:
+		return
+	case from == nil || to == nil:
+		*changes = append(*changes, Change{Path: path, From: from, To: to})
+		return
+	}
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make([]string, 0, len(fromMap)+len(toMap))
+		for k := range fromMap {
+			keys = append(keys, k)
+		}
+		for k := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			diffValues(fieldPath(path, k), fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			var f, t interface{}
+			if i < len(fromList) {
+				f = fromList[i]
+			}
+			if i < len(toList) {
+				t = toList[i]
+			}
+			diffValues(fmt.Sprintf("%s[%d]", path, i), f, t, changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Path: path, From: from, To: to})
+	}
+}
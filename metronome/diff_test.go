@@ -0,0 +1,121 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func diffJob() *Job {
+	labels := Labels{"owner": "zeus"}
+	return &Job{
+		ID:     "prod.example",
+		Labels: &labels,
+		Run: &Run{
+			Cpus: 1, Mem: 128, MaxLaunchDelay: 3600,
+			Docker:    &Docker{Image: "alpine:3.4"},
+			Env:       map[string]string{"STAGE": "prod"},
+			Placement: &Placement{Constraints: []Constraint{{Attribute: "rack", Operator: IS, Value: "rack-1"}}},
+			Volumes:   []Volume{{ContainerPath: "/mnt", HostPath: "/data", Mode: RO}},
+		},
+		Schedules: []*Schedule{{ID: "nightly", Cron: "0 2 * * *", ConcurrencyPolicy: "ALLOW", Enabled: true, Timezone: "UTC"}},
+	}
+}
+
+var _ = Describe("DeepCopy", func() {
+	It("Shares nothing with the original", func() {
+		job := diffJob()
+		job.Run.Extra = Extra{"gpus": json.RawMessage(`1`)}
+		clone := job.DeepCopy()
+		Expect(clone).To(Equal(job))
+
+		(*clone.Labels)["owner"] = "hera"
+		clone.Run.Docker.Image = "busybox"
+		clone.Run.Env["STAGE"] = "dev"
+		clone.Run.Placement.Constraints[0].Value = "rack-2"
+		clone.Run.Volumes[0].Mode = RW
+		clone.Run.Extra["gpus"][0] = '2'
+		clone.Schedules[0].Cron = "@hourly"
+
+		original := diffJob()
+		original.Run.Extra = Extra{"gpus": json.RawMessage(`1`)}
+		Expect(job).To(Equal(original))
+	})
+
+	It("Copies nil as nil", func() {
+		var job *Job
+		Expect(job.DeepCopy()).To(BeNil())
+		Expect((&Job{ID: "a"}).DeepCopy().Run).To(BeNil())
+	})
+})
+
+var _ = Describe("Diff", func() {
+	It("Finds nothing between a job and its copy", func() {
+		job := diffJob()
+		changes, err := job.Diff(job.DeepCopy())
+		Expect(err).To(BeNil())
+		Expect(changes).To(BeEmpty())
+		Expect(job.Equal(job.DeepCopy())).To(BeTrue())
+	})
+
+	It("Ignores runtime detail and empty collections", func() {
+		live := diffJob()
+		live.Run.Artifacts = []Artifact{}
+		live.Run.Args = []string{}
+		live.Run.EnvSecrets = map[string]string{}
+		live.Schedules[0].NextRunAt = "2017-01-01T02:00:00.000+0000"
+		live.HistorySummary = &HistorySummary{SuccessCount: 3}
+		live.ActiveRuns = []*ActiveRun{{ID: "20170101"}}
+		Expect(diffJob().Equal(live)).To(BeTrue())
+	})
+
+	It("Reports changes by json path", func() {
+		desired := diffJob()
+		desired.Run.Docker.Image = "alpine:3.5"
+		desired.Run.Env["DEBUG"] = "1"
+		delete(*desired.Labels, "owner")
+		desired.Run.Volumes = append(desired.Run.Volumes, Volume{ContainerPath: "/tmp", HostPath: "/tmp", Mode: RW})
+		desired.Schedules[0].Enabled = false
+		desired.Schedules = append(desired.Schedules, &Schedule{ID: "hourly", Cron: "@hourly"})
+
+		changes, err := diffJob().Diff(desired)
+		Expect(err).To(BeNil())
+		var paths []string
+		for _, change := range changes {
+			paths = append(paths, change.Path)
+		}
+		Expect(paths).To(Equal([]string{
+			"labels",
+			"run.docker.image",
+			"run.env.DEBUG",
+			"run.volumes[1]",
+			"schedules.hourly",
+			"schedules.nightly.enabled",
+		}))
+		Expect(changes[0].String()).To(Equal(`- labels: {"owner":"zeus"}`))
+		Expect(changes[1].String()).To(Equal(`~ run.docker.image: "alpine:3.4" -> "alpine:3.5"`))
+		Expect(changes[2].String()).To(Equal(`+ run.env.DEBUG: "1"`))
+		Expect(changes[5]).To(Equal(Change{Path: "schedules.nightly.enabled", From: true, To: false}))
+	})
+
+	It("Matches schedules by id rather than position", func() {
+		a := diffJob()
+		a.Schedules = append(a.Schedules, &Schedule{ID: "hourly", Cron: "@hourly"})
+		b := a.DeepCopy()
+		b.Schedules[0], b.Schedules[1] = b.Schedules[1], b.Schedules[0]
+		Expect(a.Equal(b)).To(BeTrue())
+	})
+
+	It("Diffs schedules", func() {
+		sched := Schedule{ID: "nightly", Cron: "0 2 * * *", Timezone: "UTC"}
+		other := sched
+		other.Timezone = "America/New_York"
+		changes, err := sched.Diff(&other)
+		Expect(err).To(BeNil())
+		Expect(changes).To(Equal([]Change{{Path: "timezone", From: "UTC", To: "America/New_York"}}))
+		Expect(sched.Equal(&sched)).To(BeTrue())
+	})
+})
@@ -0,0 +1,248 @@
+package metronome
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Extra - json properties a model doesn't declare.  They are captured on unmarshal and written back on
+// marshal so GetJob -> modify -> UpdateJob doesn't drop settings added by newer Metronome versions
+type Extra map[string]json.RawMessage
+
+var knownFieldsCache = struct {
+	sync.Mutex
+	fields map[reflect.Type]map[string]bool
+}{fields: make(map[reflect.Type]map[string]bool)}
+
+// knownFields - the lowercased json names of a struct's fields.  encoding/json matches names case-insensitively
+func knownFields(t reflect.Type) map[string]bool {
+	knownFieldsCache.Lock()
+	defer knownFieldsCache.Unlock()
+	if known, ok := knownFieldsCache.fields[t]; ok {
+		return known
+	}
+	known := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+	knownFieldsCache.fields[t] = known
+	return known
+}
+
+// extraFields - the properties of the json object raw that aren't fields of model
+func extraFields(raw []byte, model interface{}) (Extra, error) {
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &props); err != nil {
+		return nil, err
+	}
+	known := knownFields(reflect.Indirect(reflect.ValueOf(model)).Type())
+	var extra Extra
+	for name, value := range props {
+		if known[strings.ToLower(name)] {
+			continue
+		}
+		if extra == nil {
+			extra = make(Extra)
+		}
+		extra[name] = value
+	}
+	return extra, nil
+}
+
+// withExtra - add extra's properties to the marshalled json object b
+func withExtra(b []byte, extra Extra) ([]byte, error) {
+	if len(extra) == 0 {
+		return b, nil
+	}
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(b, &props); err != nil {
+		return nil, err
+	}
+	for name, value := range extra {
+		if _, declared := props[name]; !declared {
+			props[name] = value
+		}
+	}
+	return json.Marshal(props)
+}
+
+// marshalExtra - alias, a model without its json methods, as json with extra's properties added
+func marshalExtra(alias interface{}, extra Extra) ([]byte, error) {
+	b, err := json.Marshal(alias)
+	if err != nil {
+		return nil, err
+	}
+	return withExtra(b, extra)
+}
+
+// unmarshalExtra - decode raw into alias, a model without its json methods.  Returns the properties it doesn't declare
+func unmarshalExtra(raw []byte, alias interface{}) (Extra, error) {
+	if err := json.Unmarshal(raw, alias); err != nil {
+		return nil, err
+	}
+	return extraFields(raw, alias)
+}
+
+// jobAlias - Job without its json methods
+type jobAlias Job
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (theJob Job) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*jobAlias)(&theJob), theJob.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (theJob *Job) UnmarshalJSON(raw []byte) (err error) {
+	theJob.Extra, err = unmarshalExtra(raw, (*jobAlias)(theJob))
+	return err
+}
+
+// scheduleAlias - Schedule without its json methods
+type scheduleAlias Schedule
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (sched Schedule) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*scheduleAlias)(&sched), sched.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (sched *Schedule) UnmarshalJSON(raw []byte) (err error) {
+	sched.Extra, err = unmarshalExtra(raw, (*scheduleAlias)(sched))
+	return err
+}
+
+// artifactAlias - Artifact without its json methods
+type artifactAlias Artifact
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (theArtifact Artifact) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*artifactAlias)(&theArtifact), theArtifact.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (theArtifact *Artifact) UnmarshalJSON(raw []byte) (err error) {
+	theArtifact.Extra, err = unmarshalExtra(raw, (*artifactAlias)(theArtifact))
+	return err
+}
+
+// dockerAlias - Docker without its json methods
+type dockerAlias Docker
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (docker Docker) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*dockerAlias)(&docker), docker.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (docker *Docker) UnmarshalJSON(raw []byte) (err error) {
+	docker.Extra, err = unmarshalExtra(raw, (*dockerAlias)(docker))
+	return err
+}
+
+// dockerParameterAlias - DockerParameter without its json methods
+type dockerParameterAlias DockerParameter
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (param DockerParameter) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*dockerParameterAlias)(&param), param.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (param *DockerParameter) UnmarshalJSON(raw []byte) (err error) {
+	param.Extra, err = unmarshalExtra(raw, (*dockerParameterAlias)(param))
+	return err
+}
+
+// constraintAlias - Constraint without its json methods
+type constraintAlias Constraint
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (theConstraint Constraint) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*constraintAlias)(&theConstraint), theConstraint.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (theConstraint *Constraint) UnmarshalJSON(raw []byte) (err error) {
+	theConstraint.Extra, err = unmarshalExtra(raw, (*constraintAlias)(theConstraint))
+	return err
+}
+
+// placementAlias - Placement without its json methods
+type placementAlias Placement
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (thePlacement Placement) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*placementAlias)(&thePlacement), thePlacement.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (thePlacement *Placement) UnmarshalJSON(raw []byte) (err error) {
+	thePlacement.Extra, err = unmarshalExtra(raw, (*placementAlias)(thePlacement))
+	return err
+}
+
+// restartAlias - Restart without its json methods
+type restartAlias Restart
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (restart Restart) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*restartAlias)(&restart), restart.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (restart *Restart) UnmarshalJSON(raw []byte) (err error) {
+	restart.Extra, err = unmarshalExtra(raw, (*restartAlias)(restart))
+	return err
+}
+
+// uCRAlias - UCR without its json methods
+type uCRAlias UCR
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (ucr UCR) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*uCRAlias)(&ucr), ucr.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (ucr *UCR) UnmarshalJSON(raw []byte) (err error) {
+	ucr.Extra, err = unmarshalExtra(raw, (*uCRAlias)(ucr))
+	return err
+}
+
+// uCRImageAlias - UCRImage without its json methods
+type uCRImageAlias UCRImage
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (image UCRImage) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*uCRImageAlias)(&image), image.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (image *UCRImage) UnmarshalJSON(raw []byte) (err error) {
+	image.Extra, err = unmarshalExtra(raw, (*uCRImageAlias)(image))
+	return err
+}
+
+// secretSourceAlias - SecretSource without its json methods
+type secretSourceAlias SecretSource
+
+// MarshalJSON - json interface implementation.  Writes back properties kept in Extra
+func (secret SecretSource) MarshalJSON() ([]byte, error) {
+	return marshalExtra((*secretSourceAlias)(&secret), secret.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (secret *SecretSource) UnmarshalJSON(raw []byte) (err error) {
+	secret.Extra, err = unmarshalExtra(raw, (*secretSourceAlias)(secret))
+	return err
+}
@@ -0,0 +1,120 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const futureJob = `{
+  "id": "prod.example", "description": "", "sla": {"maxRuntime": 3600},
+  "run": {
+    "cpus": 1, "mem": 128, "disk": 0, "maxLaunchDelay": 3600, "volumes": [],
+    "gpus": 1, "networks": [{"mode": "host"}], "env": {"A": "1"}
+  },
+  "schedules": [{"id": "nightly", "cron": "0 2 * * *", "concurrencyPolicy": "ALLOW", "enabled": true,
+                 "startingDeadlineSeconds": 60, "timezone": "UTC", "catchUp": true}]
+}`
+
+const futureNested = `{
+  "id": "prod.nested", "description": "",
+  "run": {
+    "cpus": 1, "mem": 128, "disk": 0, "maxLaunchDelay": 3600,
+    "artifacts": [{"uri": "http://example.com/a.tgz", "cache": false, "executable": false, "extract": false, "checksum": "abc"}],
+    "docker": {"image": "busybox", "parameters": [{"key": "label", "value": "a=b", "scope": "task"}], "pullConfig": {"secret": "pull"}},
+    "placement": {"constraints": [{"attribute": "rack", "operator": "LIKE", "value": "r1", "weight": 2}], "regions": ["us"]},
+    "restart": {"policy": "NEVER", "activeDeadlineSeconds": 0, "backoff": 10},
+    "volumes": [{"containerPath": "/data", "hostPath": "/mnt/data", "mode": "RW", "persistent": true},
+                {"containerPath": "/secret", "secret": "token", "readOnly": true}],
+    "secrets": {"token": {"source": "/prod/token", "version": 3}}
+  }
+}`
+
+var _ = Describe("Unknown fields", func() {
+	It("Keeps properties the models don't declare", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(futureJob), &job)).To(BeNil())
+		Expect(job.Extra).To(HaveKey("sla"))
+		Expect(job.Run.Extra).To(HaveLen(2))
+		Expect(string(job.Run.Extra["gpus"])).To(Equal("1"))
+		Expect(job.Schedules[0].Extra).To(HaveKey("catchUp"))
+
+		b, err := json.Marshal(&job)
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(futureJob))
+	})
+
+	It("Survives read-modify-write", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(futureJob), &job)).To(BeNil())
+		job.Run.SetCpus(2)
+		job.Schedules[0].Cron = "0 3 * * *"
+
+		var back map[string]interface{}
+		b, err := json.Marshal(job)
+		Expect(err).To(BeNil())
+		Expect(json.Unmarshal(b, &back)).To(BeNil())
+		Expect(back).To(HaveKey("sla"))
+		run := back["run"].(map[string]interface{})
+		Expect(run["cpus"]).To(BeEquivalentTo(2))
+		Expect(run).To(HaveKey("networks"))
+		sched := back["schedules"].([]interface{})[0].(map[string]interface{})
+		Expect(sched["cron"]).To(Equal("0 3 * * *"))
+		Expect(sched["catchUp"]).To(BeTrue())
+	})
+
+	It("Keeps properties of nested objects the models don't declare", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(futureNested), &job)).To(BeNil())
+		Expect(job.Run.Artifacts[0].Extra).To(HaveKey("checksum"))
+		Expect(job.Run.Docker.Extra).To(HaveKey("pullConfig"))
+		Expect(job.Run.Docker.Parameters[0].Extra).To(HaveKey("scope"))
+		Expect(job.Run.Placement.Extra).To(HaveKey("regions"))
+		Expect(job.Run.Placement.Constraints[0].Extra).To(HaveKey("weight"))
+		Expect(job.Run.Restart.Extra).To(HaveKey("backoff"))
+		Expect(job.Run.Volumes[0].Extra).To(HaveKey("persistent"))
+		Expect(job.Run.Volumes[1].Extra).To(HaveKey("readOnly"))
+		Expect(job.Run.Secrets["token"].Extra).To(HaveKey("version"))
+
+		job.Run.SetCpus(2)
+		job.Run.Docker.Image = "busybox:1"
+		b, err := json.Marshal(job.DeepCopy())
+		Expect(err).To(BeNil())
+		var back, want map[string]interface{}
+		Expect(json.Unmarshal(b, &back)).To(BeNil())
+		Expect(json.Unmarshal([]byte(futureNested), &want)).To(BeNil())
+		run := want["run"].(map[string]interface{})
+		run["cpus"] = 2
+		run["docker"].(map[string]interface{})["image"] = "busybox:1"
+		wanted, _ := json.Marshal(want)
+		Expect(b).To(MatchJSON(wanted))
+	})
+
+	It("Keeps properties of a ucr image the models don't declare", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(`{"cpus":1,"mem":64,"disk":0,"ucr":{"image":{"id":"busybox","pullConfig":{"secret":"p"}},"seccomp":{"unconfined":true}}}`), &run)).To(BeNil())
+		Expect(run.UCR.Image.Extra).To(HaveKey("pullConfig"))
+		Expect(run.UCR.Extra).To(HaveKey("seccomp"))
+		b, err := json.Marshal(run)
+		Expect(err).To(BeNil())
+		Expect(b).To(ContainSubstring(`"pullConfig":{"secret":"p"}`))
+		Expect(b).To(ContainSubstring(`"seccomp":{"unconfined":true}`))
+	})
+
+	It("Prefers declared fields over extras with the same name", func() {
+		sched := Schedule{ID: "s", Cron: "@daily", Extra: Extra{"id": json.RawMessage(`"stale"`)}}
+		b, err := json.Marshal(sched)
+		Expect(err).To(BeNil())
+		Expect(b).To(ContainSubstring(`"id":"s"`))
+	})
+
+	It("Leaves Extra nil when every property is known", func() {
+		var sched Schedule
+		Expect(json.Unmarshal([]byte(`{"id":"s","cron":"@daily","Enabled":true}`), &sched)).To(BeNil())
+		Expect(sched.Extra).To(BeNil())
+		Expect(sched.Enabled).To(BeTrue())
+	})
+})
@@ -0,0 +1,175 @@
+package metronome
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	duration "github.com/ChannelMeter/iso8601duration"
+)
+
+var repeatRegex = regexp.MustCompile(`^R(?P<repeat>\d*)$`)
+
+// MaxOneShotSchedules - a finite repeat count is converted into one pinned schedule per run.  Larger counts are rejected
+const MaxOneShotSchedules = 24
+
+const minutesPerDay = 24 * 60
+
+// ConvertIso8601ToCron - converts an iso8601 repeating interval into a single cron expression.
+//  - A value that isn't a 3 part R<n>/<start>/<interval> yields a crontab for now as it always has
+//  - Intervals needing more than one schedule are an error.  Use ConvertIso8601ToSchedules
+func ConvertIso8601ToCron(isoRep string) (string, error) {
+	if len(strings.Split(isoRep, "/")) != 3 {
+		return ImmediateCrontab(), nil
+	}
+	scheds, err := ConvertIso8601ToSchedules(isoRep, time.Now())
+	if err != nil {
+		return "", err
+	}
+	if len(scheds) != 1 {
+		return "", fmt.Errorf("'%s' needs %d cron expressions.  Use ConvertIso8601ToSchedules", isoRep, len(scheds))
+	}
+	return scheds[0].Cron, nil
+}
+
+// ConvertIso8601ToSchedules - converts a Chronos style iso8601 repeating interval R<n>/<start>/<interval> into Metronome schedules.
+//  - An empty start means now.  Schedules are in UTC and fire at the start time's phase e.g. R/2017-01-01T00:10:00Z/PT30M fires at :10 and :40.
+//    Cron can't delay a first run, so an endless interval whose start is more than one interval after now is an error;
+//    a schedule in its phase would fire before the start.  A start up to one interval away, such as the next run time
+//    Chronos keeps, is honoured since the first fire in phase is the start itself
+//  - R<n> with n <= MaxOneShotSchedules becomes one schedule per remaining run, each pinned to its year.  Runs before now are dropped
+//  - R<n> with n > MaxOneShotSchedules is an error since a cron has no repeat count
+//  - Only a bare R repeats forever.  Intervals dividing a day become one schedule per distinct set of hours;
+//    daily and weekly intervals become a single schedule.  Other intervals can't be expressed in cron and are an error
+//  - Intervals must be whole minutes of at least a minute - Metronome's granularity
+func ConvertIso8601ToSchedules(isoRep string, now time.Time) ([]Schedule, error) {
+	parts := strings.Split(isoRep, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("'%s' is not an iso8601 repeating interval R<n>/<start>/<interval>", isoRep)
+	}
+	match := repeatRegex.FindStringSubmatch(parts[0])
+	if match == nil {
+		return nil, fmt.Errorf("'%s' has no repeat pattern", isoRep)
+	}
+	repeat := -1
+	if match[1] != "" {
+		val, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+		if val == 0 {
+			return nil, fmt.Errorf("'%s' repeats 0 times and never runs", isoRep)
+		}
+		repeat = val
+	}
+	start := now
+	if parts[1] != "" {
+		var err error
+		if start, err = time.Parse(time.RFC3339, parts[1]); err != nil {
+			return nil, fmt.Errorf("'%s' has an illegal start time: %s", isoRep, err)
+		}
+	}
+	start = start.UTC().Truncate(time.Minute)
+	dur, err := duration.FromString(parts[2])
+	if err != nil {
+		return nil, errors.New("Illegal duration")
+	}
+	interval := dur.ToDuration()
+	if interval < time.Minute {
+		return nil, fmt.Errorf("'%s' interval %s is below Metronome's one minute granularity", isoRep, interval)
+	} else if interval%time.Minute != 0 {
+		return nil, fmt.Errorf("'%s' interval %s is not a whole number of minutes", isoRep, interval)
+	}
+	var crons []string
+	switch {
+	case repeat > MaxOneShotSchedules:
+		return nil, fmt.Errorf("'%s' repeats %d times.  Only %d can be pinned and a cron has no repeat count", isoRep, repeat, MaxOneShotSchedules)
+	case repeat > 0:
+		for i := 0; i < repeat; i++ {
+			at := start.Add(time.Duration(i) * interval)
+			if at.Before(now) {
+				continue
+			}
+			crons = append(crons, fmt.Sprintf("%d %d %d %d * %d", at.Minute(), at.Hour(), at.Day(), int(at.Month()), at.Year()))
+		}
+		if len(crons) == 0 {
+			return nil, fmt.Errorf("'%s' has no runs left after %s", isoRep, now.Format(time.RFC3339))
+		}
+	default:
+		if first := start.Add(-interval); !first.Before(now) {
+			return nil, fmt.Errorf("'%s' starts at %s but a schedule in its phase would already fire at %s.  Create the job after %s or use a finite repeat count",
+				isoRep, start.Format(time.RFC3339), first.Format(time.RFC3339), first.Format(time.RFC3339))
+		}
+		if crons, err = repeatingCrons(start, int(interval/time.Minute)); err != nil {
+			return nil, fmt.Errorf("'%s' %s", isoRep, err)
+		}
+	}
+	scheds := make([]Schedule, 0, len(crons))
+	for i, cron := range crons {
+		scheds = append(scheds, Schedule{
+			ID:                      fmt.Sprintf("iso8601-%d", i+1),
+			Cron:                    cron,
+			ConcurrencyPolicy:       "ALLOW",
+			Enabled:                 true,
+			StartingDeadlineSeconds: 60,
+			Timezone:                "UTC",
+		})
+	}
+	return scheds, nil
+}
+
+// repeatingCrons - cron expressions firing every interval minutes in phase with start
+func repeatingCrons(start time.Time, interval int) ([]string, error) {
+	startMinute := start.Hour()*60 + start.Minute()
+	switch {
+	case interval == minutesPerDay:
+		return []string{fmt.Sprintf("%d %d * * *", start.Minute(), start.Hour())}, nil
+	case interval == 7*minutesPerDay:
+		return []string{fmt.Sprintf("%d %d * * %d", start.Minute(), start.Hour(), int(start.Weekday()))}, nil
+	case minutesPerDay%interval != 0:
+		return nil, fmt.Errorf("interval of %d minutes doesn't divide a day and can't be expressed in cron", interval)
+	}
+	// the fire times repeat daily.  Group the hours each minute fires in, then minutes sharing the same hours
+	hoursByMinute := make(map[int][]int)
+	for at := startMinute % interval; at < minutesPerDay; at += interval {
+		hoursByMinute[at%60] = append(hoursByMinute[at%60], at/60)
+	}
+	minutes := make([]int, 0, len(hoursByMinute))
+	for minute := range hoursByMinute {
+		minutes = append(minutes, minute)
+	}
+	sort.Ints(minutes)
+	var order []string
+	minutesByHours := make(map[string][]int)
+	for _, minute := range minutes {
+		hours := hourList(hoursByMinute[minute])
+		if _, ok := minutesByHours[hours]; !ok {
+			order = append(order, hours)
+		}
+		minutesByHours[hours] = append(minutesByHours[hours], minute)
+	}
+	crons := make([]string, 0, len(order))
+	for _, hours := range order {
+		crons = append(crons, fmt.Sprintf("%s %s * * *", intList(minutesByHours[hours]), hours))
+	}
+	return crons, nil
+}
+
+func hourList(hours []int) string {
+	if len(hours) == 24 {
+		return "*"
+	}
+	return intList(hours)
+}
+
+func intList(vals []int) string {
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		strs = append(strs, strconv.Itoa(v))
+	}
+	return strings.Join(strs, ",")
+}
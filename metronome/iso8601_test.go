@@ -0,0 +1,101 @@
+package metronome_test
+
+import (
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConvertIso8601ToSchedules", func() {
+	now, _ := time.Parse(time.RFC3339, "2017-06-01T12:00:00Z")
+	crons := func(iso string) []string {
+		scheds, err := ConvertIso8601ToSchedules(iso, now)
+		Expect(err).To(BeNil())
+		var out []string
+		for _, sched := range scheds {
+			Expect(sched.Validate()).To(BeNil())
+			Expect(sched.Timezone).To(Equal("UTC"))
+			out = append(out, sched.Cron)
+		}
+		return out
+	}
+
+	It("Keeps the start time's phase", func() {
+		Expect(crons("R/2017-01-01T00:10:00Z/PT30M")).To(Equal([]string{"10,40 * * * *"}))
+		Expect(crons("R/2017-01-01T03:15:00Z/PT6H")).To(Equal([]string{"15 3,9,15,21 * * *"}))
+		Expect(crons("R/2017-01-01T03:15:00Z/P1D")).To(Equal([]string{"15 3 * * *"}))
+		Expect(crons("R/2017-01-01T03:15:00Z/P1W")).To(Equal([]string{"15 3 * * 0"}))
+	})
+
+	It("Splits intervals a single cron can't represent", func() {
+		Expect(crons("R/2017-01-01T00:00:00Z/PT45M")).To(Equal([]string{
+			"0,45 0,3,6,9,12,15,18,21 * * *",
+			"15 2,5,8,11,14,17,20,23 * * *",
+			"30 1,4,7,10,13,16,19,22 * * *",
+		}))
+	})
+
+	It("Matches the fire times of the interval", func() {
+		scheds, err := ConvertIso8601ToSchedules("R/2017-01-01T00:00:00Z/PT90M", now)
+		Expect(err).To(BeNil())
+		var fires []time.Time
+		for i := range scheds {
+			next, err := scheds[i].NextRuns(now, 8)
+			Expect(err).To(BeNil())
+			fires = append(fires, next...)
+		}
+		Expect(fires).To(ContainElement(now.Add(90 * time.Minute)))
+		for _, fire := range fires {
+			Expect(fire.Sub(now) % (90 * time.Minute)).To(BeZero())
+		}
+	})
+
+	It("Pins finite repeats and drops runs already past", func() {
+		Expect(crons("R3/2017-06-01T11:00:00Z/PT30M")).To(Equal([]string{"0 12 1 6 * 2017"}))
+		Expect(crons("R2/2017-12-31T23:30:00Z/PT1H")).To(Equal([]string{"30 23 31 12 * 2017", "30 0 1 1 * 2018"}))
+	})
+
+	It("Refuses endless intervals that would fire before a future start", func() {
+		Expect(crons("R/2017-06-01T12:20:00Z/PT30M")).To(Equal([]string{"20,50 * * * *"}))
+		Expect(crons("R/2017-06-02T03:15:00Z/P1D")).To(Equal([]string{"15 3 * * *"}))
+		_, err := ConvertIso8601ToSchedules("R/2017-06-01T13:00:00Z/PT30M", now)
+		Expect(err).To(MatchError(ContainSubstring("would already fire at 2017-06-01T12:30:00Z")))
+		_, err = ConvertIso8601ToSchedules("R/2017-07-01T00:00:00Z/P1D", now)
+		Expect(err).ToNot(BeNil())
+		Expect(crons("R2/2017-07-01T00:00:00Z/P1D")).To(Equal([]string{"0 0 1 7 * 2017", "0 0 2 7 * 2017"}))
+	})
+
+	It("Defaults the start to now", func() {
+		Expect(crons("R//PT20M")).To(Equal([]string{"0,20,40 * * * *"}))
+	})
+
+	It("Explains what it can't convert", func() {
+		for _, iso := range []string{
+			"R/2017-01-01T00:00:00Z/PT30S",
+			"R/2017-01-01T00:00:00Z/PT90S",
+			"R/2017-01-01T00:00:00Z/PT7M",
+			"R/2017-01-01T00:00:00Z/P3D",
+			"R100/2017-01-01T00:00:00Z/PT1H",
+			"R0/2017-01-01T00:00:00Z/PT1H",
+			"R2/2017-01-01T00:00:00Z/PT1H",
+			"X/2017-01-01T00:00:00Z/PT1H",
+			"R/yesterday/PT1H",
+		} {
+			_, err := ConvertIso8601ToSchedules(iso, now)
+			Expect(err).ToNot(BeNil(), iso)
+		}
+		_, err := ConvertIso8601ToSchedules("R/2017-01-01T00:00:00Z/PT30S", now)
+		Expect(err.Error()).To(ContainSubstring("one minute granularity"))
+	})
+
+	It("Keeps ConvertIso8601ToCron for single expressions", func() {
+		cron, err := ConvertIso8601ToCron("R/2017-01-01T00:10:00Z/PT30M")
+		Expect(err).To(BeNil())
+		Expect(cron).To(Equal("10,40 * * * *"))
+		_, err = ConvertIso8601ToCron("R/2017-01-01T00:00:00Z/PT45M")
+		Expect(err).ToNot(BeNil())
+	})
+})
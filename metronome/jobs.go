@@ -1,13 +1,9 @@
 package metronome
 
 import (
-	"errors"
 	"fmt"
-	"strings"
 	"time"
-	duration "github.com/ChannelMeter/iso8601duration"
 	"encoding/json"
-	"regexp"
 	"strconv"
 	log "github.com/behance/go-logrus"
 	"net/http"
@@ -32,37 +28,35 @@ func (client *Client)  DeleteJob(jobID string) (interface{}, error) {
 	return msg, err
 
 }
-// GetJob - Gets a job by calling metronome api
+// GetJob - Gets a job by calling metronome api.  Embeds DefaultJobQuery
 // GET /v1/jobs/$jobId
 func (client *Client) GetJob(jobID string) (*Job, error) {
+	return client.QueryJob(jobID, DefaultJobQuery())
+}
+
+// QueryJob - Gets a job embedding only the detail selected by query.  A nil query embeds nothing
+// GET /v1/jobs/$jobId?embed=...
+func (client *Client) QueryJob(jobID string, query *JobQuery) (*Job, error) {
 	var job Job
-	queryParams := map[string][]string{
-		"embed" : {
-			"historySummary",
-			"activeRuns",
-			"schedules",
-		},
-	}
-	_, err := client.apiGet(fmt.Sprintf(MetronomeAPIJobGet, jobID), queryParams, &job)
+	_, err := client.apiGet(fmt.Sprintf(MetronomeAPIJobGet, jobID), query.queryParams(), &job)
 	if err != nil {
 		return nil, err
 	}
 	return &job, err
 
 }
-// Jobs - get a list of all jobs by calling metronome api
+// Jobs - get a list of all jobs by calling metronome api.  Embeds DefaultJobsQuery
 // GET /v1/jobs
 func (client *Client)  Jobs() (*[]Job, error) {
-	//	jobs := new(Jobs)
+	return client.QueryJobs(DefaultJobsQuery())
+}
+
+// QueryJobs - get a list of all jobs embedding only the detail selected by query.  A nil query embeds nothing
+// GET /v1/jobs?embed=...
+func (client *Client) QueryJobs(query *JobQuery) (*[]Job, error) {
 	jobs := make([]Job, 0, 0)
-	queryParams := map[string][]string{
-		"embed" : {
-			"historySummary",
-			"activeRuns",
-		},
-	}
 
-	_, err := client.apiGet(MetronomeAPIJobList, queryParams, &jobs)
+	_, err := client.apiGet(MetronomeAPIJobList, query.queryParams(), &jobs)
 
 	if err != nil {
 		return nil, err
@@ -89,21 +83,17 @@ func (client *Client) UpdateJob(jobID string, job *Job) (interface{}, error) {
 
 // Runs - get all the 'runs' of a given job
 // GET /v1/jobs/$jobId/runs
+//
+// Deprecated: returns the whole job and sends an unused `_timestamp` parameter.  Use ActiveRuns and RunHistory
 func (client *Client) Runs(jobID string, since int64) (*Job, error) {
 	//jobs := make([]JobStatus, 0, 0)
 	//jobs := make([]Job, 0, 0)
 	var jobs Job
-	queryParams := map[string][]string{
-		"_timestamp": {
-			strconv.FormatInt(since , 10),
-//			strconv.FormatInt(time.Now().UnixNano() / int64(time.Millisecond) - 24 * 3600000, 10),
-		},
-		"embed" : {
-			"history",
-			"historySummary",
-			"activeRuns",
-			"schedules",
-		},
+	query := JobQuery{Embed: []Embed{EmbedHistory, EmbedHistorySummary, EmbedActiveRuns, EmbedSchedules}}
+	queryParams := query.queryParams()
+	queryParams["_timestamp"] = []string{
+		strconv.FormatInt(since , 10),
+//		strconv.FormatInt(time.Now().UnixNano() / int64(time.Millisecond) - 24 * 3600000, 10),
 	}
 	// lame hidden parameters are only reachable via /v1/jobs/$jobId with queryParams
 	_, err := client.apiGet(fmt.Sprintf(MetronomeAPIJobGet, jobID), queryParams, &jobs)
@@ -113,15 +103,39 @@ func (client *Client) Runs(jobID string, since int64) (*Job, error) {
 	return &jobs, nil
 }
 // RunLs  - list running jobs - standard
+//
+// Deprecated: use ActiveRuns
 func (client *Client) RunLs(jobID string) (*[]JobStatus, error) {
-	jobs := make([]JobStatus, 0, 0)
+	return client.ActiveRuns(jobID, nil)
+}
 
-	_, err := client.apiGet(fmt.Sprintf(MetronomeAPIJobRunList, jobID), nil, &jobs)
+// ActiveRuns - list the runs of a job that haven't finished.  A nil filter returns every run
+// GET /v1/jobs/$jobId/runs
+func (client *Client) ActiveRuns(jobID string, filter *RunFilter) (*[]JobStatus, error) {
+	runs := make([]JobStatus, 0, 0)
+
+	_, err := client.apiGet(fmt.Sprintf(MetronomeAPIJobRunList, jobID), nil, &runs)
 
 	if err != nil {
 		return nil, err
 	}
-	return &jobs, nil
+	runs = filter.FilterActive(runs)
+	return &runs, nil
+}
+
+// RunHistory - list the finished runs of a job, newest first, with Status set to SUCCESS or FAILED.
+// A nil filter returns every run Metronome still remembers
+// GET /v1/jobs/$jobId?embed=history
+func (client *Client) RunHistory(jobID string, filter *RunFilter) (*[]HistoryStatus, error) {
+	job, err := client.QueryJob(jobID, &JobQuery{Embed: []Embed{EmbedHistory}})
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]HistoryStatus, 0, 0)
+	if job.History != nil {
+		runs = filter.FilterHistory(job.History.Runs())
+	}
+	return &runs, nil
 }
 // StartJob - starts a metronome job.  Implies that CreateJob was already called.
 // POST /v1/jobs/$jobId/runs
@@ -269,75 +283,6 @@ func formatTimeString(t time.Time) string {
 }
 
 
-///
-var (
-	repeatRegex = regexp.MustCompile(`R((?P<repeat>\d*))`)
-)
-
-// ConvertIso8601ToCron - attempts to convert an iso8601, 3 -part date into a cron repre.  Experimental
-//  - only simple cases will work without creating *multiple* schedules
-//
-func ConvertIso8601ToCron(isoRep string) (string, error) {
-	pat := strings.Split(isoRep, "/")
-	if len(pat) == 3 {
-		interval := pat[2]
-		dur := pat[0]
-		repeatTimes := 0
-		if repeatRegex.MatchString(dur) {
-			match := repeatRegex.FindStringSubmatch(dur)
-			for i, name := range repeatRegex.SubexpNames() {
-				part := match[i]
-				if i == 0 || name == "" || part == "" {
-					continue
-				}
-				val, err := strconv.Atoi(part)
-				if err != nil {
-					return "", err
-				}
-				switch name {
-				case "repeat":
-					repeatTimes = val
-				default:
-					return "", fmt.Errorf("unknown field %s", name)
-				}
-			}
-		} else {
-			return "", fmt.Errorf("No repeat pattern")
-
-		}
-		tdur, err := duration.FromString(interval)
-
-		if err != nil {
-			return "", errors.New("Illegal duration")
-		}
-		timeT := tdur.ToDuration()
-		if repeatTimes != 0 {
-			// minute is the smallest scheduling unit for metronome
-			slot := int64(timeT)
-			if slot < 1 {
-				return "", errors.New("Too small a duration")
-			} else if slot < 60 {
-
-			}
-
-		} else {
-
-		}
-
-	} else {
-		var (
-			y, M, d, h, m, s int
-		)
-		_, err := fmt.Sscanf(time.Now().Format(time.RFC3339), "%d-%d-%dT%d:%d:%dZ", &y, &M, &d, &h, &m, &s)
-		if err != nil {
-			return "", err
-		}
-		return fmt.Sprintf("%d %d %d %d * %d", m, h, d, M, y), nil
-
-	}
-
-	return "", errors.New("Unknown error")
-}
 // ImmediateCrontab - generates a point in time crontab
 func ImmediateCrontab() string {
 	var (
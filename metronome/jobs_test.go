@@ -406,4 +406,105 @@ var _ = Describe("Jobs", func() {
 				})
 		*/
 	})
+	Describe("QueryJob", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar", "embed=history&embed=schedules"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, allJobs[0]),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs", ""),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, allJobs),
+				),
+			)
+		})
+
+		It("Embeds only the requested detail", func() {
+			_, err := client.QueryJob("foo.bar", &JobQuery{Embed: []Embed{EmbedHistory, EmbedSchedules}})
+			Expect(err).ShouldNot(HaveOccurred())
+			jobs, err := client.QueryJobs(nil)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(*jobs).To(HaveLen(2))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Rejects unknown embed values", func() {
+			_, err := ParseEmbed("tasks")
+			Expect(err).To(HaveOccurred())
+			embed, err := ParseEmbed("activeRuns")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(embed).To(Equal(EmbedActiveRuns))
+		})
+	})
+	Describe("Runs", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar/runs"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []JobStatus{status}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar", "embed=history"),
+					ghttp.RespondWith(http.StatusOK, hiddenJobApi, http.Header{
+						"Content-Type":   []string{"application/json"},
+						"Content-Length": []string{strconv.Itoa(len(hiddenJobApi))},
+					}),
+				),
+			)
+		})
+
+		It("Lists active runs filtered by status", func() {
+			runs, err := client.ActiveRuns("foo.bar", &RunFilter{Status: []RunStatus{RunActive}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(*runs).To(BeEmpty())
+		})
+
+		It("Merges finished runs newest first and filters by window and outcome", func() {
+			client.ActiveRuns("foo.bar", nil)
+			runs, err := client.RunHistory("foo.bar", &RunFilter{
+				Since: time.Date(2016, 12, 12, 17, 30, 0, 0, time.UTC),
+				Until: time.Date(2016, 12, 12, 17, 40, 0, 0, time.UTC),
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(*runs).To(HaveLen(5))
+			Expect((*runs)[0].ID).To(Equal("201612121739593nv1S"))
+			Expect((*runs)[2].ID).To(Equal("20161212173559asQpr"))
+			Expect((*runs)[2].Status).To(Equal(RunFailed))
+			Expect((*runs)[4].Status).To(Equal(RunSuccess))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+
+		It("Rejects unknown run statuses", func() {
+			_, err := ParseRunStatus("FAILD")
+			Expect(err).To(MatchError(ContainSubstring("INITIAL,STARTING,ACTIVE,SUCCESS,FAILED")))
+			status, err := ParseRunStatus("FAILED")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(status).To(Equal(RunFailed))
+		})
+	})
+	Describe("RunOutcome", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar/runs/20161212173559asQpr"),
+					ghttp.RespondWithJSONEncoded(http.StatusNotFound, map[string]string{"message": "not found"}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/jobs/foo.bar", "embed=history"),
+					ghttp.RespondWith(http.StatusOK, hiddenJobApi, http.Header{
+						"Content-Type":   []string{"application/json"},
+						"Content-Length": []string{strconv.Itoa(len(hiddenJobApi))},
+					}),
+				),
+			)
+		})
+
+		It("Looks for finished runs in the history", func() {
+			outcome, err := RunOutcome(client, "foo.bar", "20161212173559asQpr")
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(outcome).To(Equal(RunFailed))
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
 })
@@ -0,0 +1,65 @@
+package jobspec
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Config - where a spec file's values and overlays come from
+type Config struct {
+	// ValueFiles - yaml or json values files.  Later files win
+	ValueFiles []string
+	// Sets - key=value overrides applied after the files
+	Sets []string
+	// Environment - the environment whose values are merged over the rest.  Optional
+	Environment string
+	// Overlays - files merged into every rendered spec, in order
+	Overlays []string
+}
+
+// Values - the values the config selects
+func (config Config) Values() (Values, error) {
+	values := Values{}
+	for _, file := range config.ValueFiles {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileValues, err := LoadValues(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", file, err)
+		}
+		values.Merge(fileValues)
+	}
+	values, err := values.ForEnvironment(config.Environment)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range config.Sets {
+		if err := values.Set(set); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// RenderFile - the jobs the spec file renders to with the config's values and overlays
+func (config Config) RenderFile(file string) ([]met.Job, error) {
+	values, err := config.Values()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	overlays := make([][]byte, len(config.Overlays))
+	for i, overlay := range config.Overlays {
+		if overlays[i], err = ioutil.ReadFile(overlay); err != nil {
+			return nil, err
+		}
+	}
+	return RenderJobs(file, spec, values, overlays...)
+}
@@ -0,0 +1,13 @@
+package jobspec_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestJobspec(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Jobspec Suite")
+}
@@ -0,0 +1,267 @@
+package jobspec_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/adobe-platform/go-metronome/metronome/jobspec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const spec = `
+id: {{ .name }}
+description: {{ .name }} in {{ lookup "environment" | default "no environment" }}
+labels:
+  owner: {{ required "owner is required" .owner }}
+run:
+  cpus: {{ .cpus }}
+  mem: {{ .mem }}
+  disk: 0
+  maxLaunchDelay: 3600
+  docker:
+    image: {{ .image.repo }}:{{ lookup "image.tag" | default "latest" }}
+schedules:
+- id: nightly
+  cron: {{ quote .cron }}
+  concurrencyPolicy: FORBID
+  startingDeadlineSeconds: 60
+`
+
+const values = `
+name: reports
+owner: data
+cpus: 0.5
+mem: 256
+cron: "0 2 * * *"
+image:
+  repo: myorg/reports
+environments:
+  dev:
+  prod:
+    mem: 1024
+    image:
+      tag: "1.4.2"
+`
+
+func lookup(values Values, path string) interface{} {
+	value, ok := values.Lookup(path)
+	Expect(ok).To(BeTrue(), path)
+	return value
+}
+
+var _ = Describe("Values", func() {
+	var base Values
+	BeforeEach(func() {
+		var err error
+		base, err = LoadValues([]byte(values))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("Loads nested values", func() {
+		Expect(lookup(base, "image.repo")).To(Equal("myorg/reports"))
+		_, ok := base.Lookup("image.tag")
+		Expect(ok).To(BeFalse())
+	})
+	It("Lists environments", func() {
+		Expect(base.Environments()).To(Equal([]string{"dev", "prod"}))
+	})
+	It("Merges an environment over the rest", func() {
+		prod, err := base.ForEnvironment("prod")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(prod["mem"]).To(Equal(1024))
+		Expect(lookup(prod, "image.tag")).To(Equal("1.4.2"))
+		Expect(lookup(prod, "image.repo")).To(Equal("myorg/reports"))
+		Expect(prod[EnvironmentKey]).To(Equal("prod"))
+		Expect(prod).ToNot(HaveKey(EnvironmentsKey))
+		Expect(base["mem"]).To(Equal(256))
+		_, ok := base.Lookup("image.tag")
+		Expect(ok).To(BeFalse())
+	})
+	It("Allows an empty environment", func() {
+		dev, err := base.ForEnvironment("dev")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(dev["mem"]).To(Equal(256))
+		Expect(dev[EnvironmentKey]).To(Equal("dev"))
+	})
+	It("Rejects unknown environments", func() {
+		_, err := base.ForEnvironment("qa")
+		Expect(err).To(MatchError(ContainSubstring("Known: dev,prod")))
+	})
+	It("Sets nested typed values", func() {
+		Expect(base.Set("image.tag=1.5.0")).To(Succeed())
+		Expect(base.Set("cpus=2")).To(Succeed())
+		Expect(base.Set("debug=true")).To(Succeed())
+		Expect(base.Set(`version="1.10"`)).To(Succeed())
+		Expect(base.Set("note=")).To(Succeed())
+		Expect(base.Set("a.b.c=x")).To(Succeed())
+		Expect(lookup(base, "image.tag")).To(Equal("1.5.0"))
+		Expect(lookup(base, "image.repo")).To(Equal("myorg/reports"))
+		Expect(base["cpus"]).To(Equal(2))
+		Expect(base["debug"]).To(Equal(true))
+		Expect(base["version"]).To(Equal("1.10"))
+		Expect(base["note"]).To(Equal(""))
+		Expect(lookup(base, "a.b.c")).To(Equal("x"))
+	})
+	It("Rejects malformed overrides", func() {
+		Expect(base.Set("cpus")).ToNot(Succeed())
+		Expect(base.Set("=2")).ToNot(Succeed())
+		Expect(base.Set("image..tag=2")).ToNot(Succeed())
+	})
+	It("Rejects values that aren't an object", func() {
+		_, err := LoadValues([]byte("- a\n- b\n"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Render", func() {
+	var base Values
+	BeforeEach(func() {
+		var err error
+		base, err = LoadValues([]byte(values))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("Renders jobs and schedules", func() {
+		jobs, err := RenderJobs("spec", []byte(spec), base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].ID).To(Equal("reports"))
+		Expect(jobs[0].Description).To(Equal("reports in no environment"))
+		Expect(jobs[0].Run.Mem).To(Equal(256))
+		Expect(jobs[0].Run.Docker.Image).To(Equal("myorg/reports:latest"))
+		Expect(jobs[0].Schedules).To(HaveLen(1))
+		Expect(jobs[0].Schedules[0].Cron).To(Equal("0 2 * * *"))
+		Expect(jobs[0].Validate()).To(Succeed())
+	})
+	It("Renders an environment", func() {
+		prod, err := base.ForEnvironment("prod")
+		Expect(err).ToNot(HaveOccurred())
+		jobs, err := RenderJobs("spec", []byte(spec), prod)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs[0].Description).To(Equal("reports in prod"))
+		Expect(jobs[0].Run.Mem).To(Equal(1024))
+		Expect(jobs[0].Run.Docker.Image).To(Equal("myorg/reports:1.4.2"))
+	})
+	It("Renders numbers and booleans in env and labels as strings", func() {
+		base["workers"] = 4
+		jobs, err := RenderJobs("spec", []byte(`
+id: {{ .name }}
+labels:
+  replicas: {{ .workers }}
+run:
+  cpus: 1
+  mem: 32
+  env:
+    WORKERS: {{ .workers }}
+    RATIO: 0.25
+    VERSION: 1.10
+    MODE: 0755
+    DEBUG: true
+    DB_PASSWORD: {secret: db}
+`), base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect((*jobs[0].Labels)["replicas"]).To(Equal("4"))
+		Expect(jobs[0].Run.Env).To(Equal(map[string]string{"WORKERS": "4", "RATIO": "0.25", "VERSION": "1.10", "MODE": "0755", "DEBUG": "true"}))
+		Expect(jobs[0].Run.EnvSecrets).To(Equal(map[string]string{"DB_PASSWORD": "db"}))
+		jobs, err = RenderJobs("spec", []byte(`{"id": "{{ .name }}", "run": {"cpus": 1, "mem": 32, "env": {"WORKERS": {{ .workers }}, "VERSION": 1.10, "DEBUG": false}}}`), base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs[0].Run.Env).To(Equal(map[string]string{"WORKERS": "4", "VERSION": "1.10", "DEBUG": "false"}))
+	})
+	It("Renders specs that leave Metronome's defaults out", func() {
+		jobs, err := RenderJobs("spec", []byte(`
+id: {{ .name }}
+run:
+  cpus: 0.1
+  mem: 64
+  docker:
+    image: busybox
+schedules:
+- id: nightly
+  cron: {{ quote .cron }}
+`), base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs[0].Validate()).To(Succeed())
+	})
+	It("Fails on values that aren't set", func() {
+		delete(base, "cpus")
+		_, err := RenderJobs("spec", []byte(spec), base)
+		Expect(err).To(MatchError(ContainSubstring("cpus")))
+	})
+	It("Fails on required values that are empty", func() {
+		base["owner"] = ""
+		_, err := RenderJobs("spec", []byte(spec), base)
+		Expect(err).To(MatchError(ContainSubstring("owner is required")))
+	})
+	It("Renders json specs and lists of jobs", func() {
+		jobs, err := RenderJobs("spec", []byte(`[{"id": "{{ .name }}-a", "run": {"cpus": {{ .cpus }}, "mem": 32, "disk": 0}},
+			{"id": "{{ .name }}-b", "run": {"cpus": 1, "mem": 32, "disk": 0}}]`), base)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+		Expect(jobs[0].ID).To(Equal("reports-a"))
+		Expect(jobs[0].Run.Cpus).To(Equal(0.5))
+		Expect(jobs[1].ID).To(Equal("reports-b"))
+	})
+	It("Merges overlays into every job or the job with the overlay's id", func() {
+		specs := spec + "---\nid: cleanup\nrun:\n  cpus: 0.1\n  mem: 32\n  disk: 0\n  env:\n    MODE: fast\n"
+		all := "run:\n  mem: {{ .mem }}\n  maxLaunchDelay: 600\n  restart:\n    policy: ON_FAILURE\n"
+		one := "id: cleanup\nrun:\n  env:\n    MODE: ~\n    DRY_RUN: \"true\"\n---\nid: reports\nschedules:\n- id: hourly\n  cron: \"0 * * * *\"\n"
+		base["mem"] = 2048
+		jobs, err := RenderJobs("spec", []byte(specs), base, []byte(all), []byte(one))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(2))
+		for _, job := range jobs {
+			Expect(job.Run.Mem).To(Equal(2048))
+			Expect(job.Run.MaxLaunchDelay).To(Equal(600))
+			Expect(job.Run.Restart.Policy).To(Equal("ON_FAILURE"))
+		}
+		Expect(jobs[0].Run.Cpus).To(Equal(0.5))
+		Expect(jobs[0].Run.Docker.Image).To(Equal("myorg/reports:latest"))
+		Expect(jobs[0].Schedules).To(HaveLen(1))
+		Expect(jobs[0].Schedules[0].ID).To(Equal("hourly"))
+		Expect(jobs[1].Run.Cpus).To(Equal(0.1))
+		Expect(jobs[1].Run.Env).To(Equal(map[string]string{"DRY_RUN": "true"}))
+	})
+	It("Fails on overlays for unknown jobs", func() {
+		_, err := RenderJobs("spec", []byte(spec), base, []byte("id: nope\nrun:\n  mem: 64\n"))
+		Expect(err).To(MatchError(ContainSubstring("no job with id nope")))
+	})
+})
+
+var _ = Describe("Config", func() {
+	var dir string
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "jobspec")
+		Expect(err).ToNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+	It("Renders a spec file with values files, an environment, overrides and overlays", func() {
+		config := Config{
+			ValueFiles:  []string{write("values.yaml", values), write("team.json", `{"owner": "analytics", "cpus": 1}`)},
+			Sets:        []string{"image.tag=1.5.0"},
+			Environment: "prod",
+			Overlays:    []string{write("prod.yaml", "run:\n  maxLaunchDelay: 600\n")},
+		}
+		jobs, err := config.RenderFile(write("spec.yaml", spec))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect((*jobs[0].Labels)["owner"]).To(Equal("analytics"))
+		Expect(jobs[0].Run.Cpus).To(Equal(1.0))
+		Expect(jobs[0].Run.Mem).To(Equal(1024))
+		Expect(jobs[0].Run.Docker.Image).To(Equal("myorg/reports:1.5.0"))
+		Expect(jobs[0].Run.MaxLaunchDelay).To(Equal(600))
+	})
+	It("Reports the file with bad values", func() {
+		config := Config{ValueFiles: []string{write("values.yaml", "- a\n")}}
+		_, err := config.Values()
+		Expect(err).To(MatchError(ContainSubstring("values.yaml")))
+	})
+})
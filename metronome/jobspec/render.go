@@ -0,0 +1,220 @@
+package jobspec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"text/template"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Render - execute the spec template with values.  Referencing a value that isn't set is an error; use
+// lookup for optional values e.g. {{ lookup "image.tag" | default "latest" }}.  name identifies the spec in errors
+func Render(name string, spec []byte, values Values) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs(values)).Parse(string(spec))
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderJobs - render the spec and each overlay with values, merge the overlays into the rendered jobs and decode
+// them.  An overlay document with an id applies to that job, one without to every job.  Overlays merge objects key
+// by key, replace lists (schedules included) and remove keys they set to null
+func RenderJobs(name string, spec []byte, values Values, overlays ...[]byte) ([]met.Job, error) {
+	rendered, err := Render(name, spec, values)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := documents(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	for i, overlay := range overlays {
+		overlayName := fmt.Sprintf("%s overlay %d", name, i+1)
+		rendered, err := Render(overlayName, overlay, values)
+		if err != nil {
+			return nil, err
+		}
+		patches, err := documents(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", overlayName, err)
+		}
+		for j, patch := range patches {
+			if err := apply(jobs, patch); err != nil {
+				return nil, fmt.Errorf("%s, document %d: %s", overlayName, j+1, err)
+			}
+		}
+	}
+	out := make([]met.Job, len(jobs))
+	for i, job := range jobs {
+		stringValues(job)
+		b, err := json.Marshal(job)
+		if err != nil {
+			return nil, fmt.Errorf("%s: job %d: %s", name, i+1, err)
+		}
+		if err := json.Unmarshal(b, &out[i]); err != nil {
+			return nil, fmt.Errorf("%s: job %d: %s", name, i+1, err)
+		}
+	}
+	return out, nil
+}
+
+// stringValues - the job's labels and run env with json numbers and booleans as the strings they were written as, so
+// templated values such as "WORKERS": {{ .workers }} needn't be quoted.  yaml specs already have them as written, see
+// met.DecodeYAMLJobs
+func stringValues(job Values) {
+	if labels, ok := job["labels"].(Values); ok {
+		writtenValues(labels)
+	}
+	if run, ok := job["run"].(Values); ok {
+		if env, ok := run["env"].(Values); ok {
+			writtenValues(env)
+		}
+	}
+}
+
+func writtenValues(object Values) {
+	for key, value := range object {
+		switch value := value.(type) {
+		case json.Number:
+			object[key] = value.String()
+		case bool:
+			object[key] = strconv.FormatBool(value)
+		}
+	}
+}
+
+// documents - the objects of a rendered json or yaml file.  Lists contribute each of their objects.  json numbers
+// are kept as json.Number, so they are written back unchanged
+func documents(data []byte) ([]Values, error) {
+	var raws []interface{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		var raw interface{}
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		} else if decoder.More() {
+			return nil, errors.New("unexpected data after the json")
+		}
+		raws = append(raws, raw)
+	} else {
+		for i, doc := range met.SplitYAMLDocuments(data) {
+			raw, err := met.DecodeYAMLJobs(doc)
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %s", i+1, err)
+			}
+			raws = append(raws, raw)
+		}
+	}
+	var objects []Values
+	for _, raw := range raws {
+		value, err := plain(raw)
+		if err != nil {
+			return nil, err
+		}
+		list, isList := value.([]interface{})
+		if !isList {
+			list = []interface{}{value}
+		}
+		for _, value := range list {
+			if value == nil {
+				continue
+			}
+			object, ok := value.(Values)
+			if !ok {
+				return nil, fmt.Errorf("a job must be an object not %T", value)
+			}
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
+
+// apply - merge patch into the job with its id or, without one, into every job
+func apply(jobs []Values, patch Values) error {
+	id, targeted := patch["id"]
+	applied := false
+	for _, job := range jobs {
+		if targeted && !reflect.DeepEqual(job["id"], id) {
+			continue
+		}
+		overlay(job, patch)
+		applied = true
+	}
+	if targeted && !applied {
+		return fmt.Errorf("no job with id %v", id)
+	}
+	return nil
+}
+
+func overlay(into Values, patch Values) {
+	for key, value := range patch {
+		if value == nil {
+			delete(into, key)
+			continue
+		}
+		if from, ok := value.(Values); ok {
+			if to, ok := into[key].(Values); ok {
+				overlay(to, from)
+				continue
+			}
+			value = copyValues(from)
+		}
+		into[key] = value
+	}
+}
+
+// funcs - the functions specs can call besides text/template's own
+func funcs(values Values) template.FuncMap {
+	return template.FuncMap{
+		"default": func(fallback interface{}, value interface{}) interface{} {
+			if empty(value) {
+				return fallback
+			}
+			return value
+		},
+		"required": func(message string, value interface{}) (interface{}, error) {
+			if empty(value) {
+				return nil, errors.New(message)
+			}
+			return value, nil
+		},
+		"lookup": func(path string) interface{} {
+			value, _ := values.Lookup(path)
+			return value
+		},
+		"quote": func(value interface{}) (string, error) {
+			b, err := json.Marshal(fmt.Sprint(value))
+			return string(b), err
+		},
+		"toJson": func(value interface{}) (string, error) {
+			b, err := json.Marshal(value)
+			return string(b), err
+		},
+	}
+}
+
+// empty - nil, false, zero, "" and empty lists and objects
+func empty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
@@ -0,0 +1,194 @@
+// Package jobspec renders templated job definition files into Metronome jobs.
+//
+// A spec file is a job definition (json or a yaml stream, see metronome.LoadJobs) written as a Go text/template.
+// Values come from values files, later files winning, and `key=value` overrides.  A values file can hold
+// per-environment values under `environments`; selecting an environment merges them over the rest.  Overlays
+// are partial job definitions merged into the rendered jobs, e.g. a prod overlay raising mem for every job.
+package jobspec
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"gopkg.in/yaml.v2"
+)
+
+// EnvironmentsKey - the values file section holding per-environment values
+const EnvironmentsKey = "environments"
+
+// EnvironmentKey - the value set to the selected environment's name
+const EnvironmentKey = "environment"
+
+// Values - template variables.  Nested objects are Values too
+type Values map[string]interface{}
+
+// LoadValues - values from a yaml or json document.  An empty document has no values
+func LoadValues(data []byte) (Values, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return Values{}, nil
+	}
+	value, err := plain(raw)
+	if err != nil {
+		return nil, err
+	}
+	values, ok := value.(Values)
+	if !ok {
+		return nil, fmt.Errorf("values must be an object not %T", value)
+	}
+	return values, nil
+}
+
+// Set - apply a `key=value` override.  Dots in key address nested values e.g. image.tag=1.4.2.  The value is
+// read as a yaml scalar so numbers and booleans keep their type; quote it to force a string e.g. tag='"1.10"'
+func (values Values) Set(assignment string) error {
+	eq := strings.Index(assignment, "=")
+	if eq <= 0 {
+		return fmt.Errorf("'%s' must be key=value", assignment)
+	}
+	path := strings.Split(assignment[:eq], ".")
+	for _, key := range path {
+		if key == "" {
+			return fmt.Errorf("'%s' has an empty key", assignment)
+		}
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(assignment[eq+1:]), &value); err != nil || value == nil {
+		value = assignment[eq+1:]
+	}
+	value, err := plain(value)
+	if err != nil {
+		return err
+	}
+	object := values
+	for _, key := range path[:len(path)-1] {
+		next, ok := object[key].(Values)
+		if !ok {
+			next = Values{}
+			object[key] = next
+		}
+		object = next
+	}
+	object[path[len(path)-1]] = value
+	return nil
+}
+
+// Merge - deep merge other into values.  Objects merge key by key; anything else in other replaces the value
+func (values Values) Merge(other Values) Values {
+	for key, value := range other {
+		if from, ok := value.(Values); ok {
+			if into, ok := values[key].(Values); ok {
+				into.Merge(from)
+				continue
+			}
+			value = copyValues(from)
+		}
+		values[key] = value
+	}
+	return values
+}
+
+// Environments - the names of the environments the values define, sorted
+func (values Values) Environments() []string {
+	envs, _ := values[EnvironmentsKey].(Values)
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForEnvironment - the values with the environment's section merged over them and `environment` set to name.
+// The environments section itself is left out.  An empty name selects no environment
+func (values Values) ForEnvironment(name string) (Values, error) {
+	out := copyValues(values)
+	delete(out, EnvironmentsKey)
+	if name == "" {
+		return out, nil
+	}
+	envs, _ := values[EnvironmentsKey].(Values)
+	env, ok := envs[name]
+	if !ok {
+		return nil, fmt.Errorf("environment %s not defined.  Known: %s", name, strings.Join(values.Environments(), ","))
+	}
+	if env != nil {
+		overrides, ok := env.(Values)
+		if !ok {
+			return nil, fmt.Errorf("environment %s must be an object", name)
+		}
+		out.Merge(overrides)
+	}
+	out[EnvironmentKey] = name
+	return out, nil
+}
+
+// Lookup - the value at a dotted path and whether it is set
+func (values Values) Lookup(path string) (interface{}, bool) {
+	var value interface{} = values
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(Values)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func copyValues(values Values) Values {
+	out := make(Values, len(values))
+	for key, value := range values {
+		switch value := value.(type) {
+		case Values:
+			out[key] = copyValues(value)
+		case []interface{}:
+			list := make([]interface{}, len(value))
+			for i, v := range value {
+				if object, ok := v.(Values); ok {
+					v = copyValues(object)
+				}
+				list[i] = v
+			}
+			out[key] = list
+		default:
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// plain - yaml's and json's objects as Values.  See met.JSONValue
+func plain(value interface{}) (interface{}, error) {
+	value, err := met.JSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return asValues(value), nil
+}
+
+// asValues - json's map[string]interface{} objects, at any depth, as Values
+func asValues(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		object := make(Values, len(value))
+		for key, v := range value {
+			object[key] = asValues(v)
+		}
+		return object
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = asValues(v)
+		}
+		return list
+	}
+	return value
+}
@@ -1,7 +1,7 @@
 package metronome_test
 
 import (
-	//"time"
+	"time"
 
 	. "github.com/adobe-platform/go-metronome/metronome"
 
@@ -88,4 +88,34 @@ var _ = Describe("JSON rendering", func() {
 
 	})
 
+	It("Parses run timestamps and statuses and round trips them", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(hiddenJobApi), &job)).To(Succeed())
+		Expect(job.History.LastSuccessAt.Equal(time.Date(2016, 12, 12, 18, 2, 0, 251000000, time.UTC))).To(BeTrue())
+		Expect(job.ActiveRuns[0].Status).To(Equal(RunInitial))
+		Expect(job.ActiveRuns[0].CompletedAt.IsZero()).To(BeTrue())
+		Expect(job.History.SuccessfulFinishedRuns[0].Duration()).To(Equal(916 * time.Millisecond))
+
+		var original, roundTripped map[string]interface{}
+		Expect(json.Unmarshal([]byte(hiddenJobApi), &original)).To(Succeed())
+		encoded, err := json.Marshal(struct {
+			ActiveRuns []*ActiveRun `json:"activeRuns"`
+			History    *History     `json:"history"`
+		}{job.ActiveRuns, job.History})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(json.Unmarshal(encoded, &roundTripped)).To(Succeed())
+		Expect(roundTripped["activeRuns"]).To(Equal(original["activeRuns"]))
+		Expect(roundTripped["history"]).To(Equal(original["history"]))
+	})
+	It("Tolerates empty and null timestamps", func() {
+		var status JobStatus
+		Expect(json.Unmarshal([]byte(`{"id":"1","createdAt":"","completedAt":null,"status":"FAILED","tasks":[{"id":"t","startedAt":null,"status":"TASK_KILLED"}]}`), &status)).To(Succeed())
+		Expect(status.CreatedAt.IsZero()).To(BeTrue())
+		Expect(status.Duration()).To(Equal(time.Duration(0)))
+		Expect(status.Status.IsTerminal()).To(BeTrue())
+		Expect(status.Status.IsSuccess()).To(BeFalse())
+		Expect(status.Tasks[0].Status.IsTerminal()).To(BeTrue())
+		Expect(TaskRunning.IsTerminal()).To(BeFalse())
+	})
+
 })
@@ -0,0 +1,265 @@
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultBackoffLimit - Kubernetes' default, used for ON_FAILURE jobs which Metronome retries until their deadline
+const DefaultBackoffLimit = 6
+
+// DescriptionAnnotation - carries the Metronome job description
+const DescriptionAnnotation = "metronome.dcos.io/description"
+
+// Export - CronJobs for a Metronome job and what didn't translate
+type Export struct {
+	CronJobs []CronJob `json:"cronJobs"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+func (export *Export) warn(format string, args ...interface{}) {
+	export.Warnings = append(export.Warnings, fmt.Sprintf(format, args...))
+}
+
+// YAML - the CronJobs as a multi-document yaml stream
+func (export *Export) YAML() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	for i := range export.CronJobs {
+		doc, err := yaml.Marshal(&export.CronJobs[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString("---\n")
+		buf.Write(doc)
+	}
+	return buf.Bytes(), nil
+}
+
+// FromJob - one CronJob per schedule.  schedules default to the job's embedded schedules.
+// A job without schedules becomes a single suspended CronJob so it can still be triggered by hand
+func FromJob(job *met.Job, schedules []met.Schedule) (*Export, error) {
+	if job.Run == nil {
+		return nil, fmt.Errorf("job %s has no run", job.ID)
+	}
+	if schedules == nil {
+		for _, sched := range job.Schedules {
+			if sched != nil {
+				schedules = append(schedules, *sched)
+			}
+		}
+	}
+	export := &Export{}
+	template := export.jobTemplate(job)
+	if len(schedules) == 0 {
+		export.warn("job %s has no schedules; exported suspended with a placeholder schedule", job.ID)
+		suspend := true
+		cronJob := newCronJob(job, resourceName(job.ID), template)
+		cronJob.Spec.Schedule = "@yearly"
+		cronJob.Spec.Suspend = &suspend
+		export.CronJobs = append(export.CronJobs, cronJob)
+		return export, nil
+	}
+	for i := range schedules {
+		sched := &schedules[i]
+		name := resourceName(job.ID)
+		if len(schedules) > 1 {
+			name = resourceName(job.ID + "-" + sched.ID)
+		}
+		cronJob := newCronJob(job, name, template)
+		if err := export.schedule(&cronJob.Spec, sched); err != nil {
+			return nil, err
+		}
+		export.CronJobs = append(export.CronJobs, cronJob)
+	}
+	return export, nil
+}
+
+func newCronJob(job *met.Job, name string, template JobTemplateSpec) CronJob {
+	cronJob := CronJob{
+		APIVersion: APIVersion,
+		Kind:       Kind,
+		Metadata:   ObjectMeta{Name: name},
+		Spec:       CronJobSpec{JobTemplate: template},
+	}
+	if job.Labels != nil && len(*job.Labels) > 0 {
+		cronJob.Metadata.Labels = make(map[string]string, len(*job.Labels))
+		for k, v := range *job.Labels {
+			cronJob.Metadata.Labels[k] = v
+		}
+	}
+	if job.Description != "" {
+		cronJob.Metadata.Annotations = map[string]string{DescriptionAnnotation: job.Description}
+	}
+	return cronJob
+}
+
+// schedule - cron, timezone, concurrency and deadline
+func (export *Export) schedule(spec *CronJobSpec, sched *met.Schedule) error {
+	expr, err := cron.Parse(sched.Cron)
+	if err != nil {
+		return fmt.Errorf("schedule %s: %s", sched.ID, err)
+	}
+	spec.Schedule = expr.String()
+	if !expr.Year.Any {
+		fields := strings.Fields(expr.String())
+		spec.Schedule = strings.Join(fields[:5], " ")
+		export.warn("schedule %s: Kubernetes has no year field; '%s' was dropped", sched.ID, fields[5])
+	}
+	spec.TimeZone = sched.Timezone
+	switch sched.ConcurrencyPolicy {
+	case "ALLOW":
+		spec.ConcurrencyPolicy = "Allow"
+	case "FORBID":
+		spec.ConcurrencyPolicy = "Forbid"
+	case "REPLACE":
+		spec.ConcurrencyPolicy = "Replace"
+	default:
+		export.warn("schedule %s: unknown concurrency policy '%s'", sched.ID, sched.ConcurrencyPolicy)
+	}
+	if sched.StartingDeadlineSeconds > 0 {
+		deadline := int64(sched.StartingDeadlineSeconds)
+		spec.StartingDeadlineSeconds = &deadline
+	}
+	if !sched.Enabled {
+		suspend := true
+		spec.Suspend = &suspend
+	}
+	return nil
+}
+
+// jobTemplate - the pod and retry settings shared by every schedule's CronJob
+func (export *Export) jobTemplate(job *met.Job) JobTemplateSpec {
+	run := job.Run
+	container := Container{
+		Name:      containerName(job.ID),
+		Args:      run.Args,
+		Resources: resources(run),
+	}
+	switch {
+	case run.Docker != nil:
+		container.Image = run.Docker.Image
+		if run.Docker.ForcePullImage {
+			container.ImagePullPolicy = "Always"
+		}
+		for _, param := range run.Docker.Parameters {
+			export.warn("docker parameter %s=%s not exported", param.Key, param.Value)
+		}
+		if run.Docker.Privileged {
+			export.warn("privileged not exported; set the container's securityContext.privileged")
+		}
+	case run.UCR != nil:
+		container.Image = run.UCR.Image.ID
+		if run.UCR.Image.ForcePull {
+			container.ImagePullPolicy = "Always"
+		}
+		if run.UCR.Image.Kind == met.ImageKindAppc {
+			export.warn("appc image %s can't be run by Kubernetes", run.UCR.Image.ID)
+		}
+		if run.UCR.Privileged {
+			export.warn("privileged not exported; set the container's securityContext.privileged")
+		}
+	default:
+		export.warn("run has no docker image; Kubernetes needs one to run the command")
+	}
+	if run.Cmd != "" {
+		container.Command = []string{"/bin/sh", "-c", run.Cmd}
+	}
+	names := make([]string, 0, len(run.Env))
+	for name := range run.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		container.Env = append(container.Env, EnvVar{Name: name, Value: run.Env[name]})
+	}
+	for _, name := range sortedKeys(run.EnvSecrets) {
+		export.warn("env %s from secret %s not exported; use valueFrom.secretKeyRef", name, run.EnvSecrets[name])
+	}
+
+	pod := PodSpec{}
+	for i, vol := range run.Volumes {
+		if vol.Secret != "" {
+			export.warn("secret volume %s at %s not exported; use a secret volume", vol.Secret, vol.ContainerPath)
+			continue
+		}
+		name := fmt.Sprintf("volume-%d", i)
+		pod.Volumes = append(pod.Volumes, Volume{Name: name, HostPath: &HostPathVolumeSource{Path: vol.HostPath}})
+		container.VolumeMounts = append(container.VolumeMounts, VolumeMount{Name: name, MountPath: string(vol.ContainerPath), ReadOnly: vol.Mode == met.RO})
+		export.warn("volume %s:%s exported as a hostPath volume; the path must exist on every node the pod can run on", vol.HostPath, vol.ContainerPath)
+	}
+	if run.Placement != nil {
+		for _, constraint := range run.Placement.Constraints {
+			export.warn("constraint %s %s %s not exported; use nodeSelector or affinity", constraint.Attribute, constraint.Operator.String(), constraint.Value)
+		}
+	}
+	for _, artifact := range run.Artifacts {
+		export.warn("artifact %s not exported; fetch it in the image or an init container", artifact.URI)
+	}
+	if run.User != "" {
+		if uid, err := strconv.ParseInt(run.User, 10, 64); err == nil {
+			pod.SecurityContext = &PodSecurityContext{RunAsUser: &uid}
+		} else {
+			export.warn("user %s not exported; securityContext.runAsUser needs a numeric uid", run.User)
+		}
+	}
+	pod.Containers = []Container{container}
+
+	spec := JobSpec{}
+	pod.RestartPolicy = "Never"
+	backoff := int32(0)
+	if run.Restart != nil {
+		if run.Restart.Policy == "ON_FAILURE" {
+			pod.RestartPolicy = "OnFailure"
+			backoff = DefaultBackoffLimit
+		}
+		if run.Restart.ActiveDeadlineSeconds > 0 {
+			deadline := int64(run.Restart.ActiveDeadlineSeconds)
+			spec.ActiveDeadlineSeconds = &deadline
+		}
+	}
+	spec.BackoffLimit = &backoff
+	spec.Template = PodTemplateSpec{Spec: pod}
+	return JobTemplateSpec{Spec: spec}
+}
+
+// resources - Mesos enforces cpus and mem as limits so they are both requests and limits
+func resources(run *met.Run) ResourceRequirements {
+	reqs := ResourceRequirements{Requests: map[string]string{}, Limits: map[string]string{}}
+	if run.Cpus > 0 {
+		cpu := strconv.FormatInt(int64(run.Cpus*1000+0.5), 10) + "m"
+		reqs.Requests["cpu"], reqs.Limits["cpu"] = cpu, cpu
+	}
+	if run.Mem > 0 {
+		mem := strconv.Itoa(run.Mem) + "Mi"
+		reqs.Requests["memory"], reqs.Limits["memory"] = mem, mem
+	}
+	if run.Disk > 0 {
+		reqs.Requests["ephemeral-storage"] = strconv.Itoa(run.Disk) + "Mi"
+	}
+	return reqs
+}
+
+// resourceName - Kubernetes object names are lowercase alphanumerics, '-' and '.'
+func resourceName(id string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, id), "-.")
+}
+
+// containerName - container names are DNS labels so dots become dashes
+func containerName(id string) string {
+	return strings.Replace(resourceName(id), ".", "-", -1)
+}
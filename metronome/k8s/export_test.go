@@ -0,0 +1,130 @@
+package k8s_test
+
+import (
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/k8s"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const exportedYAML = `---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: prod.example.app
+  labels:
+    owner: zeus
+  annotations:
+    metronome.dcos.io/description: Example Application
+spec:
+  schedule: 0 */4 * * 1-5
+  timeZone: America/Los_Angeles
+  concurrencyPolicy: Forbid
+  startingDeadlineSeconds: 60
+  jobTemplate:
+    spec:
+      backoffLimit: 6
+      activeDeadlineSeconds: 120
+      template:
+        spec:
+          restartPolicy: OnFailure
+          containers:
+          - name: prod-example-app
+            image: foo/bla:test
+            command:
+            - /bin/sh
+            - -c
+            - nuke --dry
+            env:
+            - name: CONNECT
+              value: direct
+            - name: MON
+              value: test
+            resources:
+              requests:
+                cpu: 1500m
+                ephemeral-storage: 128Mi
+                memory: 32Mi
+              limits:
+                cpu: 1500m
+                memory: 32Mi
+            volumeMounts:
+            - name: volume-0
+              mountPath: /mnt/test
+              readOnly: true
+          volumes:
+          - name: volume-0
+            hostPath:
+              path: /etc/guest
+`
+
+var _ = Describe("Export", func() {
+	var job *met.Job
+
+	BeforeEach(func() {
+		job = &met.Job{
+			ID:          "prod.example.app",
+			Description: "Example Application",
+			Labels:      &met.Labels{"owner": "zeus"},
+			Run: &met.Run{
+				Cmd:            "nuke --dry",
+				Cpus:           1.5,
+				Mem:            32,
+				Disk:           128,
+				Docker:         &met.Docker{Image: "foo/bla:test"},
+				Env:            map[string]string{"MON": "test", "CONNECT": "direct"},
+				MaxLaunchDelay: 3600,
+				Placement:      &met.Placement{Constraints: []met.Constraint{{Attribute: "rack", Operator: met.EQ, Value: "rack-2"}}},
+				Restart:        &met.Restart{Policy: "ON_FAILURE", ActiveDeadlineSeconds: 120},
+				Volumes:        []met.Volume{{ContainerPath: "/mnt/test", HostPath: "/etc/guest", Mode: met.RO}},
+			},
+			Schedules: []*met.Schedule{{
+				ID:                      "weekdays",
+				Cron:                    "0 */4 * * 1-5",
+				Timezone:                "America/Los_Angeles",
+				ConcurrencyPolicy:       "FORBID",
+				StartingDeadlineSeconds: 60,
+				Enabled:                 true,
+			}},
+		}
+	})
+
+	It("Writes a CronJob per schedule", func() {
+		export, err := FromJob(job, nil)
+		Expect(err).To(BeNil())
+		doc, err := export.YAML()
+		Expect(err).To(BeNil())
+		Expect(string(doc)).To(Equal(exportedYAML))
+		Expect(export.Warnings).To(ConsistOf(
+			ContainSubstring("hostPath"),
+			ContainSubstring("constraint rack EQ rack-2"),
+		))
+	})
+
+	It("Names CronJobs by schedule when there are several", func() {
+		export, err := FromJob(job, []met.Schedule{
+			{ID: "a", Cron: "@daily", ConcurrencyPolicy: "ALLOW", Enabled: false},
+			{ID: "b", Cron: "5 4 1 1 * 2018", ConcurrencyPolicy: "REPLACE", Enabled: true},
+		})
+		Expect(err).To(BeNil())
+		Expect(export.CronJobs).To(HaveLen(2))
+		Expect(export.CronJobs[0].Metadata.Name).To(Equal("prod.example.app-a"))
+		Expect(*export.CronJobs[0].Spec.Suspend).To(BeTrue())
+		Expect(export.CronJobs[1].Spec.Schedule).To(Equal("5 4 1 1 *"))
+		Expect(export.CronJobs[1].Spec.ConcurrencyPolicy).To(Equal("Replace"))
+		Expect(export.Warnings).To(ContainElement(ContainSubstring("no year field")))
+	})
+
+	It("Suspends jobs without schedules and maps NEVER to no retries", func() {
+		job.Schedules = nil
+		job.Run.Restart = &met.Restart{Policy: "NEVER"}
+		export, err := FromJob(job, nil)
+		Expect(err).To(BeNil())
+		Expect(export.CronJobs).To(HaveLen(1))
+		spec := export.CronJobs[0].Spec
+		Expect(*spec.Suspend).To(BeTrue())
+		Expect(*spec.JobTemplate.Spec.BackoffLimit).To(BeEquivalentTo(0))
+		Expect(spec.JobTemplate.Spec.Template.Spec.RestartPolicy).To(Equal("Never"))
+	})
+})
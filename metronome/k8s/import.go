@@ -0,0 +1,366 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+	"gopkg.in/yaml.v2"
+)
+
+// Metronome's defaults for settings a CronJob may leave out.  They are filled in so the imported job is complete
+const (
+	DefaultCpus                    = 1.0
+	DefaultMem                     = 128
+	DefaultMaxLaunchDelay          = 3600
+	DefaultStartingDeadlineSeconds = 900
+	DefaultTimezone                = "UTC"
+)
+
+// Import - the Metronome job and schedule for a CronJob and what couldn't be carried over
+type Import struct {
+	// CronJob - the CronJob's name
+	CronJob   string         `json:"cronJob"`
+	Job       *met.Job       `json:"job"`
+	Schedules []met.Schedule `json:"schedules,omitempty"`
+	// Unsupported - CronJob features with no Metronome equivalent.  The job is still usable
+	Unsupported []string `json:"unsupported,omitempty"`
+	// Errors - problems that make the converted job unusable as is
+	Errors []string `json:"errors,omitempty"`
+}
+
+// OK - true when the job can be created
+func (imp *Import) OK() bool {
+	return len(imp.Errors) == 0
+}
+
+func (imp *Import) unsupported(format string, args ...interface{}) {
+	imp.Unsupported = append(imp.Unsupported, fmt.Sprintf(format, args...))
+}
+
+func (imp *Import) error(format string, args ...interface{}) {
+	imp.Errors = append(imp.Errors, fmt.Sprintf(format, args...))
+}
+
+// ParseCronJobs - decodes a yaml stream of CronJob documents.  Other kinds are an error
+func ParseCronJobs(data []byte) ([]CronJob, error) {
+	var cronJobs []CronJob
+	for i, doc := range met.SplitYAMLDocuments(data) {
+		var cronJob CronJob
+		if err := yaml.Unmarshal(doc, &cronJob); err != nil {
+			return nil, fmt.Errorf("document %d: %s", i+1, err)
+		}
+		if cronJob.Kind == "" && cronJob.APIVersion == "" {
+			continue
+		}
+		if cronJob.Kind != Kind || !strings.HasPrefix(cronJob.APIVersion, "batch/") {
+			return nil, fmt.Errorf("document %d: %s %s is not a batch CronJob", i+1, cronJob.APIVersion, cronJob.Kind)
+		}
+		cronJobs = append(cronJobs, cronJob)
+	}
+	return cronJobs, nil
+}
+
+// ToJobs - convert every CronJob
+func ToJobs(cronJobs []CronJob) []Import {
+	imports := make([]Import, 0, len(cronJobs))
+	for i := range cronJobs {
+		imports = append(imports, *ToJob(&cronJobs[i]))
+	}
+	return imports
+}
+
+// ToJob - map a CronJob to a Metronome job and schedule
+func ToJob(cronJob *CronJob) *Import {
+	imp := &Import{CronJob: cronJob.Metadata.Name}
+	meta := cronJob.Metadata
+	job := &met.Job{ID: meta.Name, Description: meta.Annotations[DescriptionAnnotation]}
+	if meta.Namespace != "" {
+		imp.unsupported("namespace %s", meta.Namespace)
+	}
+	if len(meta.Labels) > 0 {
+		labels := make(met.Labels, len(meta.Labels))
+		for k, v := range meta.Labels {
+			labels[k] = v
+		}
+		job.Labels = &labels
+	}
+	imp.Job = job
+	job.Run = imp.run(&cronJob.Spec.JobTemplate.Spec)
+	imp.schedule(&cronJob.Spec)
+
+	imp.invalid("", job.Validate())
+	for i := range imp.Schedules {
+		imp.invalid(fmt.Sprintf("schedules[%d].", i), imp.Schedules[i].Validate())
+	}
+	return imp
+}
+
+// invalid - record each validation failure as an error
+func (imp *Import) invalid(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	if errs, ok := err.(met.ValidationErrors); ok {
+		for _, fe := range errs {
+			imp.error("%s%s", prefix, fe.Error())
+		}
+		return
+	}
+	imp.error("%s%s", prefix, err.Error())
+}
+
+func (imp *Import) run(spec *JobSpec) *met.Run {
+	pod := &spec.Template.Spec
+	run := &met.Run{MaxLaunchDelay: DefaultMaxLaunchDelay, Volumes: []met.Volume{}}
+	if len(pod.InitContainers) > 0 {
+		imp.unsupported("initContainers: %d init containers dropped", len(pod.InitContainers))
+	}
+	if len(pod.Containers) == 0 {
+		imp.error("pod has no containers")
+		run.Cpus, run.Mem = DefaultCpus, DefaultMem
+		return run
+	}
+	if len(pod.Containers) > 1 {
+		names := make([]string, 0, len(pod.Containers)-1)
+		for _, c := range pod.Containers[1:] {
+			names = append(names, c.Name)
+		}
+		imp.unsupported("multi-container pod: only %s is run, %s dropped", pod.Containers[0].Name, strings.Join(names, ","))
+	}
+	container := &pod.Containers[0]
+	run.Docker = &met.Docker{Image: container.Image}
+	switch container.ImagePullPolicy {
+	case "", "IfNotPresent":
+	case "Always":
+		run.Docker.ForcePullImage = true
+	default:
+		imp.unsupported("imagePullPolicy %s", container.ImagePullPolicy)
+	}
+	imp.command(container, run)
+	imp.env(container, run)
+	imp.resources(container, run)
+	imp.volumes(pod, container, run)
+
+	for _, key := range sortedKeys(pod.NodeSelector) {
+		if run.Placement == nil {
+			run.Placement = &met.Placement{}
+		}
+		run.Placement.Constraints = append(run.Placement.Constraints, met.Constraint{Attribute: key, Operator: met.EQ, Value: pod.NodeSelector[key]})
+	}
+	if len(pod.Affinity) > 0 {
+		imp.unsupported("affinity")
+	}
+	if pod.SecurityContext != nil && pod.SecurityContext.RunAsUser != nil {
+		imp.unsupported("securityContext.runAsUser %d: Mesos runs as a user name; set run.user", *pod.SecurityContext.RunAsUser)
+	}
+
+	restart := &met.Restart{Policy: "NEVER"}
+	if pod.RestartPolicy == "OnFailure" {
+		restart.Policy = "ON_FAILURE"
+	} else if spec.BackoffLimit == nil || *spec.BackoffLimit > 0 {
+		imp.unsupported("backoffLimit: Metronome only retries with restartPolicy OnFailure")
+	}
+	if spec.ActiveDeadlineSeconds != nil {
+		restart.ActiveDeadlineSeconds = int(*spec.ActiveDeadlineSeconds)
+	}
+	run.Restart = restart
+	return run
+}
+
+// command - `sh -c <script>` becomes cmd.  Otherwise a command overrides the entrypoint so it runs through the shell,
+// and args alone are passed to the image's entrypoint
+func (imp *Import) command(container *Container, run *met.Run) {
+	cmd := container.Command
+	if len(cmd) == 0 {
+		run.Args = container.Args
+		return
+	}
+	if len(cmd) == 3 && (cmd[0] == "/bin/sh" || cmd[0] == "sh" || cmd[0] == "/bin/bash" || cmd[0] == "bash") && cmd[1] == "-c" && len(container.Args) == 0 {
+		run.Cmd = cmd[2]
+		return
+	}
+	words := make([]string, 0, len(cmd)+len(container.Args))
+	for _, word := range append(append([]string{}, cmd...), container.Args...) {
+		words = append(words, shellQuote(word))
+	}
+	run.Cmd = strings.Join(words, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+func shellQuote(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+	return "'" + strings.Replace(word, "'", `'"'"'`, -1) + "'"
+}
+
+func (imp *Import) env(container *Container, run *met.Run) {
+	for _, env := range container.Env {
+		if env.ValueFrom != nil {
+			imp.unsupported("env %s valueFrom", env.Name)
+			continue
+		}
+		if run.Env == nil {
+			run.Env = make(map[string]string)
+		}
+		run.Env[env.Name] = env.Value
+	}
+	if len(container.EnvFrom) > 0 {
+		imp.unsupported("envFrom")
+	}
+}
+
+// resources - limits are what Mesos enforces so they win over requests
+func (imp *Import) resources(container *Container, run *met.Run) {
+	quantity := func(name string) (string, bool) {
+		if q, ok := container.Resources.Limits[name]; ok {
+			return q, true
+		}
+		q, ok := container.Resources.Requests[name]
+		return q, ok
+	}
+	run.Cpus, run.Mem = DefaultCpus, DefaultMem
+	if q, ok := quantity("cpu"); ok {
+		if cpus, err := ParseQuantity(q); err != nil {
+			imp.error("cpu %s: %s", q, err)
+		} else {
+			run.Cpus = cpus
+		}
+	}
+	if q, ok := quantity("memory"); ok {
+		if bytes, err := ParseQuantity(q); err != nil {
+			imp.error("memory %s: %s", q, err)
+		} else {
+			run.Mem = int(bytes / (1 << 20))
+		}
+	}
+	if q, ok := quantity("ephemeral-storage"); ok {
+		if bytes, err := ParseQuantity(q); err != nil {
+			imp.error("ephemeral-storage %s: %s", q, err)
+		} else {
+			run.Disk = int(bytes / (1 << 20))
+		}
+	}
+	for _, name := range sortedKeys(container.Resources.Limits) {
+		if name != "cpu" && name != "memory" && name != "ephemeral-storage" {
+			imp.unsupported("resource %s", name)
+		}
+	}
+}
+
+func (imp *Import) volumes(pod *PodSpec, container *Container, run *met.Run) {
+	mounts := make(map[string][]VolumeMount)
+	for _, mount := range container.VolumeMounts {
+		mounts[mount.Name] = append(mounts[mount.Name], mount)
+	}
+	for _, vol := range pod.Volumes {
+		var kind string
+		switch {
+		case vol.HostPath != nil:
+			for _, mount := range mounts[vol.Name] {
+				mode := met.RW
+				if mount.ReadOnly {
+					mode = met.RO
+				}
+				run.Volumes = append(run.Volumes, met.Volume{ContainerPath: met.ContainerPath(mount.MountPath), HostPath: vol.HostPath.Path, Mode: mode})
+			}
+			continue
+		case vol.ConfigMap != nil:
+			kind = "configMap"
+		case vol.Secret != nil:
+			kind = "secret"
+		case vol.EmptyDir != nil:
+			kind = "emptyDir"
+		case vol.PersistentVolumeClaim != nil:
+			kind = "persistentVolumeClaim"
+		default:
+			kind = "unknown"
+		}
+		imp.unsupported("%s volume %s", kind, vol.Name)
+	}
+}
+
+var cronTZ = regexp.MustCompile(`^(?:CRON_TZ|TZ)=(\S+)\s+(.*)$`)
+
+func (imp *Import) schedule(spec *CronJobSpec) {
+	sched := met.Schedule{
+		ID:                      "default",
+		Cron:                    strings.TrimSpace(spec.Schedule),
+		Timezone:                spec.TimeZone,
+		ConcurrencyPolicy:       "ALLOW",
+		Enabled:                 spec.Suspend == nil || !*spec.Suspend,
+		StartingDeadlineSeconds: DefaultStartingDeadlineSeconds,
+	}
+	if match := cronTZ.FindStringSubmatch(sched.Cron); match != nil {
+		sched.Timezone, sched.Cron = match[1], match[2]
+	}
+	if sched.Timezone == "" {
+		sched.Timezone = DefaultTimezone
+	}
+	if strings.HasPrefix(sched.Cron, "@every") {
+		imp.error("schedule '%s': @every intervals aren't supported; use a cron expression", sched.Cron)
+	} else if _, err := cron.Parse(sched.Cron); err != nil {
+		imp.error("schedule: %s", err)
+	}
+	switch spec.ConcurrencyPolicy {
+	case "", "Allow":
+	case "Forbid":
+		sched.ConcurrencyPolicy = "FORBID"
+	case "Replace":
+		sched.ConcurrencyPolicy = "REPLACE"
+	default:
+		imp.error("unknown concurrencyPolicy %s", spec.ConcurrencyPolicy)
+	}
+	// Metronome needs at least a second, so 0 keeps the default
+	if spec.StartingDeadlineSeconds != nil && *spec.StartingDeadlineSeconds > 0 {
+		sched.StartingDeadlineSeconds = int(*spec.StartingDeadlineSeconds)
+	}
+	imp.Schedules = []met.Schedule{sched}
+}
+
+var quantityRe = regexp.MustCompile(`^([0-9.]+(?:[eE][-+]?[0-9]+)?)([a-zA-Z]*)$`)
+
+var quantitySuffixes = map[string]float64{
+	"":   1,
+	"m":  1e-3,
+	"k":  1e3,
+	"M":  1e6,
+	"G":  1e9,
+	"T":  1e12,
+	"Ki": 1 << 10,
+	"Mi": 1 << 20,
+	"Gi": 1 << 30,
+	"Ti": 1 << 40,
+}
+
+// ParseQuantity - a Kubernetes resource quantity e.g. 500m, 1.5, 128Mi, 1G
+func ParseQuantity(quantity string) (float64, error) {
+	match := quantityRe.FindStringSubmatch(strings.TrimSpace(quantity))
+	if match == nil {
+		return 0, fmt.Errorf("'%s' is not a quantity", quantity)
+	}
+	scale, ok := quantitySuffixes[match[2]]
+	if !ok {
+		return 0, fmt.Errorf("'%s' has an unknown suffix %s", quantity, match[2])
+	}
+	val, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	return val * scale, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
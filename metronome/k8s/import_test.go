@@ -0,0 +1,219 @@
+package k8s_test
+
+import (
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/k8s"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const cronJobYAML = `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: nightly.report
+  namespace: batch
+  labels:
+    team: data
+spec:
+  schedule: "30 2 * * *"
+  timeZone: Europe/Berlin
+  concurrencyPolicy: Forbid
+  startingDeadlineSeconds: 120
+  successfulJobsHistoryLimit: 3
+  jobTemplate:
+    spec:
+      backoffLimit: 3
+      activeDeadlineSeconds: 600
+      template:
+        spec:
+          restartPolicy: OnFailure
+          nodeSelector:
+            rack: rack-1
+          initContainers:
+          - name: fetch
+            image: busybox
+          containers:
+          - name: report
+            image: example/report:2.0
+            command: ["python", "report.py"]
+            args: ["--since", "1 day"]
+            env:
+            - name: STAGE
+              value: prod
+            - name: TOKEN
+              valueFrom:
+                secretKeyRef: {name: report, key: token}
+            resources:
+              requests:
+                cpu: 250m
+                memory: 256Mi
+              limits:
+                cpu: 500m
+                memory: 1Gi
+                ephemeral-storage: 2Gi
+            volumeMounts:
+            - name: data
+              mountPath: /data
+              readOnly: true
+            - name: config
+              mountPath: /etc/report
+          - name: sidecar
+            image: example/proxy
+          volumes:
+          - name: data
+            hostPath:
+              path: /mnt/data
+          - name: config
+            configMap:
+              name: report-config
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  schedule: "CRON_TZ=America/New_York @hourly"
+  suspend: true
+  jobTemplate:
+    spec:
+      backoffLimit: 0
+      template:
+        spec:
+          restartPolicy: Never
+          containers:
+          - name: cleanup
+            image: example/cleanup
+            command: ["/bin/sh", "-c", "rm -rf /tmp/*"]
+`
+
+var _ = Describe("Import", func() {
+	var imports []Import
+
+	BeforeEach(func() {
+		cronJobs, err := ParseCronJobs([]byte(cronJobYAML))
+		Expect(err).To(BeNil())
+		Expect(cronJobs).To(HaveLen(2))
+		imports = ToJobs(cronJobs)
+	})
+
+	It("Maps the container, resources and schedule", func() {
+		imp := imports[0]
+		Expect(imp.OK()).To(BeTrue(), "%v", imp.Errors)
+		job := imp.Job
+		Expect(job.ID).To(Equal("nightly.report"))
+		Expect(*job.Labels).To(Equal(met.Labels{"team": "data"}))
+		run := job.Run
+		Expect(run.Docker.Image).To(Equal("example/report:2.0"))
+		Expect(run.Cmd).To(Equal("python report.py --since '1 day'"))
+		Expect(run.Env).To(Equal(map[string]string{"STAGE": "prod"}))
+		Expect(run.Cpus).To(Equal(0.5))
+		Expect(run.Mem).To(Equal(1024))
+		Expect(run.Disk).To(Equal(2048))
+		Expect(run.Volumes).To(Equal([]met.Volume{{ContainerPath: "/data", HostPath: "/mnt/data", Mode: met.RO}}))
+		Expect(run.Placement.Constraints).To(Equal([]met.Constraint{{Attribute: "rack", Operator: met.EQ, Value: "rack-1"}}))
+		Expect(*run.Restart).To(Equal(met.Restart{Policy: "ON_FAILURE", ActiveDeadlineSeconds: 600}))
+
+		Expect(imp.Schedules).To(Equal([]met.Schedule{{
+			ID:                      "default",
+			Cron:                    "30 2 * * *",
+			Timezone:                "Europe/Berlin",
+			ConcurrencyPolicy:       "FORBID",
+			Enabled:                 true,
+			StartingDeadlineSeconds: 120,
+		}}))
+	})
+
+	It("Reports unsupported features", func() {
+		Expect(imports[0].Unsupported).To(ConsistOf(
+			ContainSubstring("namespace batch"),
+			ContainSubstring("initContainers"),
+			ContainSubstring("multi-container pod: only report is run, sidecar dropped"),
+			ContainSubstring("env TOKEN valueFrom"),
+			ContainSubstring("configMap volume config"),
+		))
+	})
+
+	It("Handles shell commands, CRON_TZ and suspended jobs", func() {
+		imp := imports[1]
+		Expect(imp.OK()).To(BeTrue(), "%v", imp.Errors)
+		Expect(imp.Job.Run.Cmd).To(Equal("rm -rf /tmp/*"))
+		Expect(imp.Job.Run.Restart.Policy).To(Equal("NEVER"))
+		Expect(imp.Job.Run.Cpus).To(Equal(DefaultCpus))
+		sched := imp.Schedules[0]
+		Expect(sched.Cron).To(Equal("@hourly"))
+		Expect(sched.Timezone).To(Equal("America/New_York"))
+		Expect(sched.Enabled).To(BeFalse())
+		Expect(imp.Unsupported).To(BeEmpty())
+	})
+
+	It("Round trips an exported job", func() {
+		job := &met.Job{
+			ID:  "round.trip",
+			Run: &met.Run{Cmd: "echo hi", Cpus: 0.25, Mem: 64, Disk: 0, MaxLaunchDelay: 3600, Docker: &met.Docker{Image: "alpine"}, Restart: &met.Restart{Policy: "NEVER"}, Volumes: []met.Volume{}},
+		}
+		sched := met.Schedule{ID: "default", Cron: "*/5 * * * *", Timezone: "UTC", ConcurrencyPolicy: "REPLACE", Enabled: true, StartingDeadlineSeconds: 30}
+		export, err := FromJob(job, []met.Schedule{sched})
+		Expect(err).To(BeNil())
+		doc, err := export.YAML()
+		Expect(err).To(BeNil())
+		cronJobs, err := ParseCronJobs(doc)
+		Expect(err).To(BeNil())
+		imp := ToJob(&cronJobs[0])
+		Expect(imp.OK()).To(BeTrue(), "%v", imp.Errors)
+		Expect(imp.Job.Run).To(Equal(job.Run))
+		Expect(imp.Schedules).To(Equal([]met.Schedule{sched}))
+	})
+
+	It("Rejects other kinds and unsupported schedules", func() {
+		_, err := ParseCronJobs([]byte("apiVersion: v1\nkind: Pod\n"))
+		Expect(err).ToNot(BeNil())
+		imp := ToJob(&CronJob{Spec: CronJobSpec{Schedule: "@every 1h"}})
+		Expect(imp.OK()).To(BeFalse())
+		Expect(imp.Errors).To(ContainElement(ContainSubstring("@every")))
+		Expect(imp.Errors).To(ContainElement(ContainSubstring("no containers")))
+	})
+
+	It("Fills in Metronome's defaults for what a CronJob leaves out", func() {
+		cronJobs, err := ParseCronJobs([]byte(`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: minimal
+spec:
+  schedule: "0 3 * * *"
+  startingDeadlineSeconds: 0
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: minimal
+            image: busybox
+`))
+		Expect(err).To(BeNil())
+		imp := ToJob(&cronJobs[0])
+		Expect(imp.Errors).To(BeEmpty())
+		Expect(imp.Job.Run.Cpus).To(Equal(DefaultCpus))
+		Expect(imp.Job.Run.Mem).To(Equal(DefaultMem))
+		Expect(imp.Job.Run.MaxLaunchDelay).To(Equal(DefaultMaxLaunchDelay))
+		Expect(imp.Job.Run.Restart.Policy).To(Equal("NEVER"))
+		Expect(imp.Schedules).To(Equal([]met.Schedule{{
+			ID:                      "default",
+			Cron:                    "0 3 * * *",
+			Timezone:                DefaultTimezone,
+			ConcurrencyPolicy:       "ALLOW",
+			Enabled:                 true,
+			StartingDeadlineSeconds: DefaultStartingDeadlineSeconds,
+		}}))
+		Expect(imp.Job.Validate()).To(Succeed())
+	})
+	It("Parses quantities", func() {
+		for q, want := range map[string]float64{"500m": 0.5, "2": 2, "128Mi": 128 << 20, "1G": 1e9, "1.5Gi": 1.5 * (1 << 30)} {
+			got, err := ParseQuantity(q)
+			Expect(err).To(BeNil())
+			Expect(got).To(Equal(want), q)
+		}
+		_, err := ParseQuantity("12Qi")
+		Expect(err).ToNot(BeNil())
+	})
+})
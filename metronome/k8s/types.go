@@ -0,0 +1,121 @@
+// Package k8s converts between Metronome jobs and Kubernetes batch/v1 CronJob manifests.
+//
+// Only the subset of the Kubernetes API that has a Metronome counterpart, or that
+// needs to be reported as unsupported, is modelled here.
+package k8s
+
+// APIVersion, Kind - the manifest type this package reads and writes
+const (
+	APIVersion = "batch/v1"
+	Kind       = "CronJob"
+)
+
+// CronJob - batch/v1 CronJob
+type CronJob struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   ObjectMeta  `yaml:"metadata"`
+	Spec       CronJobSpec `yaml:"spec"`
+}
+
+// ObjectMeta - name, namespace, labels and annotations
+type ObjectMeta struct {
+	Name        string            `yaml:"name,omitempty"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// CronJobSpec - when and how the job template runs
+type CronJobSpec struct {
+	Schedule                string          `yaml:"schedule"`
+	TimeZone                string          `yaml:"timeZone,omitempty"`
+	ConcurrencyPolicy       string          `yaml:"concurrencyPolicy,omitempty"`
+	StartingDeadlineSeconds *int64          `yaml:"startingDeadlineSeconds,omitempty"`
+	Suspend                 *bool           `yaml:"suspend,omitempty"`
+	JobTemplate             JobTemplateSpec `yaml:"jobTemplate"`
+}
+
+// JobTemplateSpec - the Job created for each run
+type JobTemplateSpec struct {
+	Metadata ObjectMeta `yaml:"metadata,omitempty"`
+	Spec     JobSpec    `yaml:"spec"`
+}
+
+// JobSpec - retry and deadline settings around the pod
+type JobSpec struct {
+	BackoffLimit          *int32          `yaml:"backoffLimit,omitempty"`
+	ActiveDeadlineSeconds *int64          `yaml:"activeDeadlineSeconds,omitempty"`
+	Template              PodTemplateSpec `yaml:"template"`
+}
+
+// PodTemplateSpec - the pod run by the job
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `yaml:"metadata,omitempty"`
+	Spec     PodSpec    `yaml:"spec"`
+}
+
+// PodSpec - containers and volumes
+type PodSpec struct {
+	RestartPolicy   string                 `yaml:"restartPolicy,omitempty"`
+	InitContainers  []Container            `yaml:"initContainers,omitempty"`
+	Containers      []Container            `yaml:"containers"`
+	Volumes         []Volume               `yaml:"volumes,omitempty"`
+	NodeSelector    map[string]string      `yaml:"nodeSelector,omitempty"`
+	SecurityContext *PodSecurityContext    `yaml:"securityContext,omitempty"`
+	Affinity        map[string]interface{} `yaml:"affinity,omitempty"`
+}
+
+// PodSecurityContext - the user the pod runs as
+type PodSecurityContext struct {
+	RunAsUser *int64 `yaml:"runAsUser,omitempty"`
+}
+
+// Container - a pod container
+type Container struct {
+	Name            string               `yaml:"name"`
+	Image           string               `yaml:"image"`
+	ImagePullPolicy string               `yaml:"imagePullPolicy,omitempty"`
+	Command         []string             `yaml:"command,omitempty"`
+	Args            []string             `yaml:"args,omitempty"`
+	Env             []EnvVar             `yaml:"env,omitempty"`
+	EnvFrom         []interface{}        `yaml:"envFrom,omitempty"`
+	Resources       ResourceRequirements `yaml:"resources,omitempty"`
+	VolumeMounts    []VolumeMount        `yaml:"volumeMounts,omitempty"`
+}
+
+// EnvVar - a literal value or a reference to one
+type EnvVar struct {
+	Name      string                 `yaml:"name"`
+	Value     string                 `yaml:"value,omitempty"`
+	ValueFrom map[string]interface{} `yaml:"valueFrom,omitempty"`
+}
+
+// ResourceRequirements - quantities keyed by resource name e.g. cpu: 500m, memory: 128Mi
+type ResourceRequirements struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
+}
+
+// VolumeMount - where a pod volume appears in a container
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+// Volume - a pod volume.  Only hostPath has a Metronome equivalent; other sources are kept so they can be reported
+type Volume struct {
+	Name                  string                 `yaml:"name"`
+	HostPath              *HostPathVolumeSource  `yaml:"hostPath,omitempty"`
+	EmptyDir              map[string]interface{} `yaml:"emptyDir,omitempty"`
+	ConfigMap             map[string]interface{} `yaml:"configMap,omitempty"`
+	Secret                map[string]interface{} `yaml:"secret,omitempty"`
+	PersistentVolumeClaim map[string]interface{} `yaml:"persistentVolumeClaim,omitempty"`
+}
+
+// HostPathVolumeSource - a directory on the node
+type HostPathVolumeSource struct {
+	Path string `yaml:"path"`
+	Type string `yaml:"type,omitempty"`
+}
@@ -0,0 +1,221 @@
+package lint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Config - the lint config file.  Rules configures or disables built-in rules by name; Custom adds rules
+//
+//	rules:
+//	  max-mem: {max: 8192}
+//	  required-labels: {labels: [owner, team]}
+//	  no-host-volumes: {allow: [/var/log]}
+//	  restart-deadline: {severity: warning}
+//	custom:
+//	- name: team-prefix
+//	  path: id
+//	  pattern: ^(data|web)\.
+//	  message: job ids start with the team
+//	- name: no-privileged
+//	  path: run.docker.privileged
+//	  forbidden: [true]
+type Config struct {
+	Rules  map[string]RuleConfig `json:"rules,omitempty"`
+	Custom []CustomRule          `json:"custom,omitempty"`
+}
+
+// LoadConfig - a config from yaml or json
+func LoadConfig(data []byte) (Config, error) {
+	var config Config
+	if err := met.FromYAML(data, &config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// CustomRule - a user-defined rule checking the values at Path in the job's json.  Path is dotted and `*` matches
+// every element of a list or object e.g. run.volumes.*.hostPath or run.env.*.  Each condition that is set must
+// hold for every value found
+type CustomRule struct {
+	Name     string   `json:"name"`
+	Severity Severity `json:"severity,omitempty"`
+	// Message - explains the finding.  Defaults to the condition that failed
+	Message string `json:"message,omitempty"`
+	Path    string `json:"path"`
+	// Required - the path must have a value
+	Required bool `json:"required,omitempty"`
+	// Pattern - string values must match this regular expression
+	Pattern string `json:"pattern,omitempty"`
+	// Allowed - values must be one of these
+	Allowed []interface{} `json:"allowed,omitempty"`
+	// Forbidden - values must not be one of these
+	Forbidden []interface{} `json:"forbidden,omitempty"`
+	// Min, Max - numeric values must lie within
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+}
+
+type customRule struct {
+	CustomRule
+	pattern *regexp.Regexp
+}
+
+// compile - the rule, checking it is complete
+func (custom CustomRule) compile() (Rule, error) {
+	if custom.Name == "" {
+		return nil, errors.New("name required")
+	} else if _, ok := builtinRule(custom.Name); ok {
+		return nil, fmt.Errorf("%s is a built-in rule", custom.Name)
+	} else if custom.Path == "" {
+		return nil, fmt.Errorf("%s: path required", custom.Name)
+	} else if custom.Severity != "" {
+		if err := custom.Severity.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s", custom.Name, err)
+		}
+	}
+	if !custom.Required && custom.Pattern == "" && custom.Allowed == nil && custom.Forbidden == nil &&
+		custom.Min == nil && custom.Max == nil {
+		return nil, fmt.Errorf("%s: needs a condition: required, pattern, allowed, forbidden, min or max", custom.Name)
+	}
+	rule := &customRule{CustomRule: custom}
+	if custom.Pattern != "" {
+		pattern, err := regexp.Compile(custom.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s: pattern %s", custom.Name, err)
+		}
+		rule.pattern = pattern
+	}
+	return rule, nil
+}
+
+func (rule *customRule) Name() string {
+	return rule.CustomRule.Name
+}
+
+func (rule *customRule) Check(theJob *met.Job) []Finding {
+	b, err := json.Marshal(theJob)
+	if err != nil {
+		return []Finding{{Message: err.Error()}}
+	}
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return []Finding{{Message: err.Error()}}
+	}
+	var findings []Finding
+	matches := find(value, "", strings.Split(rule.Path, "."))
+	if rule.Required && len(matches) == 0 {
+		findings = append(findings, Finding{Path: rule.Path, Message: rule.message("required")})
+	}
+	for _, match := range matches {
+		if problem := rule.problem(match.value); problem != "" {
+			findings = append(findings, Finding{Path: match.path, Message: rule.message(problem)})
+		}
+	}
+	return findings
+}
+
+func (rule *customRule) message(problem string) string {
+	if rule.Message != "" {
+		return rule.Message
+	}
+	return problem
+}
+
+// problem - the first condition value breaks, or ""
+func (rule *customRule) problem(value interface{}) string {
+	if rule.pattern != nil {
+		if s, ok := value.(string); ok && !rule.pattern.MatchString(s) {
+			return fmt.Sprintf("must match %s", rule.Pattern)
+		}
+	}
+	if rule.Allowed != nil && !contains(rule.Allowed, value) {
+		return fmt.Sprintf("must be one of %s", jsonText(rule.Allowed))
+	}
+	if contains(rule.Forbidden, value) {
+		return fmt.Sprintf("must not be %s", jsonText(value))
+	}
+	if n, ok := value.(float64); ok {
+		if rule.Min != nil && n < *rule.Min {
+			return fmt.Sprintf("must be at least %v", *rule.Min)
+		}
+		if rule.Max != nil && n > *rule.Max {
+			return fmt.Sprintf("must be at most %v", *rule.Max)
+		}
+	}
+	return ""
+}
+
+// contains - whether value is one of values, comparing json forms so 1 and 1.0 match
+func contains(values []interface{}, value interface{}) bool {
+	text := jsonText(value)
+	for _, v := range values {
+		if jsonText(v) == text {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonText(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+type match struct {
+	path  string
+	value interface{}
+}
+
+// find - the non-null values at path below value.  prefix is value's own path
+func find(value interface{}, prefix string, path []string) []match {
+	if value == nil {
+		return nil
+	}
+	if len(path) == 0 {
+		return []match{{prefix, value}}
+	}
+	key, rest := path[0], path[1:]
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if key != "*" {
+			return find(value[key], join(prefix, key), rest)
+		}
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var matches []match
+		for _, k := range keys {
+			matches = append(matches, find(value[k], join(prefix, k), rest)...)
+		}
+		return matches
+	case []interface{}:
+		if key != "*" {
+			return nil
+		}
+		var matches []match
+		for i, v := range value {
+			matches = append(matches, find(v, fmt.Sprintf("%s[%d]", prefix, i), rest)...)
+		}
+		return matches
+	}
+	return nil
+}
+
+func join(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,189 @@
+// Package lint checks job definitions against policy: built-in rules such as no-root-user and max-mem,
+// configured or disabled from a config file, plus user-defined rules that test values at json paths.
+//
+// Lint works on Job models so the same rules apply to definition files and to the jobs in Metronome.
+// Findings are plain structs with json tags, meant to be consumed by other tools.
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Severity - how serious a finding is
+type Severity string
+
+// Severities from least to most serious
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+var severities = []Severity{SeverityWarning, SeverityError}
+
+// rank - warning is 1, error 2, anything else 0
+func (severity Severity) rank() int {
+	for i, s := range severities {
+		if s == severity {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Validate - warning or error
+func (severity Severity) Validate() error {
+	if severity.rank() == 0 {
+		return fmt.Errorf("severity must be one of %s,%s not '%s'", SeverityWarning, SeverityError, severity)
+	}
+	return nil
+}
+
+// AtLeast - whether severity is as serious as threshold
+func (severity Severity) AtLeast(threshold Severity) bool {
+	return severity.rank() >= threshold.rank()
+}
+
+// Finding - a job breaking a rule.  Path is the json path of the offending value e.g. run.user, when there is one
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Job      string   `json:"job"`
+	// File - the definition file the job came from.  Set by callers linting files
+	File    string `json:"file,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// String - `severity rule job path: message`
+func (finding Finding) String() string {
+	job := finding.Job
+	if finding.File != "" {
+		job = finding.File + " " + job
+	}
+	if finding.Path != "" {
+		job += " " + finding.Path
+	}
+	return fmt.Sprintf("%s %s %s: %s", finding.Severity, finding.Rule, job, finding.Message)
+}
+
+// Rule - a policy check.  Check returns the job's violations with Path and Message set; the Linter fills in
+// Rule, Severity and Job
+type Rule interface {
+	Name() string
+	Check(theJob *met.Job) []Finding
+}
+
+type severeRule struct {
+	rule     Rule
+	severity Severity
+}
+
+// Linter - the rules to apply and how severe their findings are
+type Linter struct {
+	rules []severeRule
+}
+
+// DefaultLinter - a Linter with every built-in rule at its default settings
+func DefaultLinter() *Linter {
+	linter, err := New(Config{})
+	if err != nil {
+		panic(err)
+	}
+	return linter
+}
+
+// New - a Linter with the built-in rules as config sets them up followed by config's custom rules
+func New(config Config) (*Linter, error) {
+	for name := range config.Rules {
+		if _, ok := builtinRule(name); !ok {
+			return nil, fmt.Errorf("rules: unknown rule %s", name)
+		}
+	}
+	linter := &Linter{}
+	for _, builtin := range builtins {
+		settings := config.Rules[builtin.name]
+		if settings.Disabled {
+			continue
+		}
+		severity := builtin.severity
+		if settings.Severity != "" {
+			if err := settings.Severity.Validate(); err != nil {
+				return nil, fmt.Errorf("rules.%s: %s", builtin.name, err)
+			}
+			severity = settings.Severity
+		}
+		linter.Add(builtin.make(settings), severity)
+	}
+	for i, custom := range config.Custom {
+		rule, err := custom.compile()
+		if err != nil {
+			return nil, fmt.Errorf("custom[%d]: %s", i, err)
+		}
+		severity := custom.Severity
+		if severity == "" {
+			severity = SeverityError
+		}
+		linter.Add(rule, severity)
+	}
+	return linter, nil
+}
+
+// Add - apply rule too, reporting its findings at severity
+func (linter *Linter) Add(rule Rule, severity Severity) *Linter {
+	linter.rules = append(linter.rules, severeRule{rule, severity})
+	return linter
+}
+
+// Rules - the names of the rules applied, in order
+func (linter *Linter) Rules() []string {
+	names := make([]string, len(linter.rules))
+	for i, rule := range linter.rules {
+		names[i] = rule.rule.Name()
+	}
+	return names
+}
+
+// Lint - every rule's findings for the jobs, ordered by job, rule and path
+func (linter *Linter) Lint(jobs ...met.Job) []Finding {
+	findings := []Finding{}
+	for i := range jobs {
+		for _, rule := range linter.rules {
+			for _, finding := range rule.rule.Check(&jobs[i]) {
+				finding.Rule = rule.rule.Name()
+				finding.Severity = rule.severity
+				finding.Job = jobs[i].ID
+				findings = append(findings, finding)
+			}
+		}
+	}
+	sort.Stable(byJob(findings))
+	return findings
+}
+
+// Failed - whether any finding is at least as serious as threshold
+func Failed(findings []Finding, threshold Severity) bool {
+	for _, finding := range findings {
+		if finding.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+type byJob []Finding
+
+func (findings byJob) Len() int      { return len(findings) }
+func (findings byJob) Swap(i, j int) { findings[i], findings[j] = findings[j], findings[i] }
+func (findings byJob) Less(i, j int) bool {
+	a, b := findings[i], findings[j]
+	if a.Job != b.Job {
+		return a.Job < b.Job
+	}
+	if a.Rule != b.Rule {
+		return a.Rule < b.Rule
+	}
+	return a.Path < b.Path
+}
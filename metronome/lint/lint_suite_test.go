@@ -0,0 +1,13 @@
+package lint_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Lint Suite")
+}
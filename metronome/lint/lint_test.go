@@ -0,0 +1,207 @@
+package lint_test
+
+import (
+	met "github.com/adobe-platform/go-metronome/metronome"
+	. "github.com/adobe-platform/go-metronome/metronome/lint"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const jobsYAML = `
+id: data.reports
+labels:
+  owner: data
+run:
+  cpus: 1
+  mem: 8192
+  disk: 0
+  user: root
+  docker:
+    image: myorg/reports
+    privileged: true
+  restart:
+    policy: ON_FAILURE
+  volumes:
+  - containerPath: /logs
+    hostPath: /var/log/app
+    mode: RW
+  - containerPath: /etc/x
+    hostPath: /etc
+    mode: RO
+  - containerPath: /run/password
+    secret: password
+  secrets:
+    password:
+      source: databases/password
+  env:
+    MODE: fast
+    DEBUG: "1"
+---
+id: cleanup
+run:
+  cpus: 0.1
+  mem: 32
+  disk: 0
+  restart:
+    policy: ON_FAILURE
+    activeDeadlineSeconds: 60
+`
+
+const configYAML = `
+rules:
+  max-mem: {max: 16384}
+  no-host-volumes: {allow: [/var/log/]}
+  restart-deadline: {severity: warning}
+  required-labels: {disabled: true}
+custom:
+- name: team-prefix
+  path: id
+  pattern: ^(data|web)\.
+  severity: warning
+  message: job ids start with the team
+- name: no-privileged
+  path: run.docker.privileged
+  forbidden: [true]
+- name: env-values
+  path: run.env.*
+  allowed: [fast, slow]
+- name: small-cpus
+  path: run.cpus
+  max: 0.5
+- name: needs-image
+  path: run.docker.image
+  required: true
+`
+
+type finding struct {
+	rule     string
+	severity Severity
+	job      string
+	path     string
+}
+
+func summarize(findings []Finding) []finding {
+	out := make([]finding, len(findings))
+	for i, f := range findings {
+		out[i] = finding{f.Rule, f.Severity, f.Job, f.Path}
+	}
+	return out
+}
+
+var _ = Describe("Lint", func() {
+	var jobs []met.Job
+	BeforeEach(func() {
+		var err error
+		jobs, err = met.LoadJobs([]byte(jobsYAML))
+		Expect(err).ToNot(HaveOccurred())
+	})
+	It("Applies the built-in rules", func() {
+		findings := DefaultLinter().Lint(jobs...)
+		Expect(summarize(findings)).To(Equal([]finding{
+			{RuleRequiredLabels, SeverityError, "cleanup", "labels.owner"},
+			{RuleMaxMem, SeverityError, "data.reports", "run.mem"},
+			{RuleNoHostVolumes, SeverityError, "data.reports", "run.volumes[0].hostPath"},
+			{RuleNoHostVolumes, SeverityError, "data.reports", "run.volumes[1].hostPath"},
+			{RuleNoRootUser, SeverityError, "data.reports", "run.user"},
+			{RuleRestartDeadline, SeverityError, "data.reports", "run.restart.activeDeadlineSeconds"},
+		}))
+		Expect(findings[1].Message).To(Equal("8192 MiB is over the 4096 MiB limit"))
+		Expect(Failed(findings, SeverityError)).To(BeTrue())
+	})
+	It("Has no findings for a compliant job", func() {
+		job := jobs[1]
+		job.Labels = &met.Labels{"owner": "ops"}
+		Expect(DefaultLinter().Lint(job)).To(BeEmpty())
+	})
+	It("Flags root by uid and with a group", func() {
+		linter := DefaultLinter()
+		for _, user := range []string{"root", "0", "0:0", "root:root", "0:1000"} {
+			job := jobs[1]
+			job.Labels = &met.Labels{"owner": "ops"}
+			job.Run.User = user
+			Expect(summarize(linter.Lint(job))).To(Equal([]finding{
+				{RuleNoRootUser, SeverityError, "cleanup", "run.user"},
+			}), user)
+		}
+		for _, user := range []string{"", "nobody", "1000", "1000:0"} {
+			job := jobs[1]
+			job.Labels = &met.Labels{"owner": "ops"}
+			job.Run.User = user
+			Expect(linter.Lint(job)).To(BeEmpty(), user)
+		}
+	})
+	It("Configures built-in rules and adds custom rules", func() {
+		config, err := LoadConfig([]byte(configYAML))
+		Expect(err).ToNot(HaveOccurred())
+		linter, err := New(config)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(linter.Rules()).To(Equal([]string{
+			RuleNoRootUser, RuleMaxMem, RuleRestartDeadline, RuleNoHostVolumes,
+			"team-prefix", "no-privileged", "env-values", "small-cpus", "needs-image",
+		}))
+		findings := linter.Lint(jobs...)
+		Expect(summarize(findings)).To(Equal([]finding{
+			{"needs-image", SeverityError, "cleanup", "run.docker.image"},
+			{"team-prefix", SeverityWarning, "cleanup", "id"},
+			{"env-values", SeverityError, "data.reports", "run.env.DEBUG"},
+			{RuleNoHostVolumes, SeverityError, "data.reports", "run.volumes[1].hostPath"},
+			{"no-privileged", SeverityError, "data.reports", "run.docker.privileged"},
+			{RuleNoRootUser, SeverityError, "data.reports", "run.user"},
+			{RuleRestartDeadline, SeverityWarning, "data.reports", "run.restart.activeDeadlineSeconds"},
+			{"small-cpus", SeverityError, "data.reports", "run.cpus"},
+		}))
+		Expect(findings[1].Message).To(Equal("job ids start with the team"))
+		Expect(findings[2].Message).To(Equal(`must be one of ["fast","slow"]`))
+		Expect(findings[7].Message).To(Equal("must be at most 0.5"))
+	})
+	It("Matches every element of a list", func() {
+		linter, err := New(Config{Custom: []CustomRule{{Name: "mounts", Path: "run.volumes.*.containerPath", Pattern: "^/(logs|run)"}}})
+		Expect(err).ToNot(HaveOccurred())
+		findings := linter.Lint(jobs...)
+		Expect(summarize(findings)).To(ContainElement(finding{"mounts", SeverityError, "data.reports", "run.volumes[1].containerPath"}))
+		Expect(findings).To(HaveLen(7))
+	})
+	It("Adds rules written in Go", func() {
+		linter := (&Linter{}).Add(noCmd{}, SeverityWarning)
+		findings := linter.Lint(jobs...)
+		Expect(summarize(findings)).To(Equal([]finding{
+			{"no-cmd", SeverityWarning, "cleanup", "run.cmd"},
+			{"no-cmd", SeverityWarning, "data.reports", "run.cmd"},
+		}))
+		Expect(Failed(findings, SeverityError)).To(BeFalse())
+		Expect(Failed(findings, SeverityWarning)).To(BeTrue())
+	})
+	It("Rejects bad configs", func() {
+		bad := []Config{
+			{Rules: map[string]RuleConfig{"no-such-rule": {}}},
+			{Rules: map[string]RuleConfig{RuleMaxMem: {Severity: "fatal"}}},
+			{Custom: []CustomRule{{Path: "id", Required: true}}},
+			{Custom: []CustomRule{{Name: RuleMaxMem, Path: "id", Required: true}}},
+			{Custom: []CustomRule{{Name: "x", Required: true}}},
+			{Custom: []CustomRule{{Name: "x", Path: "id"}}},
+			{Custom: []CustomRule{{Name: "x", Path: "id", Pattern: "("}}},
+		}
+		for _, config := range bad {
+			_, err := New(config)
+			Expect(err).To(HaveOccurred(), "%+v", config)
+		}
+	})
+	It("Formats findings", func() {
+		finding := Finding{Rule: RuleNoRootUser, Severity: SeverityError, Job: "a", File: "a.yaml", Path: "run.user", Message: "must not run as root"}
+		Expect(finding.String()).To(Equal("error no-root-user a.yaml a run.user: must not run as root"))
+	})
+})
+
+type noCmd struct{}
+
+func (noCmd) Name() string {
+	return "no-cmd"
+}
+
+func (noCmd) Check(theJob *met.Job) []Finding {
+	if theJob.Run.Cmd == "" {
+		return []Finding{{Path: "run.cmd", Message: "cmd required"}}
+	}
+	return nil
+}
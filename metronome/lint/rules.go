@@ -0,0 +1,173 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Built-in rule names
+const (
+	RuleNoRootUser      = "no-root-user"
+	RuleMaxMem          = "max-mem"
+	RuleRequiredLabels  = "required-labels"
+	RuleRestartDeadline = "restart-deadline"
+	RuleNoHostVolumes   = "no-host-volumes"
+)
+
+// Built-in rule defaults
+const (
+	// DefaultMaxMem - max-mem's limit in MiB
+	DefaultMaxMem = 4096
+	// DefaultRequiredLabel - the label required-labels asks for
+	DefaultRequiredLabel = "owner"
+)
+
+// RuleConfig - settings for a built-in rule.  Max applies to max-mem, Labels to required-labels and Allow
+// (host paths and the directories under them) to no-host-volumes
+type RuleConfig struct {
+	Disabled bool     `json:"disabled,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	Max      float64  `json:"max,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	Allow    []string `json:"allow,omitempty"`
+}
+
+// ruleFunc - a Rule from a name and a check
+type ruleFunc struct {
+	name  string
+	check func(theJob *met.Job) []Finding
+}
+
+func (rule ruleFunc) Name() string {
+	return rule.name
+}
+
+func (rule ruleFunc) Check(theJob *met.Job) []Finding {
+	return rule.check(theJob)
+}
+
+type builtin struct {
+	name     string
+	severity Severity
+	make     func(settings RuleConfig) Rule
+}
+
+// builtins - the built-in rules in the order they run
+var builtins = []builtin{
+	{RuleNoRootUser, SeverityError, noRootUser},
+	{RuleMaxMem, SeverityError, maxMem},
+	{RuleRequiredLabels, SeverityError, requiredLabels},
+	{RuleRestartDeadline, SeverityError, restartDeadline},
+	{RuleNoHostVolumes, SeverityError, noHostVolumes},
+}
+
+func builtinRule(name string) (builtin, bool) {
+	for _, rule := range builtins {
+		if rule.name == name {
+			return rule, true
+		}
+	}
+	return builtin{}, false
+}
+
+// Builtins - the names of the built-in rules
+func Builtins() []string {
+	names := make([]string, len(builtins))
+	for i, rule := range builtins {
+		names[i] = rule.name
+	}
+	return names
+}
+
+// noRootUser - run.user isn't root, by name or uid 0, with or without a group
+func noRootUser(settings RuleConfig) Rule {
+	return ruleFunc{RuleNoRootUser, func(theJob *met.Job) []Finding {
+		if theJob.Run != nil && isRoot(theJob.Run.User) {
+			return []Finding{{Path: "run.user", Message: "must not run as root"}}
+		}
+		return nil
+	}}
+}
+
+// isRoot - user, as name, uid, name:group or uid:gid, is root
+func isRoot(user string) bool {
+	name := strings.SplitN(strings.TrimSpace(user), ":", 2)[0]
+	return name == "root" || name == "0"
+}
+
+// maxMem - run.mem is at most settings.Max MiB
+func maxMem(settings RuleConfig) Rule {
+	limit := settings.Max
+	if limit == 0 {
+		limit = DefaultMaxMem
+	}
+	return ruleFunc{RuleMaxMem, func(theJob *met.Job) []Finding {
+		if theJob.Run != nil && float64(theJob.Run.Mem) > limit {
+			return []Finding{{Path: "run.mem", Message: fmt.Sprintf("%d MiB is over the %v MiB limit", theJob.Run.Mem, limit)}}
+		}
+		return nil
+	}}
+}
+
+// requiredLabels - every label in settings.Labels is set and not empty
+func requiredLabels(settings RuleConfig) Rule {
+	names := settings.Labels
+	if len(names) == 0 {
+		names = []string{DefaultRequiredLabel}
+	}
+	return ruleFunc{RuleRequiredLabels, func(theJob *met.Job) []Finding {
+		var findings []Finding
+		for _, name := range names {
+			if theJob.Labels == nil || strings.TrimSpace((*theJob.Labels)[name]) == "" {
+				findings = append(findings, Finding{Path: "labels." + name, Message: "label required"})
+			}
+		}
+		return findings
+	}}
+}
+
+// restartDeadline - jobs restarted ON_FAILURE give up after activeDeadlineSeconds
+func restartDeadline(settings RuleConfig) Rule {
+	return ruleFunc{RuleRestartDeadline, func(theJob *met.Job) []Finding {
+		if theJob.Run != nil && theJob.Run.Restart != nil && theJob.Run.Restart.Policy == "ON_FAILURE" &&
+			theJob.Run.Restart.ActiveDeadlineSeconds <= 0 {
+			return []Finding{{Path: "run.restart.activeDeadlineSeconds", Message: "ON_FAILURE restarts need a deadline"}}
+		}
+		return nil
+	}}
+}
+
+// noHostVolumes - no host path volumes other than those settings.Allow lists.  Secret volumes are fine
+func noHostVolumes(settings RuleConfig) Rule {
+	return ruleFunc{RuleNoHostVolumes, func(theJob *met.Job) []Finding {
+		if theJob.Run == nil {
+			return nil
+		}
+		var findings []Finding
+		for i, vol := range theJob.Run.Volumes {
+			if vol.Secret != "" || allowedPath(vol.HostPath, settings.Allow) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Path:    fmt.Sprintf("run.volumes[%d].hostPath", i),
+				Message: fmt.Sprintf("host volume %s not allowed", vol.HostPath),
+			})
+		}
+		return findings
+	}}
+}
+
+// allowedPath - hostPath is one of allowed or under one of them
+func allowedPath(hostPath string, allowed []string) bool {
+	hostPath = path.Clean(hostPath)
+	for _, dir := range allowed {
+		dir = path.Clean(dir)
+		if hostPath == dir || strings.HasPrefix(hostPath, strings.TrimSuffix(dir, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
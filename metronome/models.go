@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"time"
 )
 
 var whitespaceRe = regexp.MustCompile(`\s+`)
 
-var errConstraintViol = errors.New("Bad constraint.  Must be EQ,LIKE,UNLIKE")
+var errConstraintViol = errors.New("Bad constraint.  Must be EQ,IS,LIKE,UNLIKE,IN,GROUP_BY,UNIQUE,MAX_PER")
 var errMountViol = errors.New("Mount point must designate RW,RO")
 var errContainerPathViol = errors.New("Bad container path.  Must match `^/[^/].*$`")
 
@@ -27,6 +29,8 @@ type Artifact struct {
 	Executable bool   `json:"executable"`
 	Extract    bool   `json:"extract"`
 	Cache      bool   `json:"cache"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra      Extra  `json:"-"`
 }
 // GetURI - return string copy
 func (theArtifact *Artifact) GetURI() string {
@@ -44,9 +48,14 @@ func (theArtifact *Artifact) ShouldExtract() bool {
 func (theArtifact *Artifact) ShouldCache() bool {
 	return theArtifact.Cache
 }
-// Docker - metronome limited docker image
+// Docker - run the job in a docker container
 type Docker struct {
-	Image string `json:"image"`
+	Image          string            `json:"image"`
+	ForcePullImage bool              `json:"forcePullImage,omitempty"`
+	Parameters     []DockerParameter `json:"parameters,omitempty"`
+	Privileged     bool              `json:"privileged,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra          Extra             `json:"-"`
 }
 
 // NewDockerImage  - create a new image
@@ -60,82 +69,99 @@ func NewDockerImage(image string) (*Docker, error) {
 func (docker *Docker) GetImage() string {
 	return docker.Image
 }
+// SetForcePullImage - pull the image even when the agent has it cached
+func (docker *Docker) SetForcePullImage(force bool) *Docker {
+	docker.ForcePullImage = force
+	return docker
+}
+// AddParameter - add a docker run command line parameter e.g. key "memory-swap" value "-1"
+func (docker *Docker) AddParameter(key string, value string) *Docker {
+	docker.Parameters = append(docker.Parameters, DockerParameter{Key: key, Value: value})
+	return docker
+}
+// SetPrivileged - run the container in privileged mode
+func (docker *Docker) SetPrivileged(privileged bool) *Docker {
+	docker.Privileged = privileged
+	return docker
+}
 
 // constraint support
 
-// Operator - constrain operator values
-type Operator int
+// Operator - constraint operator.  Operators this package doesn't know are kept as-is so jobs created
+// by newer Metronome versions still round trip
+type Operator string
 
 const (
-	// EQ - Valid Operator values
-	EQ Operator = 1 + iota
-	// LIKE - operator
-	LIKE
-	// UNLIKE - operator
-	UNLIKE
+	// EQ - attribute equals value. Deprecated in favor of IS
+	EQ Operator = "EQ"
+	// LIKE - attribute matches the value regex
+	LIKE Operator = "LIKE"
+	// UNLIKE - attribute doesn't match the value regex
+	UNLIKE Operator = "UNLIKE"
+	// IS - attribute equals value
+	IS Operator = "IS"
+	// IN - attribute is one of the comma separated values
+	IN Operator = "IN"
+	// GROUPBY - spread runs evenly across the attribute's values. value optionally gives the number of values
+	GROUPBY Operator = "GROUP_BY"
+	// UNIQUE - at most one run per attribute value.  Takes no value
+	UNIQUE Operator = "UNIQUE"
+	// MAXPER - at most value runs per attribute value
+	MAXPER Operator = "MAX_PER"
 )
 
-var constraintOperators = [...]string{
-	"EQ",
-	"LIKE",
-	"UNLIKE",
-}
+var constraintOperators = []Operator{EQ, LIKE, UNLIKE, IS, IN, GROUPBY, UNIQUE, MAXPER}
+
 // String - string rep of operator
-func (theOp *Operator) String() string {
-	return constraintOperators[int(*theOp) - 1]
+func (theOp Operator) String() string {
+	return string(theOp)
+}
+// Known - whether the operator is one this package understands
+func (theOp Operator) Known() bool {
+	for _, op := range constraintOperators {
+		if op == theOp {
+			return true
+		}
+	}
+	return false
 }
 func decodeOperator(op string) (Operator, error) {
-	switch op {
-	case "EQ":
-		return EQ, nil
-	case "LIKE":
-		return LIKE, nil
-	case "UNLIKE":
-		return UNLIKE, nil
-	default:
-		fmt.Printf("Operator.UnmarshallJSON - unknown value '%s'\n", op)
-		return -1, errConstraintViol
+	if theOp := Operator(strings.ToUpper(op)); theOp.Known() {
+		return theOp, nil
 	}
-
-}
-// UnmarshalJSON - hand json unmarshalling
-func (theOp *Operator) UnmarshalJSON(raw []byte) error {
-	var s string
-	if err := json.Unmarshal(raw, &s); err != nil {
-		return err
-	}
-	op, err := decodeOperator(s)
-	if err != nil {
-		return err
-	}
-	*theOp = op
-
-	return nil
-}
-// MarshalJSON - implement json interface for marshalling json
-func (theOp *Operator) MarshalJSON() ([]byte, error) {
-	s := theOp.String()
-	return json.Marshal(s)
-	//return []byte(fmt.Sprintf("\"%s\"", s)), nil
+	return "", errConstraintViol
 }
 // Constraint - Metronome constraint
 type Constraint struct {
 	Attribute string `json:"attribute"`
-	// operator is EQ, LIKE,UNLIKE
+	// operator is one of EQ, LIKE, UNLIKE, IS, IN, GROUP_BY, UNIQUE, MAX_PER
 	Operator  Operator `json:"operator"`
-	Value     string   `json:"value"`
+	Value     string   `json:"value,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra     Extra    `json:"-"`
 }
-// StrToConstraint - takes constraint as described in Metronome documentation
+// StrToConstraint - takes constraint as described in Metronome documentation e.g. `rack IS rack-1` or `hostname UNIQUE`
 func StrToConstraint(cli string) (*Constraint, error) {
-	args := whitespaceRe.Split(cli, -1)
-	if len(args) != 3 {
-		return nil, errors.New("Not enough constraint args `attribute` {EQ|LIKE} value")
+	args := whitespaceRe.Split(strings.TrimSpace(cli), -1)
+	if len(args) < 2 || len(args) > 3 {
+		return nil, errors.New("Constraints should be `attribute` {EQ|IS|LIKE|UNLIKE|IN|GROUP_BY|UNIQUE|MAX_PER} [value]")
 	}
 	op, err := decodeOperator(args[1])
 	if err != nil {
 		return nil, err
 	}
-	return NewConstraint(args[0], op, args[2])
+	var value string
+	if len(args) == 3 {
+		value = args[2]
+	}
+	constraint, err := NewConstraint(args[0], op, value)
+	if err != nil {
+		return nil, err
+	}
+	if err = constraint.Validate(); err != nil {
+		return nil, err
+	}
+	return constraint, nil
 }
 // NewConstraint - create Metronome constraint
 func NewConstraint(attribute string, op Operator, value string) (*Constraint, error) {
@@ -159,6 +185,8 @@ func (theConstraint *Constraint) GetValue() string {
 // Placement - Metronome placement
 type Placement struct {
 	Constraints []Constraint `json:"constraints"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra       Extra        `json:"-"`
 }
 // GetConstraints - return constraints
 func (thePlacement *Placement) GetConstraints() ([]Constraint, error) {
@@ -188,6 +216,9 @@ var mountModes = [...]string{
 }
 // String - stringrep
 func (mm MountMode) String() string {
+	if mm < RO || mm > RW {
+		return ""
+	}
 	return mountModes[int(mm) - 1]
 }
 
@@ -243,8 +274,8 @@ func (cp *ContainerPath) UnmarshalJSON(raw []byte) error {
 }
 // NewContainerPath - create a new container path that's checked for validity per Metronome's doc
 func NewContainerPath(path string) (self ContainerPath, err error) {
-	if _, err = regexp.MatchString("^/[^/].*$", path); err != nil {
-		return "", err
+	if !containerPathRe.MatchString(path) {
+		return "", errContainerPathViol
 	}
 	vg := ContainerPath(path)
 
@@ -258,40 +289,35 @@ type Volume struct {
 	HostPath      string        `json:"hostPath"`
 	// Values: RW,RO
 	Mode          MountMode `json:"mode"`
+	// Secret - name of one of Run.Secrets.  Secret volumes have no host path or mode
+	Secret        string `json:"secret,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra         Extra  `json:"-"`
 }
 // NewVolume - creates a new volume from raw strings
 func NewVolume(rawPath string, hostPath string, modestr string) (*Volume, error) {
-	mode, err := decodeMount(modestr);
-	if err != nil {
-		return nil, err
-	}
-	vol := Volume{Mode: mode, HostPath:hostPath}
-	// ensure valid path
-	cpath, err := NewContainerPath(rawPath);
-	if err != nil {
+	// an unknown mode decodes as -1 and is reported by Validate
+	mode, _ := decodeMount(modestr)
+	vol := Volume{ContainerPath: ContainerPath(rawPath), HostPath: hostPath, Mode: mode}
+	if err := vol.Validate(); err != nil {
 		return nil, err
 	}
-	vol.ContainerPath = cpath
-
-	if vol.HostPath == "" {
-		return nil, required("host path")
-	}
 	return &vol, nil
-
 }
 // Restart - structure representing a Metronome structure
 type Restart struct {
 	ActiveDeadlineSeconds int    `json:"activeDeadlineSeconds"`
 	Policy                string `json:"policy"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra                 Extra  `json:"-"`
 }
 // NewRestart - create a valid Restart policy
 func NewRestart(activeDeadlineSeconds int, policy string) (*Restart, error) {
-	if len(policy) == 0 {
-		return nil, required("length(Restart.policy)>0")
-	} else if !(policy == "NEVER" || policy == "ON_FAILURE") {
-		return nil, fmt.Errorf("Policy must be 'ON_FAILURE' or 'NEVER' not %s", policy)
+	restart := Restart{ActiveDeadlineSeconds: activeDeadlineSeconds, Policy: policy}
+	if err := restart.Validate(); err != nil {
+		return nil, err
 	}
-	return &Restart{ActiveDeadlineSeconds: activeDeadlineSeconds, Policy: policy}, nil
+	return &restart, nil
 }
 // Run - composite structure representing Metronone run
 type Run struct {
@@ -303,12 +329,18 @@ type Run struct {
 	Mem            int               `json:"mem"`
 	Disk           int               `json:"disk"`
 	Docker         *Docker           `json:"docker,omitempty"`
+	UCR            *UCR              `json:"ucr,omitempty"`
 	Env            map[string]string `json:"env,omitempty"`
+	// EnvSecrets - variables set from Secrets, marshalled into env as {"secret": name}
+	EnvSecrets     map[string]string `json:"-"`
 	MaxLaunchDelay int               `json:"maxLaunchDelay"`
 	Placement      *Placement        `json:"placement,omitempty"`
 	Restart        *Restart          `json:"restart,omitempty"`
 	User           string            `json:"user,omitempty"`
 	Volumes        []Volume         `json:"volumes"`
+	Secrets        map[string]SecretSource `json:"secrets,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra          Extra `json:"-"`
 }
 
 // GetArtifacts - accessor returning Artifacts
@@ -378,6 +410,15 @@ func (runner *Run) SetDocker(docker *Docker) *Run {
 	runner.Docker = docker
 	return runner
 }
+// GetUCR - accessor returning the universal container runtime settings if set
+func (runner *Run) GetUCR() *UCR {
+	return runner.UCR
+}
+// SetUCR - run in the universal container runtime instead of docker
+func (runner *Run) SetUCR(ucr *UCR) *Run {
+	runner.UCR = ucr
+	return runner
+}
 // GetEnv - return the current environment
 func (runner *Run) GetEnv() map[string]string {
 	return runner.Env
@@ -435,15 +476,6 @@ func (runner *Run) SetVolumes(vols []Volume) *Run {
 }
 // NewRun - create a run structure needed for a job
 func NewRun(cpus float64, mem int, disk int) (*Run, error) {
-	if mem <= 0 {
-		return nil, required("Run.memory")
-	}
-	if disk <= 0 {
-		return nil, required("Run.disk")
-	}
-	if cpus <= 0.0 {
-		return nil, required("Run.cpus")
-	}
 	vg := Run{
 		Artifacts: make([]Artifact, 0, 10),
 		Args: make([]string, 0, 0),
@@ -457,6 +489,11 @@ func NewRun(cpus float64, mem int, disk int) (*Run, error) {
 		Restart: nil,
 		Volumes: make([]Volume, 0, 0),
 	}
+	v := new(validator)
+	vg.validateResources("", v)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
 	return &vg, nil
 }
 // Labels - list of labels that get converted to environment variables on job
@@ -472,6 +509,8 @@ type Job struct {
 	ActiveRuns     [] *ActiveRun`json:"activeRuns,omitempty"`
 	History        *History `json:"history,omitempty"`
 	HistorySummary *HistorySummary `json:"historySummary,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra          Extra `json:"-"`
 }
 //NewJob - create a job checking for some required fields
 func NewJob(id string, description string, labels Labels, run *Run) (*Job, error) {
@@ -536,46 +575,62 @@ type Schedule struct {
 	StartingDeadlineSeconds int `json:"startingDeadlineSeconds"`
 	Timezone                string `json:"timezone"`
 	NextRunAt               string `json:"nextRunAt,omitempty"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra                   Extra `json:"-"`
 }
 // JobStatus - represents a metronome job status
 type JobStatus struct {
-	CompletedAt interface{} `json:"completedAt"`
-	CreatedAt   string `json:"createdAt"`
+	CompletedAt Timestamp `json:"completedAt"`
+	CreatedAt   Timestamp `json:"createdAt"`
 	ID          string `json:"id"`
 	JobID       string `json:"jobId"`
-	Status      string `json:"status"`
+	Status      RunStatus `json:"status"`
 	Tasks       [] TaskStatus `json:"tasks"`
 }
+// Duration - how long the run took, or has been running if it hasn't completed
+func (status *JobStatus) Duration() time.Duration {
+	return runDuration(status.CreatedAt, status.CompletedAt)
+}
 // Jobs - list of jobs
 type Jobs []Job
 
 // TaskStatus - status of currently running task representing job
 type TaskStatus struct {
 	ID        string `json:"id"`
-	StartedAt string `json:"startedAt"`
-	Status    string `json:"status"`
+	StartedAt Timestamp `json:"startedAt"`
+	Status    TaskState `json:"status"`
 }
 // HistoryStatus - history outcome of previous jobs
 type HistoryStatus struct {
 	ID         string `json:"id"`
-	CreatedAt  string `json:"createdAt"`
-	FinishedAt string `json:"finishedAt"`
+	CreatedAt  Timestamp `json:"createdAt"`
+	FinishedAt Timestamp `json:"finishedAt"`
+	// Status - not sent by metronome.  Set to SUCCESS or FAILED by History.Runs
+	Status     RunStatus `json:"status,omitempty"`
+}
+// Duration - how long the finished run took
+func (status *HistoryStatus) Duration() time.Duration {
+	return runDuration(status.CreatedAt, status.FinishedAt)
 }
 // ActiveRun - undocumented structure returned via api for job runs
 type ActiveRun struct {
 	ID          string `json:"id"`
 	JobID       string `json:"jobId"`
-	Status      string `json:"status"`
-	CreatedAt   string `json:"createdAt"`
-	CompletedAt interface{} `json:"completedAt"`
+	Status      RunStatus `json:"status"`
+	CreatedAt   Timestamp `json:"createdAt"`
+	CompletedAt Timestamp `json:"completedAt"`
 	Tasks       []TaskStatus `json:"tasks"`
 }
+// Duration - how long the run took, or has been running if it hasn't completed
+func (run *ActiveRun) Duration() time.Duration {
+	return runDuration(run.CreatedAt, run.CompletedAt)
+}
 // History - undocumented structure returned by Metronome api for job runs
 type History struct {
 	SuccessCount           int `json:"successCount"`
 	FailureCount           int `json:"failureCount"`
-	LastSuccessAt          string `json:"lastSuccessAt"`
-	LastFailureAt          string `json:"lastFailureAt"`
+	LastSuccessAt          Timestamp `json:"lastSuccessAt"`
+	LastFailureAt          Timestamp `json:"lastFailureAt"`
 	SuccessfulFinishedRuns [] HistoryStatus `json:"successfulFinishedRuns"`
 	FailedFinishedRuns     [] HistoryStatus `json:"failedFinishedRuns"`
 }
@@ -583,6 +638,64 @@ type History struct {
 type HistorySummary struct {
 	SuccessCount  int `json:"successCount"`
 	FailureCount  int `json:"failureCount"`
-	LastSuccessAt string `json:"lastSuccessAt"`
-	LastFailureAt string `json:"lastFailureAt"`
-}
\ No newline at end of file
+	LastSuccessAt Timestamp `json:"lastSuccessAt"`
+	LastFailureAt Timestamp `json:"lastFailureAt"`
+}
+
+// Embed - extra detail Metronome can embed in a job read via the `embed` query parameter
+type Embed string
+
+const (
+	// EmbedHistory - every finished run (can be large)
+	EmbedHistory Embed = "history"
+	// EmbedHistorySummary - success/failure counts and last success/failure times
+	EmbedHistorySummary Embed = "historySummary"
+	// EmbedActiveRuns - runs currently in progress
+	EmbedActiveRuns Embed = "activeRuns"
+	// EmbedSchedules - the job's schedules
+	EmbedSchedules Embed = "schedules"
+)
+
+var embeds = [...]Embed{
+	EmbedHistory,
+	EmbedHistorySummary,
+	EmbedActiveRuns,
+	EmbedSchedules,
+}
+
+// ParseEmbed - convert a string into an Embed, rejecting values Metronome doesn't know
+func ParseEmbed(embed string) (Embed, error) {
+	for _, known := range embeds {
+		if string(known) == embed {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown embed '%s'.  Must be one of history,historySummary,activeRuns,schedules", embed)
+}
+
+// JobQuery - options for job reads.  Embed selects exactly which extra detail Metronome returns with each job
+type JobQuery struct {
+	Embed []Embed
+}
+
+// DefaultJobQuery - a new query for the detail GetJob embeds
+func DefaultJobQuery() *JobQuery {
+	return &JobQuery{Embed: []Embed{EmbedHistorySummary, EmbedActiveRuns, EmbedSchedules}}
+}
+
+// DefaultJobsQuery - a new query for the detail Jobs embeds
+func DefaultJobsQuery() *JobQuery {
+	return &JobQuery{Embed: []Embed{EmbedHistorySummary, EmbedActiveRuns}}
+}
+
+// queryParams - render the query as uri parameters
+func (query *JobQuery) queryParams() map[string][]string {
+	if query == nil || len(query.Embed) == 0 {
+		return nil
+	}
+	embed := make([]string, 0, len(query.Embed))
+	for _, e := range query.Embed {
+		embed = append(embed, string(e))
+	}
+	return map[string][]string{"embed": embed}
+}
@@ -0,0 +1,310 @@
+// Package monitor checks jobs against their schedules and service levels: schedules that should have started a run
+// but didn't, runs going on longer than the job expects and jobs whose last success is older than their SLA.
+//
+// The expectations come from job labels, MaxDurationLabel and SLALabel, written as durations such as 45m or 26h.
+// Violations are reported through Notifiers; Monitor reports each one once, until it clears.
+package monitor
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/backfill"
+)
+
+// Job labels read by Check
+const (
+	// SLALabel - the most time allowed since the job's last successful run, e.g. 26h
+	SLALabel = "sla"
+	// MaxDurationLabel - the most time a run is expected to take, e.g. 45m
+	MaxDurationLabel = "max-duration"
+)
+
+// Kind - what a violation is about
+type Kind string
+
+// Violation kinds
+const (
+	// KindMissedStart - a schedule fired but no run was created
+	KindMissedStart Kind = "missed-start"
+	// KindOverdueRun - an active run has taken longer than the job's max-duration
+	KindOverdueRun Kind = "overdue-run"
+	// KindStaleSuccess - the last successful run is older than the job's sla, or there is none
+	KindStaleSuccess Kind = "stale-success"
+	// KindInvalid - a label or schedule that can't be checked
+	KindInvalid Kind = "invalid"
+)
+
+// Defaults for Options
+const (
+	// DefaultWindow - how far back fire times are checked for runs
+	DefaultWindow = 24 * time.Hour
+	// DefaultGrace - how long after a fire time its run may be created
+	DefaultGrace = 5 * time.Minute
+)
+
+// Violation - one broken expectation
+type Violation struct {
+	Kind     Kind   `json:"kind"`
+	Job      string `json:"job"`
+	Schedule string `json:"schedule,omitempty"`
+	RunID    string `json:"runId,omitempty"`
+	// At - the missed fire time, the overdue run's creation or the last success.  Zero when there is none
+	At      time.Time `json:"at"`
+	Message string    `json:"message"`
+}
+
+// String - kind, job and message
+func (violation Violation) String() string {
+	return fmt.Sprintf("%s %s: %s", violation.Kind, violation.Job, violation.Message)
+}
+
+// Key - identifies the violation across checks.  A missed start or overdue run keeps its key until it clears; a
+// stale success changes key with each new last success
+func (violation Violation) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d", violation.Kind, violation.Job, violation.Schedule, violation.RunID, violation.At.Unix())
+}
+
+// Options - how Check looks at schedules
+type Options struct {
+	// Window - how far back fire times are checked.  Defaults to DefaultWindow
+	Window time.Duration
+	// Grace - how long after a fire time its run may be created.  Fire times closer to now than Grace aren't
+	// checked yet.  Defaults to DefaultGrace
+	Grace time.Duration
+}
+
+func (options Options) window() time.Duration {
+	if options.Window <= 0 {
+		return DefaultWindow
+	}
+	return options.Window
+}
+
+func (options Options) grace() time.Duration {
+	if options.Grace <= 0 {
+		return DefaultGrace
+	}
+	return options.Grace
+}
+
+// Check - the violations of job at now.  The job should embed its schedules, active runs and history or history
+// summary.  With the history every fire time in the window is matched with a run; with only the summary, just the
+// latest fire time is checked against the last run's outcome time.  Metronome keeps a bounded number of finished
+// runs, so fire times before the oldest run in the history aren't checked.  Runs of backfill jobs aren't counted;
+// Monitor.Check counts them
+func Check(job *met.Job, now time.Time, options Options) []Violation {
+	var violations []Violation
+	violations = append(violations, missedStarts(job, now, options)...)
+	violations = append(violations, overdueRuns(job, now)...)
+	violations = append(violations, staleSuccess(job, now)...)
+	sort.Stable(byJob(violations))
+	return violations
+}
+
+func missedStarts(job *met.Job, now time.Time, options Options) []Violation {
+	var violations []Violation
+	grace := options.grace()
+	for _, sched := range job.Schedules {
+		if sched == nil || !sched.Enabled {
+			continue
+		}
+		from := now.Add(-options.window())
+		if oldest := oldestRun(job); !oldest.IsZero() && oldest.Add(-grace).After(from) {
+			// older fire times' runs may have dropped out of the history
+			from = oldest.Add(-grace)
+		}
+		fires, err := backfill.FireTimes([]*met.Schedule{sched}, "", from, now.Add(-grace))
+		if err != nil {
+			violations = append(violations, Violation{Kind: KindInvalid, Job: job.ID, Schedule: sched.ID, Message: err.Error()})
+			continue
+		}
+		if len(fires) == 0 {
+			continue
+		}
+		if job.History != nil {
+			backfill.Match(fires, runs(job), grace)
+		} else {
+			// the summary only says when the last runs finished, so check the latest fire time
+			fires = fires[len(fires)-1:]
+			if ranSince(job, fires[0].Time) {
+				fires[0].RunID = "-"
+			}
+		}
+		for _, fire := range backfill.Missing(fires) {
+			if sched.ConcurrencyPolicy == "FORBID" && busy(job, fire.Time) {
+				// Metronome skips a FORBID schedule's fire time while a run is going
+				continue
+			}
+			violations = append(violations, Violation{
+				Kind:     KindMissedStart,
+				Job:      job.ID,
+				Schedule: sched.ID,
+				At:       fire.Time,
+				Message:  fmt.Sprintf("schedule %s fired at %s but no run started within %s", sched.ID, fire.Time.Format(time.RFC3339), grace),
+			})
+		}
+	}
+	return violations
+}
+
+// runs - the job's finished and active runs
+func runs(job *met.Job) []met.HistoryStatus {
+	var all []met.HistoryStatus
+	if job.History != nil {
+		all = job.History.Runs()
+	}
+	for _, run := range job.ActiveRuns {
+		if run != nil {
+			all = append(all, met.HistoryStatus{ID: run.ID, CreatedAt: run.CreatedAt, Status: run.Status})
+		}
+	}
+	return all
+}
+
+// oldestRun - when the oldest run in the job's history was created.  Zero without one
+func oldestRun(job *met.Job) time.Time {
+	var oldest time.Time
+	if job.History != nil {
+		for _, run := range job.History.Runs() {
+			if !run.CreatedAt.IsZero() && (oldest.IsZero() || run.CreatedAt.Before(oldest)) {
+				oldest = run.CreatedAt.Time
+			}
+		}
+	}
+	return oldest
+}
+
+// busy - whether a run of the job was going at at
+func busy(job *met.Job, at time.Time) bool {
+	for _, run := range job.ActiveRuns {
+		if run != nil && run.CreatedAt.Before(at) {
+			return true
+		}
+	}
+	if job.History != nil {
+		for _, run := range job.History.Runs() {
+			if run.CreatedAt.Before(at) && run.FinishedAt.After(at) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ranSince - whether, by the history summary and active runs, a run was created or finished at or after at
+func ranSince(job *met.Job, at time.Time) bool {
+	for _, run := range job.ActiveRuns {
+		if run != nil && !run.CreatedAt.Before(at) {
+			return true
+		}
+	}
+	if summary := job.HistorySummary; summary != nil {
+		return !summary.LastSuccessAt.Before(at) || !summary.LastFailureAt.Before(at)
+	}
+	return false
+}
+
+func overdueRuns(job *met.Job, now time.Time) []Violation {
+	max, violation := labelDuration(job, MaxDurationLabel)
+	if violation != nil {
+		return []Violation{*violation}
+	} else if max == 0 {
+		return nil
+	}
+	var violations []Violation
+	for _, run := range job.ActiveRuns {
+		if run == nil || run.CreatedAt.IsZero() || run.Status.IsTerminal() {
+			continue
+		}
+		if took := now.Sub(run.CreatedAt.Time); took > max {
+			violations = append(violations, Violation{
+				Kind:    KindOverdueRun,
+				Job:     job.ID,
+				RunID:   run.ID,
+				At:      run.CreatedAt.Time,
+				Message: fmt.Sprintf("run %s has taken %s, more than its %s %s", run.ID, seconds(took), MaxDurationLabel, max),
+			})
+		}
+	}
+	return violations
+}
+
+func staleSuccess(job *met.Job, now time.Time) []Violation {
+	sla, violation := labelDuration(job, SLALabel)
+	if violation != nil {
+		return []Violation{*violation}
+	} else if sla == 0 {
+		return nil
+	}
+	last := lastSuccess(job)
+	if last.IsZero() {
+		return []Violation{{Kind: KindStaleSuccess, Job: job.ID, Message: fmt.Sprintf("no successful run; %s %s", SLALabel, sla)}}
+	}
+	if age := now.Sub(last); age > sla {
+		return []Violation{{
+			Kind:    KindStaleSuccess,
+			Job:     job.ID,
+			At:      last,
+			Message: fmt.Sprintf("last success %s ago at %s, more than its %s %s", seconds(age), last.Format(time.RFC3339), SLALabel, sla),
+		}}
+	}
+	return nil
+}
+
+// lastSuccess - when the last successful run finished, by the history summary or the history
+func lastSuccess(job *met.Job) time.Time {
+	var last time.Time
+	if job.HistorySummary != nil {
+		last = job.HistorySummary.LastSuccessAt.Time
+	}
+	if job.History != nil {
+		if job.History.LastSuccessAt.After(last) {
+			last = job.History.LastSuccessAt.Time
+		}
+		for _, run := range job.History.SuccessfulFinishedRuns {
+			if run.FinishedAt.After(last) {
+				last = run.FinishedAt.Time
+			}
+		}
+	}
+	return last
+}
+
+// labelDuration - the duration in the job's label.  Zero when the label isn't set
+func labelDuration(job *met.Job, label string) (time.Duration, *Violation) {
+	if job.Labels == nil {
+		return 0, nil
+	}
+	value, ok := (*job.Labels)[label]
+	if !ok {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, &Violation{Kind: KindInvalid, Job: job.ID, Message: fmt.Sprintf("label %s: '%s' isn't a positive duration such as 26h", label, value)}
+	}
+	return duration, nil
+}
+
+// seconds - d to the second
+func seconds(d time.Duration) time.Duration {
+	return d / time.Second * time.Second
+}
+
+// byJob - sort.Interface ordering violations by job, then kind, then time
+type byJob []Violation
+
+func (violations byJob) Len() int      { return len(violations) }
+func (violations byJob) Swap(i, j int) { violations[i], violations[j] = violations[j], violations[i] }
+func (violations byJob) Less(i, j int) bool {
+	a, b := violations[i], violations[j]
+	if a.Job != b.Job {
+		return a.Job < b.Job
+	} else if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	return a.At.Before(b.At)
+}
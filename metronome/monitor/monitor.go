@@ -0,0 +1,145 @@
+package monitor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/backfill"
+	log "github.com/behance/go-logrus"
+)
+
+// Query - a new query for the detail Check needs embedded in each job
+func Query() *met.JobQuery {
+	return &met.JobQuery{Embed: []met.Embed{met.EmbedSchedules, met.EmbedActiveRuns, met.EmbedHistory}}
+}
+
+// Monitor - checks Metronome's jobs and tells the notifiers about new violations
+type Monitor struct {
+	Client  met.Metronome
+	Options Options
+	// JobIDs - the jobs to check.  Empty checks every job
+	JobIDs    []string
+	Notifiers []Notifier
+
+	mutex    sync.Mutex
+	reported map[string]bool
+}
+
+// Check - the violations of the monitored jobs now, sorted by job.  Fire times a backfill job has a successful or
+// active run for aren't missed; the backfill jobs themselves aren't checked
+func (monitor *Monitor) Check() ([]Violation, error) {
+	var jobs, all []met.Job
+	if len(monitor.JobIDs) == 0 {
+		queried, err := monitor.Client.QueryJobs(Query())
+		if err != nil {
+			return nil, err
+		}
+		jobs, all = *queried, *queried
+	} else {
+		for _, id := range monitor.JobIDs {
+			job, err := monitor.Client.QueryJob(id, Query())
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, *job)
+		}
+	}
+	now := time.Now()
+	violations := []Violation{}
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Labels != nil && (*job.Labels)[backfill.BackfillOfLabel] != "" {
+			continue
+		}
+		found := Check(job, now, monitor.Options)
+		if all == nil && missed(found) {
+			queried, err := monitor.Client.QueryJobs(backfill.Query())
+			if err != nil {
+				return nil, err
+			}
+			all = *queried
+		}
+		violations = append(violations, withoutBackfilled(found, backfill.Backfilled(job.ID, all))...)
+	}
+	sort.Stable(byJob(violations))
+	return violations, nil
+}
+
+// missed - whether any of the violations is a missed start
+func missed(violations []Violation) bool {
+	for _, violation := range violations {
+		if violation.Kind == KindMissedStart {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutBackfilled - violations less the missed starts of fire times in backfilled
+func withoutBackfilled(violations []Violation, backfilled []backfill.FireTime) []Violation {
+	var out []Violation
+	for _, violation := range violations {
+		done := false
+		for _, fire := range backfilled {
+			if violation.Kind == KindMissedStart && violation.Schedule == fire.Schedule && violation.At.Equal(fire.Time) {
+				done = true
+			}
+		}
+		if !done {
+			out = append(out, violation)
+		}
+	}
+	return out
+}
+
+// Notify - tell every notifier about the violations not reported by an earlier Notify.  A violation missing from
+// violations has cleared and is reported again should it come back.  Every notifier is tried; the first error is
+// returned
+func (monitor *Monitor) Notify(violations []Violation) error {
+	monitor.mutex.Lock()
+	current := make(map[string]bool, len(violations))
+	var fresh []Violation
+	for _, violation := range violations {
+		key := violation.Key()
+		current[key] = true
+		if !monitor.reported[key] {
+			fresh = append(fresh, violation)
+		}
+	}
+	monitor.reported = current
+	monitor.mutex.Unlock()
+	if len(fresh) == 0 {
+		return nil
+	}
+	var first error
+	for _, notifier := range monitor.Notifiers {
+		if err := notifier.Notify(fresh); err != nil {
+			log.Errorf("notify failed: %s", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Run - check and notify now and every interval until stop is closed
+func (monitor *Monitor) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if violations, err := monitor.Check(); err != nil {
+			log.Errorf("check failed: %s", err)
+		} else {
+			log.Debugf("%d violations", len(violations))
+			monitor.Notify(violations)
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package monitor_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/backfill"
+	. "github.com/adobe-platform/go-metronome/metronome/monitor"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	Expect(err).ToNot(HaveOccurred())
+	return t
+}
+
+func stamp(value string) met.Timestamp {
+	return met.Timestamp{Time: at(value)}
+}
+
+// nightly - a job scheduled at 02:00 UTC that ran on the 17th but not the 18th
+func nightly() *met.Job {
+	return &met.Job{
+		ID:  "reports",
+		Run: &met.Run{Cpus: 0.5, Mem: 128},
+		Schedules: []*met.Schedule{
+			{ID: "nightly", Cron: "0 2 * * *", Enabled: true, ConcurrencyPolicy: "ALLOW"},
+			{ID: "off", Cron: "* * * * *", Enabled: false},
+		},
+		History: &met.History{
+			SuccessfulFinishedRuns: []met.HistoryStatus{
+				{ID: "r17", CreatedAt: stamp("2026-10-17T02:00:02Z"), FinishedAt: stamp("2026-10-17T02:40:00Z")},
+			},
+		},
+	}
+}
+
+func kinds(violations []Violation) []Kind {
+	var all []Kind
+	for _, violation := range violations {
+		all = append(all, violation.Kind)
+	}
+	return all
+}
+
+var now = "2026-10-18T10:00:00Z"
+
+var _ = Describe("Check", func() {
+	It("finds fire times without a run", func() {
+		violations := Check(nightly(), at(now), Options{Window: 36 * time.Hour})
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Kind).To(Equal(KindMissedStart))
+		Expect(violations[0].Schedule).To(Equal("nightly"))
+		Expect(violations[0].At).To(Equal(at("2026-10-18T02:00:00Z")))
+	})
+	It("counts active runs and leaves fire times within the grace alone", func() {
+		job := nightly()
+		job.ActiveRuns = []*met.ActiveRun{{ID: "r18", CreatedAt: stamp("2026-10-18T02:01:00Z"), Status: met.RunActive}}
+		Expect(Check(job, at(now), Options{Window: 36 * time.Hour})).To(BeEmpty())
+		Expect(Check(nightly(), at("2026-10-18T02:03:00Z"), Options{})).To(BeEmpty())
+	})
+	It("doesn't expect FORBID schedules to start while a run is going", func() {
+		job := nightly()
+		job.Schedules[0].ConcurrencyPolicy = "FORBID"
+		job.History.SuccessfulFinishedRuns[0].FinishedAt = stamp("2026-10-18T03:00:00Z")
+		Expect(Check(job, at(now), Options{Window: 36 * time.Hour})).To(BeEmpty())
+	})
+	It("checks the latest fire time against the history summary", func() {
+		job := nightly()
+		job.History = nil
+		job.HistorySummary = &met.HistorySummary{LastFailureAt: stamp("2026-10-18T02:20:00Z")}
+		Expect(Check(job, at(now), Options{})).To(BeEmpty())
+		job.HistorySummary.LastFailureAt = stamp("2026-10-17T02:20:00Z")
+		Expect(kinds(Check(job, at(now), Options{}))).To(Equal([]Kind{KindMissedStart}))
+	})
+	It("doesn't check fire times older than the history Metronome keeps", func() {
+		job := nightly()
+		job.Schedules = []*met.Schedule{{ID: "often", Cron: "*/5 * * * *", Enabled: true}}
+		job.History = &met.History{
+			SuccessfulFinishedRuns: []met.HistoryStatus{
+				{ID: "r3", CreatedAt: stamp("2026-10-18T09:50:01Z")},
+				{ID: "r2", CreatedAt: stamp("2026-10-18T09:45:01Z")},
+			},
+			FailedFinishedRuns: []met.HistoryStatus{{ID: "r1", CreatedAt: stamp("2026-10-18T09:40:02Z")}},
+		}
+		violations := Check(job, at("2026-10-18T10:01:00Z"), Options{})
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].At).To(Equal(at("2026-10-18T09:55:00Z")))
+	})
+	It("finds runs over the job's max-duration", func() {
+		job := nightly()
+		job.Schedules = nil
+		job.Labels = &met.Labels{MaxDurationLabel: "1h"}
+		job.ActiveRuns = []*met.ActiveRun{
+			{ID: "slow", CreatedAt: stamp("2026-10-18T08:30:00Z"), Status: met.RunActive},
+			{ID: "fine", CreatedAt: stamp("2026-10-18T09:30:00Z"), Status: met.RunActive},
+		}
+		violations := Check(job, at(now), Options{})
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].RunID).To(Equal("slow"))
+		Expect(violations[0].Message).To(ContainSubstring("1h30m0s"))
+	})
+	It("finds last successes older than the job's sla", func() {
+		job := nightly()
+		job.Schedules = nil
+		job.Labels = &met.Labels{SLALabel: "26h"}
+		Expect(Check(job, at("2026-10-18T04:00:00Z"), Options{})).To(BeEmpty())
+		violations := Check(job, at("2026-10-18T05:00:00Z"), Options{})
+		Expect(kinds(violations)).To(Equal([]Kind{KindStaleSuccess}))
+		Expect(violations[0].At).To(Equal(at("2026-10-17T02:40:00Z")))
+
+		job.History = nil
+		job.HistorySummary = &met.HistorySummary{LastSuccessAt: stamp("2026-10-18T02:30:00Z")}
+		Expect(Check(job, at("2026-10-19T04:00:00Z"), Options{})).To(BeEmpty())
+		job.HistorySummary = nil
+		Expect(Check(job, at(now), Options{})[0].Message).To(ContainSubstring("no successful run"))
+	})
+	It("reports labels it can't read", func() {
+		job := nightly()
+		job.Schedules = nil
+		job.Labels = &met.Labels{SLALabel: "daily", MaxDurationLabel: "-1h"}
+		Expect(kinds(Check(job, at(now), Options{}))).To(Equal([]Kind{KindInvalid, KindInvalid}))
+	})
+})
+
+// fakeMetronome - serves jobs for QueryJobs and QueryJob
+type fakeMetronome struct {
+	met.Metronome
+	jobs []met.Job
+}
+
+func (fake *fakeMetronome) QueryJobs(query *met.JobQuery) (*[]met.Job, error) {
+	jobs := append([]met.Job{}, fake.jobs...)
+	return &jobs, nil
+}
+
+func (fake *fakeMetronome) QueryJob(jobID string, query *met.JobQuery) (*met.Job, error) {
+	for i := range fake.jobs {
+		if fake.jobs[i].ID == jobID {
+			return &fake.jobs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+var _ = Describe("Monitor", func() {
+	It("checks every job or the ones asked for", func() {
+		other := nightly()
+		other.ID = "cleanup"
+		other.Schedules = nil
+		fake := &fakeMetronome{jobs: []met.Job{*nightly(), *other}}
+		monitor := &Monitor{Client: fake, Options: Options{Window: 240 * time.Hour}}
+		violations, err := monitor.Check()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(violations).ToNot(BeEmpty())
+		monitor.JobIDs = []string{"cleanup"}
+		Expect(monitor.Check()).To(BeEmpty())
+	})
+	It("counts the runs of backfill jobs and doesn't check those jobs", func() {
+		job := nightly()
+		job.History = &met.History{}
+		job.Labels = &met.Labels{SLALabel: "1h"}
+		fires, err := backfill.FireTimes(job.Schedules, "", time.Now().Add(-48*time.Hour), time.Now().Add(-DefaultGrace))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fires).To(HaveLen(2))
+		done, failed := backfill.Job(job, fires[0]), backfill.Job(job, fires[1])
+		done.History = &met.History{SuccessfulFinishedRuns: []met.HistoryStatus{{ID: "b1"}}}
+		failed.History = &met.History{FailedFinishedRuns: []met.HistoryStatus{{ID: "b2"}}}
+		fake := &fakeMetronome{jobs: []met.Job{*job, *done, *failed}}
+		monitor := &Monitor{Client: fake, Options: Options{Window: 48 * time.Hour}}
+		for _, ids := range [][]string{nil, {"reports"}} {
+			monitor.JobIDs = ids
+			violations, err := monitor.Check()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kinds(violations)).To(Equal([]Kind{KindMissedStart, KindStaleSuccess}))
+			Expect(violations[0].Job).To(Equal("reports"))
+			Expect(violations[0].At).To(Equal(fires[1].Time))
+			Expect(violations[1].Job).To(Equal("reports"))
+		}
+	})
+	It("notifies each violation once until it clears", func() {
+		var notified [][]Violation
+		monitor := &Monitor{Notifiers: []Notifier{NotifierFunc(func(violations []Violation) error {
+			notified = append(notified, violations)
+			return nil
+		})}}
+		a := Violation{Kind: KindMissedStart, Job: "a", At: at(now)}
+		b := Violation{Kind: KindOverdueRun, Job: "b", RunID: "r1"}
+		Expect(monitor.Notify([]Violation{a})).To(Succeed())
+		Expect(monitor.Notify([]Violation{a, b})).To(Succeed())
+		Expect(monitor.Notify([]Violation{b})).To(Succeed())
+		Expect(monitor.Notify([]Violation{a, b})).To(Succeed())
+		Expect(notified).To(Equal([][]Violation{{a}, {b}, {a}}))
+	})
+})
+
+var _ = Describe("Notifiers", func() {
+	violations := []Violation{{Kind: KindStaleSuccess, Job: "reports", Message: "no successful run; sla 26h0m0s"}}
+	It("write json lines", func() {
+		buf := new(bytes.Buffer)
+		Expect((&WriterNotifier{Writer: buf}).Notify(violations)).To(Succeed())
+		Expect(buf.String()).To(HavePrefix(`{"kind":"stale-success","job":"reports",`))
+	})
+	It("post to webhooks", func() {
+		var received map[string][]Violation
+		status := http.StatusOK
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			json.NewDecoder(request.Body).Decode(&received)
+			writer.WriteHeader(status)
+		}))
+		defer server.Close()
+		webhook := &WebhookNotifier{URL: server.URL}
+		Expect(webhook.Notify(violations)).To(Succeed())
+		Expect(received["violations"]).To(Equal(violations))
+		status = http.StatusBadGateway
+		Expect(webhook.Notify(violations)).ToNot(Succeed())
+	})
+})
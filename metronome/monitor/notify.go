@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	log "github.com/behance/go-logrus"
+)
+
+// Notifier - told about new violations
+type Notifier interface {
+	Notify(violations []Violation) error
+}
+
+// NotifierFunc - a function as a Notifier
+type NotifierFunc func(violations []Violation) error
+
+// Notify - Notifier implementation
+func (notify NotifierFunc) Notify(violations []Violation) error {
+	return notify(violations)
+}
+
+// LogNotifier - logs each violation as a warning
+type LogNotifier struct{}
+
+// Notify - Notifier implementation
+func (LogNotifier) Notify(violations []Violation) error {
+	for _, violation := range violations {
+		log.Warnf("%s", violation)
+	}
+	return nil
+}
+
+// WriterNotifier - writes each violation to Writer as a line of json
+type WriterNotifier struct {
+	Writer io.Writer
+}
+
+// Notify - Notifier implementation
+func (notifier *WriterNotifier) Notify(violations []Violation) error {
+	encoder := json.NewEncoder(notifier.Writer)
+	for _, violation := range violations {
+		if err := encoder.Encode(violation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WebhookNotifier - posts {"violations": [...]} as json to URL
+type WebhookNotifier struct {
+	URL string
+	// Client - defaults to a client with a 30 second timeout
+	Client *http.Client
+}
+
+// Notify - Notifier implementation.  A response other than 2xx is an error
+func (notifier *WebhookNotifier) Notify(violations []Violation) error {
+	body, err := json.Marshal(map[string][]Violation{"violations": violations})
+	if err != nil {
+		return err
+	}
+	client := notifier.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	response, err := client.Post(notifier.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook %s answered %s", notifier.URL, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package reconcile
+
+import (
+	"fmt"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// Result - an action and, when it failed, why
+type Result struct {
+	Action
+	Error string `json:"error,omitempty"`
+}
+
+// Apply - carry out the actions in order.  A job that fails to be created has its schedule actions skipped; other
+// failures don't stop the rest.  Returns every action's result
+func Apply(client met.Metronome, actions []Action) []Result {
+	results := make([]Result, 0, len(actions))
+	failed := make(map[string]bool)
+	for _, action := range actions {
+		result := Result{Action: action}
+		if action.SchedID != "" && failed[action.JobID] {
+			result.Error = fmt.Sprintf("skipped; job %s wasn't created", action.JobID)
+		} else if err := apply(client, action); err != nil {
+			result.Error = err.Error()
+			if action.Type == CreateJob {
+				failed[action.JobID] = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func apply(client met.Metronome, action Action) error {
+	var err error
+	switch action.Type {
+	case CreateJob:
+		_, err = client.CreateJob(action.job)
+	case UpdateJob:
+		_, err = client.UpdateJob(action.JobID, action.job)
+	case DeleteJob:
+		_, err = client.DeleteJob(action.JobID)
+	case CreateSchedule:
+		_, err = client.CreateSchedule(action.JobID, action.schedule)
+	case UpdateSchedule:
+		_, err = client.UpdateSchedule(action.JobID, action.SchedID, action.schedule)
+	case DeleteSchedule:
+		_, err = client.DeleteSchedule(action.JobID, action.SchedID)
+	default:
+		err = fmt.Errorf("unknown action %s", action.Type)
+	}
+	return err
+}
+
+// Failed - the results with errors
+func Failed(results []Result) []Result {
+	var failed []Result
+	for _, result := range results {
+		if result.Error != "" {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
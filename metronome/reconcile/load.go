@@ -0,0 +1,93 @@
+package reconcile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/jobspec"
+)
+
+// specExtensions - the files LoadDir reads
+var specExtensions = []string{".json", ".yaml", ".yml"}
+
+// LoadDir - the jobs defined by the .json, .yaml and .yml files under dir.  Hidden files and directories such as
+// .git are skipped.  When render has values, an environment or overlays each file is rendered as a job spec
+// template (see jobspec); render's own files are skipped when they are under dir.  A job id defined twice is an error
+func LoadDir(dir string, render *jobspec.Config) ([]met.Job, error) {
+	skip := make(map[string]bool)
+	if render != nil {
+		for _, file := range append(append([]string{}, render.ValueFiles...), render.Overlays...) {
+			if abs, err := filepath.Abs(file); err == nil {
+				skip[abs] = true
+			}
+		}
+	}
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !isSpec(path) {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && skip[abs] {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var jobs []met.Job
+	defined := make(map[string]string)
+	for _, file := range files {
+		fileJobs, err := loadFile(file, render)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range fileJobs {
+			if other, ok := defined[job.ID]; ok {
+				return nil, fmt.Errorf("%s: job %s is also defined in %s", file, job.ID, other)
+			}
+			defined[job.ID] = file
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func loadFile(file string, render *jobspec.Config) ([]met.Job, error) {
+	if render != nil && (len(render.ValueFiles) > 0 || len(render.Sets) > 0 || render.Environment != "" || len(render.Overlays) > 0) {
+		return render.RenderFile(file)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := met.LoadJobs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+	return jobs, nil
+}
+
+func isSpec(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, spec := range specExtensions {
+		if ext == spec {
+			return true
+		}
+	}
+	return false
+}
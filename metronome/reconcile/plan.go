@@ -0,0 +1,340 @@
+// Package reconcile makes the jobs and schedules in Metronome match a set of desired jobs, typically loaded
+// from a directory of job definition files in a git checkout.
+//
+// Plan compares the desired jobs with the live ones and lists the actions needed; Apply carries them out.  Jobs a
+// plan creates or updates are labelled as managed (ManagedByLabel) so that, with pruning on, managed jobs that are
+// no longer desired can be deleted without touching jobs managed by hand.  Syncer repeats load, plan and apply on an
+// interval and reports its status over http.
+package reconcile
+
+import (
+	"fmt"
+	"sort"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+)
+
+// ManagedByLabel - the job label naming the sync that manages the job
+const ManagedByLabel = "managed-by"
+
+// DefaultManagedBy - the ManagedByLabel value used when Options doesn't set one
+const DefaultManagedBy = "metronome-sync"
+
+// ActionType - what an Action does
+type ActionType string
+
+// Action types
+const (
+	CreateJob      ActionType = "create-job"
+	UpdateJob      ActionType = "update-job"
+	DeleteJob      ActionType = "delete-job"
+	CreateSchedule ActionType = "create-schedule"
+	UpdateSchedule ActionType = "update-schedule"
+	DeleteSchedule ActionType = "delete-schedule"
+)
+
+// Action - one change to Metronome.  Changes lists what an update changes
+type Action struct {
+	Type     ActionType   `json:"type"`
+	JobID    string       `json:"jobId"`
+	SchedID  string       `json:"schedId,omitempty"`
+	Changes  []met.Change `json:"changes,omitempty"`
+	job      *met.Job
+	schedule *met.Schedule
+}
+
+// String - `type job[/schedule]`
+func (action Action) String() string {
+	if action.SchedID != "" {
+		return fmt.Sprintf("%s %s/%s", action.Type, action.JobID, action.SchedID)
+	}
+	return fmt.Sprintf("%s %s", action.Type, action.JobID)
+}
+
+// Options - how a plan treats the live jobs
+type Options struct {
+	// ManagedBy - the ManagedByLabel value given to desired jobs and looked for when pruning.  Defaults to DefaultManagedBy
+	ManagedBy string
+	// Prune - delete live jobs labelled as managed by ManagedBy that aren't desired
+	Prune bool
+}
+
+func (options Options) managedBy() string {
+	if options.ManagedBy == "" {
+		return DefaultManagedBy
+	}
+	return options.ManagedBy
+}
+
+// Plan - the actions that make live match desired.  live should embed schedules.  Desired jobs without a schedules
+// property leave the live schedules alone; with one, live schedules it doesn't list are deleted.  Leaving out a
+// property Metronome fills in a default for, or an empty collection, matches the default; removing any other value,
+// such as an env var or label, is an update.  See normalized
+func Plan(desired []met.Job, live []met.Job, options Options) ([]Action, error) {
+	liveJobs := make(map[string]*met.Job, len(live))
+	for i := range live {
+		liveJobs[live[i].ID] = &live[i]
+	}
+	wanted := make(map[string]bool, len(desired))
+	actions := []Action{}
+	for i := range desired {
+		job := managed(&desired[i], options.managedBy())
+		if job.ID == "" {
+			return nil, fmt.Errorf("job %d has no id", i+1)
+		} else if wanted[job.ID] {
+			return nil, fmt.Errorf("job %s is defined more than once", job.ID)
+		}
+		wanted[job.ID] = true
+		current, exists := liveJobs[job.ID]
+		if !exists {
+			actions = append(actions, Action{Type: CreateJob, JobID: job.ID, job: withoutSchedules(job)})
+			for _, sched := range job.Schedules {
+				actions = append(actions, Action{Type: CreateSchedule, JobID: job.ID, SchedID: sched.ID, schedule: sched})
+			}
+			continue
+		}
+		jobActions, err := planJob(job, current)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %s", job.ID, err)
+		}
+		actions = append(actions, jobActions...)
+	}
+	if options.Prune {
+		ids := make([]string, 0, len(liveJobs))
+		for id, job := range liveJobs {
+			if !wanted[id] && job.Labels != nil && (*job.Labels)[ManagedByLabel] == options.managedBy() {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			actions = append(actions, Action{Type: DeleteJob, JobID: id})
+		}
+	}
+	return actions, nil
+}
+
+// planJob - the actions for a job that exists
+func planJob(job *met.Job, current *met.Job) ([]Action, error) {
+	var actions []Action
+	from, to := normalized(current, job)
+	changes, err := withoutSchedules(from).Diff(withoutSchedules(to))
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		actions = append(actions, Action{Type: UpdateJob, JobID: job.ID, Changes: changes, job: withoutSchedules(job)})
+	}
+	if job.Schedules == nil {
+		return actions, nil
+	}
+	liveSchedules := make(map[string]*met.Schedule, len(current.Schedules))
+	for _, sched := range current.Schedules {
+		if sched != nil {
+			liveSchedules[sched.ID] = sched
+		}
+	}
+	wanted := make(map[string]bool, len(job.Schedules))
+	for _, sched := range job.Schedules {
+		if sched == nil {
+			continue
+		} else if wanted[sched.ID] {
+			return nil, fmt.Errorf("schedule %s is defined more than once", sched.ID)
+		}
+		wanted[sched.ID] = true
+		live, exists := liveSchedules[sched.ID]
+		if !exists {
+			actions = append(actions, Action{Type: CreateSchedule, JobID: job.ID, SchedID: sched.ID, schedule: sched})
+			continue
+		}
+		from, to := normalizedSchedules(live, sched)
+		changes, err := from.Diff(to)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			actions = append(actions, Action{Type: UpdateSchedule, JobID: job.ID, SchedID: sched.ID, Changes: changes, schedule: sched})
+		}
+	}
+	ids := make([]string, 0, len(liveSchedules))
+	for id := range liveSchedules {
+		if !wanted[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		actions = append(actions, Action{Type: DeleteSchedule, JobID: job.ID, SchedID: id})
+	}
+	return actions, nil
+}
+
+// Metronome's values for the properties a job or schedule leaves out
+const (
+	defaultMaxLaunchDelay    = 3600
+	defaultRestartPolicy     = "NEVER"
+	defaultConcurrencyPolicy = "ALLOW"
+	defaultStartingDeadline  = 900
+	defaultTimezone          = "UTC"
+)
+
+// normalized - copies of the live and desired jobs with Metronome's defaults filled in on both, so a property left
+// out matches its default while a value removed from the desired job is still a change.  The defaults of properties
+// this package doesn't model (Extra) are unknown, so live ones the desired job leaves out are dropped
+func normalized(live *met.Job, desired *met.Job) (*met.Job, *met.Job) {
+	from, to := live.DeepCopy(), desired.DeepCopy()
+	from.Extra = sharedExtra(from.Extra, to.Extra)
+	if from.Run != nil && to.Run != nil {
+		shareRunExtra(from.Run, to.Run)
+	}
+	fillRunDefaults(from.Run)
+	fillRunDefaults(to.Run)
+	return from, to
+}
+
+// withDefaults - a copy of job with Metronome's defaults filled in on its run and schedules, as Metronome would store
+// it
+func withDefaults(job *met.Job) *met.Job {
+	out := job.DeepCopy()
+	fillRunDefaults(out.Run)
+	for _, sched := range out.Schedules {
+		fillScheduleDefaults(sched)
+	}
+	return out
+}
+
+// fillRunDefaults - set the run properties left out to Metronome's defaults
+func fillRunDefaults(run *met.Run) {
+	if run == nil {
+		return
+	}
+	if run.MaxLaunchDelay == 0 {
+		run.MaxLaunchDelay = defaultMaxLaunchDelay
+	}
+	if run.Restart == nil {
+		run.Restart = &met.Restart{}
+	}
+	if run.Restart.Policy == "" {
+		run.Restart.Policy = defaultRestartPolicy
+	}
+}
+
+// normalizedSchedules - copies of the live and desired schedules normalized as normalized does jobs.  enabled can't
+// be told apart from false when left out, so it is always compared
+func normalizedSchedules(live *met.Schedule, desired *met.Schedule) (*met.Schedule, *met.Schedule) {
+	from, to := live.DeepCopy(), desired.DeepCopy()
+	from.Extra = sharedExtra(from.Extra, to.Extra)
+	fillScheduleDefaults(from)
+	fillScheduleDefaults(to)
+	return from, to
+}
+
+// fillScheduleDefaults - set the schedule properties left out to Metronome's defaults
+func fillScheduleDefaults(sched *met.Schedule) {
+	if sched == nil {
+		return
+	}
+	if sched.ConcurrencyPolicy == "" {
+		sched.ConcurrencyPolicy = defaultConcurrencyPolicy
+	}
+	if sched.StartingDeadlineSeconds == 0 {
+		sched.StartingDeadlineSeconds = defaultStartingDeadline
+	}
+	if sched.Timezone == "" {
+		sched.Timezone = defaultTimezone
+	}
+}
+
+// sharedExtra - the live properties the desired side also has
+func sharedExtra(live met.Extra, desired met.Extra) met.Extra {
+	out := met.Extra{}
+	for name, value := range live {
+		if _, ok := desired[name]; ok {
+			out[name] = value
+		}
+	}
+	return out
+}
+
+// shareRunExtra - drop the Extra properties of the live run and the objects nested in it that the desired run
+// leaves out.  Slice elements are matched by position
+func shareRunExtra(live *met.Run, desired *met.Run) {
+	live.Extra = sharedExtra(live.Extra, desired.Extra)
+	for i := range live.Artifacts {
+		if i < len(desired.Artifacts) {
+			live.Artifacts[i].Extra = sharedExtra(live.Artifacts[i].Extra, desired.Artifacts[i].Extra)
+		} else {
+			live.Artifacts[i].Extra = nil
+		}
+	}
+	if live.Docker != nil {
+		var docker met.Docker
+		if desired.Docker != nil {
+			docker = *desired.Docker
+		}
+		live.Docker.Extra = sharedExtra(live.Docker.Extra, docker.Extra)
+		for i := range live.Docker.Parameters {
+			if i < len(docker.Parameters) {
+				live.Docker.Parameters[i].Extra = sharedExtra(live.Docker.Parameters[i].Extra, docker.Parameters[i].Extra)
+			} else {
+				live.Docker.Parameters[i].Extra = nil
+			}
+		}
+	}
+	if live.UCR != nil {
+		var ucr met.UCR
+		if desired.UCR != nil {
+			ucr = *desired.UCR
+		}
+		live.UCR.Extra = sharedExtra(live.UCR.Extra, ucr.Extra)
+		live.UCR.Image.Extra = sharedExtra(live.UCR.Image.Extra, ucr.Image.Extra)
+	}
+	for name, secret := range live.Secrets {
+		secret.Extra = sharedExtra(secret.Extra, desired.Secrets[name].Extra)
+		live.Secrets[name] = secret
+	}
+	if live.Placement != nil {
+		var placement met.Placement
+		if desired.Placement != nil {
+			placement = *desired.Placement
+		}
+		live.Placement.Extra = sharedExtra(live.Placement.Extra, placement.Extra)
+		for i := range live.Placement.Constraints {
+			if i < len(placement.Constraints) {
+				live.Placement.Constraints[i].Extra = sharedExtra(live.Placement.Constraints[i].Extra, placement.Constraints[i].Extra)
+			} else {
+				live.Placement.Constraints[i].Extra = nil
+			}
+		}
+	}
+	if live.Restart != nil {
+		var restart met.Restart
+		if desired.Restart != nil {
+			restart = *desired.Restart
+		}
+		live.Restart.Extra = sharedExtra(live.Restart.Extra, restart.Extra)
+	}
+	for i := range live.Volumes {
+		if i < len(desired.Volumes) {
+			live.Volumes[i].Extra = sharedExtra(live.Volumes[i].Extra, desired.Volumes[i].Extra)
+		} else {
+			live.Volumes[i].Extra = nil
+		}
+	}
+}
+
+// managed - a copy of the job labelled as managed by managedBy
+func managed(job *met.Job, managedBy string) *met.Job {
+	out := job.DeepCopy()
+	if out.Labels == nil {
+		out.Labels = &met.Labels{}
+	}
+	(*out.Labels)[ManagedByLabel] = managedBy
+	return out
+}
+
+func withoutSchedules(job *met.Job) *met.Job {
+	out := *job
+	out.Schedules = nil
+	return &out
+}
@@ -0,0 +1,419 @@
+package reconcile_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	"github.com/adobe-platform/go-metronome/metronome/jobspec"
+	. "github.com/adobe-platform/go-metronome/metronome/reconcile"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeMetronome - jobs kept in memory.  Calls lists the writes made; fail makes the named write fail
+type fakeMetronome struct {
+	met.Metronome
+	jobs  map[string]*met.Job
+	calls []string
+	fail  map[string]bool
+}
+
+func newFake(jobs ...met.Job) *fakeMetronome {
+	fake := &fakeMetronome{jobs: make(map[string]*met.Job), fail: make(map[string]bool)}
+	for i := range jobs {
+		fake.jobs[jobs[i].ID] = jobs[i].DeepCopy()
+	}
+	return fake
+}
+
+func (fake *fakeMetronome) call(name string) error {
+	fake.calls = append(fake.calls, name)
+	if fake.fail[name] {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (fake *fakeMetronome) QueryJobs(query *met.JobQuery) (*[]met.Job, error) {
+	jobs := []met.Job{}
+	for _, id := range sortedIDs(fake.jobs) {
+		jobs = append(jobs, *fake.jobs[id].DeepCopy())
+	}
+	return &jobs, nil
+}
+
+func (fake *fakeMetronome) CreateJob(job *met.Job) (*met.Job, error) {
+	if err := fake.call("create-job " + job.ID); err != nil {
+		return nil, err
+	}
+	fake.jobs[job.ID] = job.DeepCopy()
+	return job, nil
+}
+
+func (fake *fakeMetronome) UpdateJob(jobID string, job *met.Job) (interface{}, error) {
+	if err := fake.call("update-job " + jobID); err != nil {
+		return nil, err
+	}
+	schedules := fake.jobs[jobID].Schedules
+	fake.jobs[jobID] = job.DeepCopy()
+	fake.jobs[jobID].Schedules = schedules
+	return nil, nil
+}
+
+func (fake *fakeMetronome) DeleteJob(jobID string) (interface{}, error) {
+	if err := fake.call("delete-job " + jobID); err != nil {
+		return nil, err
+	}
+	delete(fake.jobs, jobID)
+	return nil, nil
+}
+
+func (fake *fakeMetronome) CreateSchedule(jobID string, sched *met.Schedule) (interface{}, error) {
+	if err := fake.call(fmt.Sprintf("create-schedule %s/%s", jobID, sched.ID)); err != nil {
+		return nil, err
+	}
+	job := fake.jobs[jobID]
+	job.Schedules = append(job.Schedules, sched.DeepCopy())
+	return nil, nil
+}
+
+func (fake *fakeMetronome) UpdateSchedule(jobID string, schedID string, sched *met.Schedule) (interface{}, error) {
+	if err := fake.call(fmt.Sprintf("update-schedule %s/%s", jobID, schedID)); err != nil {
+		return nil, err
+	}
+	for i, s := range fake.jobs[jobID].Schedules {
+		if s.ID == schedID {
+			fake.jobs[jobID].Schedules[i] = sched.DeepCopy()
+		}
+	}
+	return nil, nil
+}
+
+func (fake *fakeMetronome) DeleteSchedule(jobID string, schedID string) (interface{}, error) {
+	if err := fake.call(fmt.Sprintf("delete-schedule %s/%s", jobID, schedID)); err != nil {
+		return nil, err
+	}
+	job := fake.jobs[jobID]
+	for i, s := range job.Schedules {
+		if s.ID == schedID {
+			job.Schedules = append(job.Schedules[:i], job.Schedules[i+1:]...)
+			break
+		}
+	}
+	return nil, nil
+}
+
+func sortedIDs(jobs map[string]*met.Job) []string {
+	ids := []string{}
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+const desiredYAML = `
+id: reports
+labels:
+  owner: data
+run:
+  cpus: 0.5
+  mem: 256
+  disk: 0
+  maxLaunchDelay: 3600
+  docker:
+    image: myorg/reports:1.4.2
+schedules:
+- id: nightly
+  cron: 0 2 * * *
+  concurrencyPolicy: FORBID
+  enabled: true
+  startingDeadlineSeconds: 60
+  timezone: UTC
+---
+id: cleanup
+run:
+  cpus: 0.1
+  mem: 32
+  disk: 0
+  maxLaunchDelay: 3600
+`
+
+func loadJobs(data string) []met.Job {
+	jobs, err := met.LoadJobs([]byte(data))
+	Expect(err).ToNot(HaveOccurred())
+	return jobs
+}
+
+func actionNames(actions []Action) []string {
+	names := make([]string, len(actions))
+	for i, action := range actions {
+		names[i] = action.String()
+	}
+	return names
+}
+
+var _ = Describe("Plan", func() {
+	var desired []met.Job
+	BeforeEach(func() {
+		desired = loadJobs(desiredYAML)
+	})
+	It("Creates missing jobs followed by their schedules", func() {
+		actions, err := Plan(desired, nil, Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actionNames(actions)).To(Equal([]string{"create-job reports", "create-schedule reports/nightly", "create-job cleanup"}))
+	})
+	It("Labels desired jobs as managed without changing them", func() {
+		fake := newFake()
+		actions, _ := Plan(desired, nil, Options{ManagedBy: "team-a"})
+		Apply(fake, actions)
+		Expect((*fake.jobs["reports"].Labels)[ManagedByLabel]).To(Equal("team-a"))
+		Expect((*fake.jobs["reports"].Labels)["owner"]).To(Equal("data"))
+		Expect(desired[0].Labels).To(Equal(&met.Labels{"owner": "data"}))
+	})
+	It("Has nothing to do once applied", func() {
+		fake := newFake()
+		actions, _ := Plan(desired, nil, Options{})
+		Expect(Failed(Apply(fake, actions))).To(BeEmpty())
+		live, _ := fake.QueryJobs(nil)
+		actions, err := Plan(desired, *live, Options{Prune: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actions).To(BeEmpty())
+	})
+	It("Ignores defaults Metronome fills in", func() {
+		fake := newFake()
+		actions, _ := Plan(desired, nil, Options{})
+		Apply(fake, actions)
+		fake.jobs["cleanup"].Run.Restart = &met.Restart{Policy: "NEVER"}
+		fake.jobs["cleanup"].Run.Placement = &met.Placement{Constraints: []met.Constraint{}}
+		fake.jobs["cleanup"].Run.Extra = met.Extra{"taskKillGracePeriodSeconds": json.RawMessage("5")}
+		fake.jobs["cleanup"].Run.Restart.Extra = met.Extra{"backoff": json.RawMessage("10")}
+		fake.jobs["cleanup"].Run.Placement.Extra = met.Extra{"regions": json.RawMessage("[]")}
+		fake.jobs["cleanup"].Schedules = []*met.Schedule{{ID: "hourly", Cron: "0 * * * *", Enabled: true, ConcurrencyPolicy: "ALLOW", StartingDeadlineSeconds: 900, Timezone: "UTC"}}
+		desired[1].Run.MaxLaunchDelay = 0
+		desired[1].Schedules = []*met.Schedule{{ID: "hourly", Cron: "0 * * * *", Enabled: true}}
+		live, _ := fake.QueryJobs(nil)
+		actions, _ = Plan(desired, *live, Options{})
+		Expect(actions).To(BeEmpty())
+	})
+	It("Updates jobs when the file drops env vars, labels or other set values", func() {
+		fake := newFake()
+		desired[1].Labels = &met.Labels{"team": "data"}
+		desired[1].Run.Env = map[string]string{"A": "1", "B": "2"}
+		desired[1].Run.Restart = &met.Restart{Policy: "ON_FAILURE", ActiveDeadlineSeconds: 60}
+		actions, _ := Plan(desired, nil, Options{})
+		Apply(fake, actions)
+		desired = loadJobs(desiredYAML)
+		desired[1].Run.Env = map[string]string{"A": "1"}
+		live, _ := fake.QueryJobs(nil)
+		actions, err := Plan(desired, *live, Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actionNames(actions)).To(Equal([]string{"update-job cleanup"}))
+		Expect(actions[0].Changes).To(ConsistOf(
+			met.Change{Path: "labels.team", From: "data"},
+			met.Change{Path: "run.env.B", From: "2"},
+			met.Change{Path: "run.restart.activeDeadlineSeconds", From: float64(60), To: float64(0)},
+			met.Change{Path: "run.restart.policy", From: "ON_FAILURE", To: "NEVER"},
+		))
+		Apply(fake, actions)
+		live, _ = fake.QueryJobs(nil)
+		Expect((*live)[0].Labels).To(Equal(&met.Labels{ManagedByLabel: DefaultManagedBy}))
+		Expect((*live)[0].Run.Env).To(Equal(map[string]string{"A": "1"}))
+	})
+	It("Updates changed jobs and schedules, deleting schedules the file dropped", func() {
+		fake := newFake()
+		actions, _ := Plan(desired, nil, Options{})
+		Apply(fake, actions)
+		fake.jobs["reports"].Schedules = append(fake.jobs["reports"].Schedules, &met.Schedule{ID: "hourly", Cron: "0 * * * *"})
+		desired[0].Run.Docker.Image = "myorg/reports:1.5.0"
+		desired[0].Schedules[0].Cron = "0 3 * * *"
+		live, _ := fake.QueryJobs(nil)
+		actions, err := Plan(desired, *live, Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actionNames(actions)).To(Equal([]string{"update-job reports", "update-schedule reports/nightly", "delete-schedule reports/hourly"}))
+		Expect(actions[0].Changes).To(Equal([]met.Change{{Path: "run.docker.image", From: "myorg/reports:1.4.2", To: "myorg/reports:1.5.0"}}))
+	})
+	It("Leaves schedules alone for jobs without a schedules property", func() {
+		fake := newFake()
+		actions, _ := Plan(desired, nil, Options{})
+		Apply(fake, actions)
+		fake.jobs["cleanup"].Schedules = []*met.Schedule{{ID: "manual", Cron: "0 * * * *"}}
+		live, _ := fake.QueryJobs(nil)
+		actions, _ = Plan(desired, *live, Options{})
+		Expect(actions).To(BeEmpty())
+	})
+	It("Prunes only jobs managed by this sync", func() {
+		live := []met.Job{
+			{ID: "old", Labels: &met.Labels{ManagedByLabel: DefaultManagedBy}, Run: &met.Run{}},
+			{ID: "other-team", Labels: &met.Labels{ManagedByLabel: "team-b"}, Run: &met.Run{}},
+			{ID: "by-hand", Run: &met.Run{}},
+		}
+		actions, err := Plan(desired, live, Options{Prune: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(actionNames(actions)).To(ContainElement("delete-job old"))
+		Expect(actionNames(actions)).ToNot(ContainElement("delete-job other-team"))
+		Expect(actionNames(actions)).ToNot(ContainElement("delete-job by-hand"))
+
+		actions, _ = Plan(desired, live, Options{})
+		Expect(actionNames(actions)).ToNot(ContainElement("delete-job old"))
+	})
+	It("Rejects jobs defined twice", func() {
+		_, err := Plan(append(desired, desired[1]), nil, Options{})
+		Expect(err).To(MatchError(ContainSubstring("cleanup is defined more than once")))
+	})
+})
+
+var _ = Describe("Apply", func() {
+	It("Skips the schedules of jobs it couldn't create and carries on", func() {
+		fake := newFake()
+		fake.fail["create-job reports"] = true
+		actions, _ := Plan(loadJobs(desiredYAML), nil, Options{})
+		results := Apply(fake, actions)
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].Error).To(Equal("create-job reports failed"))
+		Expect(results[1].Error).To(ContainSubstring("skipped"))
+		Expect(results[2].Error).To(BeEmpty())
+		Expect(Failed(results)).To(HaveLen(2))
+		Expect(fake.calls).To(Equal([]string{"create-job reports", "create-job cleanup"}))
+	})
+})
+
+var _ = Describe("LoadDir", func() {
+	var dir string
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		return path
+	}
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "reconcile")
+		Expect(err).ToNot(HaveOccurred())
+	})
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+	It("Loads definition files, skipping hidden ones and other files", func() {
+		write("reports.yaml", desiredYAML)
+		write("team/extra.json", `{"id": "extra", "run": {"cpus": 1, "mem": 32, "disk": 0}}`)
+		write(".git/config.yaml", "not: a job")
+		write("README.md", "# jobs")
+		jobs, err := LoadDir(dir, nil)
+		Expect(err).ToNot(HaveOccurred())
+		ids := []string{}
+		for _, job := range jobs {
+			ids = append(ids, job.ID)
+		}
+		Expect(ids).To(Equal([]string{"reports", "cleanup", "extra"}))
+	})
+	It("Rejects a job defined in two files", func() {
+		write("a.yaml", desiredYAML)
+		write("b.json", `{"id": "cleanup", "run": {"cpus": 1, "mem": 32, "disk": 0}}`)
+		_, err := LoadDir(dir, nil)
+		Expect(err).To(MatchError(ContainSubstring("also defined")))
+	})
+	It("Renders templates, skipping the values files", func() {
+		write("reports.yaml", "id: reports\nrun:\n  cpus: 1\n  mem: {{ .mem }}\n  disk: 0\n")
+		values := write("values.yaml", "mem: 64\nenvironments:\n  prod:\n    mem: 1024\n")
+		jobs, err := LoadDir(dir, &jobspec.Config{ValueFiles: []string{values}, Environment: "prod"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].Run.Mem).To(Equal(1024))
+	})
+})
+
+var _ = Describe("Syncer", func() {
+	var (
+		fake   *fakeMetronome
+		syncer *Syncer
+		loaded []met.Job
+		failed error
+	)
+	BeforeEach(func() {
+		fake = newFake()
+		loaded, failed = loadJobs(desiredYAML), nil
+		syncer = &Syncer{Client: fake, Load: func() ([]met.Job, error) { return loaded, failed }}
+	})
+	get := func(path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", path, nil)
+		syncer.ServeHTTP(recorder, request)
+		return recorder.Code, recorder.Body.String()
+	}
+	It("Syncs and reports status", func() {
+		status := syncer.Sync()
+		Expect(status.Healthy()).To(BeTrue())
+		Expect(status.Syncs).To(Equal(1))
+		Expect(status.Jobs).To(Equal(2))
+		Expect(status.Results).To(HaveLen(3))
+		Expect(fake.jobs).To(HaveLen(2))
+
+		status = syncer.Sync()
+		Expect(status.Syncs).To(Equal(2))
+		Expect(status.Results).To(BeEmpty())
+
+		code, body := get("/status")
+		Expect(code).To(Equal(http.StatusOK))
+		var served Status
+		Expect(json.Unmarshal([]byte(body), &served)).To(Succeed())
+		Expect(served.Syncs).To(Equal(2))
+		code, _ = get("/healthz")
+		Expect(code).To(Equal(http.StatusOK))
+	})
+	It("Plans without applying in a dry run", func() {
+		syncer.DryRun = true
+		status := syncer.Sync()
+		Expect(status.Results).To(HaveLen(3))
+		Expect(fake.calls).To(BeEmpty())
+	})
+	It("Applies nothing when a job is invalid", func() {
+		loaded[1].Run.Cpus = 0
+		status := syncer.Sync()
+		Expect(status.Healthy()).To(BeFalse())
+		Expect(status.LastError).To(ContainSubstring("job cleanup"))
+		Expect(fake.calls).To(BeEmpty())
+	})
+	It("Syncs jobs that leave Metronome's defaults out", func() {
+		loaded = loadJobs(`
+id: minimal
+run:
+  cpus: 0.1
+  mem: 64
+  docker:
+    image: busybox
+schedules:
+  - id: nightly
+    cron: "0 2 * * *"
+`)
+		status := syncer.Sync()
+		Expect(status.LastError).To(BeEmpty())
+		Expect(fake.calls).To(Equal([]string{"create-job minimal", "create-schedule minimal/nightly"}))
+		Expect(syncer.Sync().Results).To(BeEmpty())
+	})
+	It("Reports the last error until a sync succeeds", func() {
+		Expect(syncer.Sync().Healthy()).To(BeTrue())
+		success := syncer.Status().LastSuccess
+		failed = errors.New("checkout missing")
+		status := syncer.Sync()
+		Expect(status.LastError).To(Equal("checkout missing"))
+		Expect(status.LastSuccess).To(Equal(success))
+		code, body := get("/healthz")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+		Expect(body).To(ContainSubstring("checkout missing"))
+		code, _ = get("/status")
+		Expect(code).To(Equal(http.StatusServiceUnavailable))
+
+		failed = nil
+		Expect(syncer.Sync().Healthy()).To(BeTrue())
+	})
+})
@@ -0,0 +1,161 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	met "github.com/adobe-platform/go-metronome/metronome"
+	log "github.com/behance/go-logrus"
+)
+
+// Status - what the last sync did.  LastError is empty when it succeeded
+type Status struct {
+	Syncs       int       `json:"syncs"`
+	LastSync    time.Time `json:"lastSync"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	Duration    string    `json:"duration,omitempty"`
+	DryRun      bool      `json:"dryRun"`
+	// Jobs - how many jobs the directory defines
+	Jobs int `json:"jobs"`
+	// Results - the actions the last sync applied or, in a dry run, planned
+	Results   []Result `json:"results"`
+	LastError string   `json:"lastError,omitempty"`
+}
+
+// Healthy - whether the last sync succeeded.  True before the first sync
+func (status Status) Healthy() bool {
+	return status.LastError == ""
+}
+
+// Syncer - repeatedly loads the desired jobs, plans against Metronome and applies the plan
+type Syncer struct {
+	Client met.Metronome
+	// Load - the desired jobs, e.g. LoadDir on a git checkout
+	Load    func() ([]met.Job, error)
+	Options Options
+	// DryRun - plan without applying
+	DryRun bool
+
+	mutex  sync.Mutex
+	status Status
+}
+
+// Sync - load, plan and apply once.  Invalid desired jobs stop the sync before anything is applied
+func (syncer *Syncer) Sync() Status {
+	start := time.Now()
+	status := Status{DryRun: syncer.DryRun, Results: []Result{}}
+	results, jobs, err := syncer.sync()
+	status.Jobs = jobs
+	if results != nil {
+		status.Results = results
+	}
+	if err != nil {
+		status.LastError = err.Error()
+		log.Errorf("sync failed: %s", err)
+	} else {
+		log.Infof("sync of %d jobs ok; %d actions", jobs, len(status.Results))
+	}
+	status.LastSync = start
+	status.Duration = time.Since(start).String()
+
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	status.Syncs = syncer.status.Syncs + 1
+	status.LastSuccess = syncer.status.LastSuccess
+	if err == nil {
+		status.LastSuccess = start
+	}
+	syncer.status = status
+	return status
+}
+
+func (syncer *Syncer) sync() ([]Result, int, error) {
+	desired, err := syncer.Load()
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range desired {
+		// validated as Metronome will store it, so properties it defaults may be left out
+		if err := withDefaults(&desired[i]).Validate(); err != nil {
+			return nil, len(desired), fmt.Errorf("job %s: %s", desired[i].ID, err)
+		}
+	}
+	live, err := syncer.Client.QueryJobs(&met.JobQuery{Embed: []met.Embed{met.EmbedSchedules}})
+	if err != nil {
+		return nil, len(desired), err
+	}
+	actions, err := Plan(desired, *live, syncer.Options)
+	if err != nil {
+		return nil, len(desired), err
+	}
+	if syncer.DryRun {
+		results := make([]Result, len(actions))
+		for i, action := range actions {
+			results[i] = Result{Action: action}
+		}
+		return results, len(desired), nil
+	}
+	results := Apply(syncer.Client, actions)
+	for _, result := range results {
+		if result.Error != "" {
+			log.Errorf("%s failed: %s", result.Action, result.Error)
+		} else {
+			log.Infof("%s", result.Action)
+		}
+	}
+	if failed := Failed(results); len(failed) > 0 {
+		return results, len(desired), fmt.Errorf("%d of %d actions failed", len(failed), len(results))
+	}
+	return results, len(desired), nil
+}
+
+// Run - sync now and every interval until stop is closed
+func (syncer *Syncer) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		syncer.Sync()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status - the last sync's status
+func (syncer *Syncer) Status() Status {
+	syncer.mutex.Lock()
+	defer syncer.mutex.Unlock()
+	return syncer.status
+}
+
+// ServeHTTP - http.Handler implementation.  /healthz answers 200 or, when the last sync failed, 503.  Every other
+// path answers the Status as json with the same codes
+func (syncer *Syncer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	status := syncer.Status()
+	code := http.StatusOK
+	if !status.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	if request.URL.Path == "/healthz" {
+		writer.WriteHeader(code)
+		if status.Healthy() {
+			fmt.Fprintln(writer, "ok")
+		} else {
+			fmt.Fprintln(writer, status.LastError)
+		}
+		return
+	}
+	b, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(code)
+	writer.Write(b)
+}
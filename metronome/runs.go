@@ -0,0 +1,220 @@
+package metronome
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RunStatus - status of a job run as reported by Metronome
+type RunStatus string
+
+const (
+	// RunInitial - run created, not yet launched
+	RunInitial RunStatus = "INITIAL"
+	// RunStarting - tasks are being launched
+	RunStarting RunStatus = "STARTING"
+	// RunActive - tasks are running
+	RunActive RunStatus = "ACTIVE"
+	// RunSuccess - run finished successfully
+	RunSuccess RunStatus = "SUCCESS"
+	// RunFailed - run finished unsuccessfully
+	RunFailed RunStatus = "FAILED"
+)
+
+var runStatuses = [...]RunStatus{
+	RunInitial,
+	RunStarting,
+	RunActive,
+	RunSuccess,
+	RunFailed,
+}
+
+// ParseRunStatus - convert a string into a RunStatus, rejecting values Metronome doesn't report
+func ParseRunStatus(status string) (RunStatus, error) {
+	for _, known := range runStatuses {
+		if string(known) == status {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown run status '%s'.  Must be one of INITIAL,STARTING,ACTIVE,SUCCESS,FAILED", status)
+}
+
+// IsTerminal - the run has finished, successfully or not
+func (status RunStatus) IsTerminal() bool {
+	return status == RunSuccess || status == RunFailed
+}
+
+// IsSuccess - the run finished successfully
+func (status RunStatus) IsSuccess() bool {
+	return status == RunSuccess
+}
+
+// TaskState - Mesos state of a task launched for a run
+type TaskState string
+
+const (
+	// TaskStaging - task is being staged on an agent
+	TaskStaging TaskState = "TASK_STAGING"
+	// TaskStarting - executor is starting the task
+	TaskStarting TaskState = "TASK_STARTING"
+	// TaskRunning - task is running
+	TaskRunning TaskState = "TASK_RUNNING"
+	// TaskKilling - task is being killed
+	TaskKilling TaskState = "TASK_KILLING"
+	// TaskFinished - task exited successfully
+	TaskFinished TaskState = "TASK_FINISHED"
+	// TaskFailed - task exited unsuccessfully
+	TaskFailed TaskState = "TASK_FAILED"
+	// TaskKilled - task was killed
+	TaskKilled TaskState = "TASK_KILLED"
+	// TaskError - task description was invalid
+	TaskError TaskState = "TASK_ERROR"
+	// TaskLost - task was lost
+	TaskLost TaskState = "TASK_LOST"
+	// TaskDropped - task was dropped before it started
+	TaskDropped TaskState = "TASK_DROPPED"
+	// TaskUnreachable - agent running the task is unreachable
+	TaskUnreachable TaskState = "TASK_UNREACHABLE"
+	// TaskGone - task is gone along with its agent
+	TaskGone TaskState = "TASK_GONE"
+	// TaskGoneByOperator - operator marked the task's agent gone
+	TaskGoneByOperator TaskState = "TASK_GONE_BY_OPERATOR"
+	// TaskUnknown - master doesn't know the task
+	TaskUnknown TaskState = "TASK_UNKNOWN"
+)
+
+// IsTerminal - the task will not change state again
+func (state TaskState) IsTerminal() bool {
+	switch state {
+	case TaskFinished, TaskFailed, TaskKilled, TaskError, TaskLost, TaskDropped, TaskGone, TaskGoneByOperator:
+		return true
+	}
+	return false
+}
+
+// IsSuccess - the task exited successfully
+func (state TaskState) IsSuccess() bool {
+	return state == TaskFinished
+}
+
+// runDuration - time between start and end.  Runs that haven't ended are measured up to now
+func runDuration(start Timestamp, end Timestamp) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	if end.IsZero() {
+		return time.Since(start.Time)
+	}
+	return end.Sub(start.Time)
+}
+
+// metronomeTimeFormat - the layout Metronome uses for createdAt, finishedAt etc.
+const metronomeTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// parseTime - parse a Metronome timestamp.  Falls back to RFC3339
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(metronomeTimeFormat, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// RunFilter - client side filtering of runs
+//  - Since/Until bound the run's creation time.  Zero values leave that end of the window open
+//  - Status keeps only runs whose status is listed.  Empty keeps every status
+type RunFilter struct {
+	Since  time.Time
+	Until  time.Time
+	Status []RunStatus
+}
+
+// matches - does a run created at `created` with `status` pass the filter.  A nil filter matches everything
+func (filter *RunFilter) matches(created Timestamp, status RunStatus) bool {
+	if filter == nil {
+		return true
+	}
+	if len(filter.Status) > 0 {
+		found := false
+		for _, want := range filter.Status {
+			if want == status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !filter.Since.IsZero() && created.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && !created.Before(filter.Until) {
+		return false
+	}
+	return true
+}
+
+// FilterActive - runs passing the filter
+func (filter *RunFilter) FilterActive(runs []JobStatus) []JobStatus {
+	kept := make([]JobStatus, 0, len(runs))
+	for _, run := range runs {
+		if filter.matches(run.CreatedAt, run.Status) {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+// FilterHistory - finished runs passing the filter
+func (filter *RunFilter) FilterHistory(runs []HistoryStatus) []HistoryStatus {
+	kept := make([]HistoryStatus, 0, len(runs))
+	for _, run := range runs {
+		if filter.matches(run.CreatedAt, run.Status) {
+			kept = append(kept, run)
+		}
+	}
+	return kept
+}
+
+// Runs - successful and failed finished runs merged into one list, newest first, with Status set to the outcome
+func (history *History) Runs() []HistoryStatus {
+	runs := make([]HistoryStatus, 0, len(history.SuccessfulFinishedRuns)+len(history.FailedFinishedRuns))
+	for _, run := range history.SuccessfulFinishedRuns {
+		run.Status = RunSuccess
+		runs = append(runs, run)
+	}
+	for _, run := range history.FailedFinishedRuns {
+		run.Status = RunFailed
+		runs = append(runs, run)
+	}
+	sort.Stable(newestFirst(runs))
+	return runs
+}
+
+// newestFirst - sort.Interface ordering finished runs by creation time, newest first
+type newestFirst []HistoryStatus
+
+func (runs newestFirst) Len() int      { return len(runs) }
+func (runs newestFirst) Swap(i, j int) { runs[i], runs[j] = runs[j], runs[i] }
+func (runs newestFirst) Less(i, j int) bool {
+	return runs[i].CreatedAt.After(runs[j].CreatedAt.Time)
+}
+
+// RunOutcome - the status of a run.  Metronome moves finished runs from the job's runs to its history, so a run no
+// longer found at runs/$runId is looked up there.  A run in neither, e.g. one not yet in the history, has an empty status
+func RunOutcome(client Metronome, jobID string, runID string) (RunStatus, error) {
+	if run, err := client.StatusJob(jobID, runID); err == nil && run.ID == runID && run.Status != "" {
+		return run.Status, nil
+	}
+	history, err := client.RunHistory(jobID, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, run := range *history {
+		if run.ID == runID {
+			return run.Status, nil
+		}
+	}
+	return "", nil
+}
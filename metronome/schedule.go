@@ -0,0 +1,47 @@
+package metronome
+
+import (
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+)
+
+// Location - the schedule's timezone.  Metronome treats an empty timezone as UTC
+func (sched *Schedule) Location() (*time.Location, error) {
+	if sched.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(sched.Timezone)
+}
+
+// Expression - the parsed cron expression
+func (sched *Schedule) Expression() (*cron.Expression, error) {
+	return cron.Parse(sched.Cron)
+}
+
+// NextRuns - the next n times the schedule fires after from, in the schedule's timezone.
+// Fewer than n are returned if the expression stops firing (e.g. a past year)
+func (sched *Schedule) NextRuns(from time.Time, n int) ([]time.Time, error) {
+	loc, err := sched.Location()
+	if err != nil {
+		return nil, err
+	}
+	expr, err := sched.Expression()
+	if err != nil {
+		return nil, err
+	}
+	return expr.NextN(from.In(loc), n), nil
+}
+
+// Explain - the schedule in english e.g. "every 4 hours on weekdays, at minute 0, in America/Los_Angeles"
+func (sched *Schedule) Explain() (string, error) {
+	loc, err := sched.Location()
+	if err != nil {
+		return "", err
+	}
+	expr, err := sched.Expression()
+	if err != nil {
+		return "", err
+	}
+	return expr.Describe() + ", in " + loc.String(), nil
+}
@@ -0,0 +1,58 @@
+package metronome_test
+
+import (
+	"time"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// Parsing, daylight saving and descriptions are covered next to cron.Parse; these cover the schedule's timezone
+var _ = Describe("Schedule", func() {
+	utc := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		Expect(err).To(BeNil())
+		return t
+	}
+	next := func(cron string, tz string, from string, n int) []string {
+		sched := Schedule{ID: "s", Cron: cron, Timezone: tz}
+		times, err := sched.NextRuns(utc(from), n)
+		Expect(err).To(BeNil())
+		var out []string
+		for _, t := range times {
+			out = append(out, t.Format(time.RFC3339))
+		}
+		return out
+	}
+
+	It("Fires in the schedule's timezone, UTC when it has none", func() {
+		Expect(next("0 */4 * * MON-FRI", "", "2017-03-03T21:00:00Z", 1)).To(Equal([]string{"2017-03-06T00:00:00Z"}))
+		Expect(next("30 2 * * *", "America/New_York", "2017-03-11T00:00:00Z", 2)).To(Equal([]string{
+			"2017-03-11T02:30:00-05:00",
+			"2017-03-13T02:30:00-04:00",
+		}))
+	})
+
+	It("Rejects bad expressions and timezones", func() {
+		_, err := (&Schedule{Cron: "* * * *"}).NextRuns(time.Now(), 1)
+		Expect(err).ToNot(BeNil())
+		_, err = (&Schedule{Cron: "* * * * *", Timezone: "Nowhere/Special"}).NextRuns(time.Now(), 1)
+		Expect(err).ToNot(BeNil())
+		_, err = (&Schedule{Cron: "* * * * *", Timezone: "Nowhere/Special"}).Explain()
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("Explains expressions in english with the timezone", func() {
+		explain := func(cron string, tz string) string {
+			text, err := (&Schedule{Cron: cron, Timezone: tz}).Explain()
+			Expect(err).To(BeNil())
+			return text
+		}
+		Expect(explain("0 */4 * * 1-5", "America/Los_Angeles")).To(Equal("every 4 hours on weekdays, at minute 0, in America/Los_Angeles"))
+		Expect(explain("* * * * *", "")).To(Equal("every minute, in UTC"))
+		_, err := (&Schedule{Cron: "bogus"}).Explain()
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,215 @@
+package metronome
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft - the JSON Schema version JobSchema and ScheduleSchema are written in
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema - a JSON Schema document
+type Schema map[string]interface{}
+
+// JobSchema - JSON Schema for a job definition file, generated from Job.  Runtime detail
+// (activeRuns, history, historySummary, nextRunAt) is left out
+func JobSchema() Schema {
+	return generateSchema(reflect.TypeOf(Job{}), "Metronome job")
+}
+
+// ScheduleSchema - JSON Schema for a schedule definition file, generated from Schedule
+func ScheduleSchema() Schema {
+	return generateSchema(reflect.TypeOf(Schedule{}), "Metronome schedule")
+}
+
+// MarshalIndent - the schema as indented json with a trailing newline, the form shipped in schema/
+func (schema Schema) MarshalIndent() ([]byte, error) {
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// schemaRuntimeFields - properties Metronome fills in that don't belong in a definition file
+var schemaRuntimeFields = map[string]bool{
+	"job.activeRuns":     true,
+	"job.history":        true,
+	"job.historySummary": true,
+	"schedule.nextRunAt": true,
+}
+
+// schemaRequired - per definition, the properties Validate insists on
+var schemaRequired = map[string][]string{
+	"job":             {"id", "run"},
+	"run":             {"cpus", "mem", "disk"},
+	"schedule":        {"id", "cron"},
+	"docker":          {"image"},
+	"dockerParameter": {"key"},
+	"ucrImage":        {"id"},
+	"artifact":        {"uri"},
+	"constraint":      {"attribute", "operator"},
+	"restart":         {"policy"},
+	"secretSource":    {"source"},
+}
+
+// schemaRules - per definition property, the keywords matching Validate's checks
+var schemaRules = map[string]Schema{
+	"job.id":                           {"pattern": jobIDRe.String()},
+	"run.cpus":                         {"minimum": minCpus},
+	"run.mem":                          {"minimum": minMem},
+	"run.disk":                         {"minimum": minDisk},
+	"run.maxLaunchDelay":               {"minimum": minMaxLaunchDelay},
+	"restart.policy":                   {"enum": restartPolicies},
+	"restart.activeDeadlineSeconds":    {"minimum": 0},
+	"schedule.concurrencyPolicy":       {"enum": concurrencyPolicies},
+	"schedule.startingDeadlineSeconds": {"minimum": minStartDeadline},
+	"ucrImage.kind":                    {"enum": []string{ImageKindDocker, ImageKindAppc}},
+}
+
+type schemaGenerator struct {
+	definitions map[string]interface{}
+}
+
+func generateSchema(t reflect.Type, title string) Schema {
+	g := &schemaGenerator{definitions: make(map[string]interface{})}
+	root := g.structSchema(t)
+	root["$schema"] = SchemaDraft
+	root["title"] = title
+	if len(g.definitions) > 0 {
+		root["definitions"] = g.definitions
+	}
+	return root
+}
+
+// definitionName - Job is job, UCRImage is ucrImage
+func definitionName(t reflect.Type) string {
+	name := t.Name()
+	upper := 0
+	for upper < len(name) && name[upper] >= 'A' && name[upper] <= 'Z' {
+		upper++
+	}
+	if upper > 1 && upper < len(name) {
+		upper--
+	}
+	return strings.ToLower(name[:upper]) + name[upper:]
+}
+
+// typeSchema - the schema for a field's type.  Structs become definitions referenced by $ref
+func (g *schemaGenerator) typeSchema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(Operator("")):
+		ops := make([]string, len(constraintOperators))
+		for i, op := range constraintOperators {
+			ops[i] = op.String()
+		}
+		return Schema{"type": "string", "enum": ops}
+	case reflect.TypeOf(MountMode(0)):
+		return Schema{"type": "string", "enum": mountModes[:]}
+	case reflect.TypeOf(ContainerPath("")):
+		return Schema{"type": "string", "pattern": containerPathRe.String()}
+	case reflect.TypeOf(Timestamp{}):
+		return Schema{"type": "string"}
+	case reflect.TypeOf(Volume{}):
+		return g.define("volume", g.volumeSchema)
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.define(definitionName(t), func() Schema { return g.structSchema(t) })
+	case reflect.Slice:
+		return Schema{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	}
+	return Schema{}
+}
+
+// define - add a definition once and reference it
+func (g *schemaGenerator) define(name string, schema func() Schema) Schema {
+	if _, ok := g.definitions[name]; !ok {
+		g.definitions[name] = nil
+		g.definitions[name] = schema()
+	}
+	return Schema{"$ref": "#/definitions/" + name}
+}
+
+// structSchema - an object with a property per json field.  Other properties stay allowed as they are kept in Extra
+func (g *schemaGenerator) structSchema(t reflect.Type) Schema {
+	name := definitionName(t)
+	properties := Schema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "-" || field.PkgPath != "" {
+			continue
+		}
+		if tag == "" {
+			tag = field.Name
+		}
+		key := name + "." + tag
+		if schemaRuntimeFields[key] {
+			continue
+		}
+		prop := g.typeSchema(field.Type)
+		if t == reflect.TypeOf(Run{}) && tag == "env" {
+			prop = g.envSchema()
+		}
+		for k, v := range schemaRules[key] {
+			prop[k] = v
+		}
+		properties[tag] = prop
+	}
+	schema := Schema{"type": "object", "properties": properties}
+	if required, ok := schemaRequired[name]; ok {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// envSchema - Run.Env and Run.EnvSecrets share env: a value or a reference to one of Run.Secrets
+func (g *schemaGenerator) envSchema() Schema {
+	secret := g.define("envSecret", func() Schema {
+		return Schema{
+			"type":                 "object",
+			"properties":           Schema{"secret": Schema{"type": "string"}},
+			"required":             []string{"secret"},
+			"additionalProperties": false,
+		}
+	})
+	return Schema{"type": "object", "additionalProperties": Schema{"oneOf": []Schema{{"type": "string"}, secret}}}
+}
+
+// volumeSchema - a host path volume or a secret volume
+func (g *schemaGenerator) volumeSchema() Schema {
+	host := Schema{
+		"type": "object",
+		"properties": Schema{
+			"containerPath": g.typeSchema(reflect.TypeOf(ContainerPath(""))),
+			"hostPath":      Schema{"type": "string", "minLength": 1},
+			"mode":          g.typeSchema(reflect.TypeOf(MountMode(0))),
+		},
+		"required": []string{"containerPath", "hostPath", "mode"},
+	}
+	secret := Schema{
+		"type": "object",
+		"properties": Schema{
+			"containerPath": Schema{"type": "string", "minLength": 1},
+			"secret":        Schema{"type": "string", "minLength": 1},
+		},
+		"required":             []string{"containerPath", "secret"},
+		"additionalProperties": false,
+	}
+	return Schema{"oneOf": []Schema{host, secret}}
+}
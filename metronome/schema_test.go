@@ -0,0 +1,74 @@
+package metronome_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// properties - the property names a schema, or one of its definitions, allows
+func properties(schema Schema, definition string) []string {
+	if definition != "" {
+		schema = schema["definitions"].(map[string]interface{})[definition].(Schema)
+	}
+	var names []string
+	for name := range schema["properties"].(Schema) {
+		names = append(names, name)
+	}
+	return names
+}
+
+var _ = Describe("Schema", func() {
+	It("Matches the schemas shipped in schema/", func() {
+		for file, schema := range map[string]Schema{"job.schema.json": JobSchema(), "schedule.schema.json": ScheduleSchema()} {
+			shipped, err := ioutil.ReadFile("../schema/" + file)
+			Expect(err).To(BeNil())
+			generated, err := schema.MarshalIndent()
+			Expect(err).To(BeNil())
+			Expect(string(shipped)).To(Equal(string(generated)), "run `make schema` to regenerate "+file)
+		}
+	})
+
+	It("Describes every field a job marshals", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(yamlJobJSON()), &job)).To(BeNil())
+		var marshalled map[string]map[string]interface{}
+		b, _ := json.Marshal(&Job{Run: job.Run})
+		json.Unmarshal(b, &marshalled)
+
+		schema := JobSchema()
+		Expect(properties(schema, "")).To(ConsistOf("id", "description", "labels", "run", "schedules"))
+		for name := range marshalled["run"] {
+			Expect(properties(schema, "run")).To(ContainElement(name))
+		}
+		Expect(properties(schema, "schedule")).ToNot(ContainElement("nextRunAt"))
+	})
+
+	It("Carries the enums and patterns Validate uses", func() {
+		b, err := JobSchema().MarshalIndent()
+		Expect(err).To(BeNil())
+		doc := string(b)
+		Expect(doc).To(ContainSubstring(`"GROUP_BY"`))
+		Expect(doc).To(ContainSubstring(`"ON_FAILURE"`))
+		Expect(doc).To(ContainSubstring(`"RW"`))
+		Expect(doc).To(ContainSubstring(`"pattern": "^/[^/].*$"`))
+		Expect(properties(ScheduleSchema(), "")).To(ConsistOf("id", "cron", "concurrencyPolicy", "enabled", "startingDeadlineSeconds", "timezone"))
+	})
+})
+
+// yamlJobJSON - the yaml spec's job as json, covering docker, env secrets, placement, volumes and secrets
+func yamlJobJSON() string {
+	var job Job
+	Expect(FromYAML([]byte(yamlJob), &job)).To(BeNil())
+	job.Run.Restart = &Restart{Policy: "NEVER"}
+	job.Run.Artifacts = []Artifact{{URI: "http://example.com/a.zip"}}
+	job.Run.Args = []string{"--dry"}
+	job.Run.User = "root"
+	b, err := json.Marshal(&job)
+	Expect(err).To(BeNil())
+	return string(b)
+}
@@ -0,0 +1,163 @@
+package metronome
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SecretSource - a secret in the DC/OS secret store e.g. {"source": "databases/prod/password"}
+type SecretSource struct {
+	Source string `json:"source"`
+	// Extra - properties this package doesn't model, preserved across unmarshal and marshal
+	Extra Extra `json:"-"`
+}
+
+// envSecret - the json form of an environment variable whose value comes from a secret
+type envSecret struct {
+	Secret string `json:"secret"`
+}
+
+// runAlias - Run without its json methods so the custom marshalling can defer to the defaults
+type runAlias Run
+
+// MarshalJSON - json interface implementation.  Env holds both plain values and {"secret": name} references.
+// Properties kept in Extra are written back
+func (runner Run) MarshalJSON() ([]byte, error) {
+	var env map[string]interface{}
+	if runner.Env != nil || runner.EnvSecrets != nil {
+		env = make(map[string]interface{}, len(runner.Env)+len(runner.EnvSecrets))
+		for name, value := range runner.Env {
+			env[name] = value
+		}
+		for name, secret := range runner.EnvSecrets {
+			env[name] = envSecret{Secret: secret}
+		}
+	}
+	aux := struct {
+		*runAlias
+		Env map[string]interface{} `json:"env,omitempty"`
+	}{(*runAlias)(&runner), env}
+	b, err := json.Marshal(&aux)
+	if err != nil {
+		return nil, err
+	}
+	return withExtra(b, runner.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Splits env into Env and EnvSecrets and keeps undeclared properties in
+// Extra.  Env and EnvSecrets are replaced, not merged, when unmarshalling into a Run that already has them
+func (runner *Run) UnmarshalJSON(raw []byte) error {
+	runner.Env, runner.EnvSecrets = nil, nil
+	aux := struct {
+		*runAlias
+		Env map[string]json.RawMessage `json:"env,omitempty"`
+	}{runAlias: (*runAlias)(runner)}
+	if err := json.Unmarshal(raw, &aux); err != nil {
+		return err
+	}
+	extra, err := extraFields(raw, runner)
+	if err != nil {
+		return err
+	}
+	runner.Extra = extra
+	if aux.Env == nil {
+		return nil
+	}
+	runner.Env = make(map[string]string, len(aux.Env))
+	for name, value := range aux.Env {
+		if trimmed := bytes.TrimSpace(value); len(trimmed) > 0 && trimmed[0] == '{' {
+			var ref envSecret
+			if err := json.Unmarshal(trimmed, &ref); err != nil {
+				return fmt.Errorf("env %s: %s", name, err)
+			}
+			if runner.EnvSecrets == nil {
+				runner.EnvSecrets = make(map[string]string)
+			}
+			runner.EnvSecrets[name] = ref.Secret
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("env %s: must be a string or {\"secret\": name}", name)
+		}
+		runner.Env[name] = s
+	}
+	return nil
+}
+
+// GetSecrets - the secrets the run can reference, keyed by name
+func (runner *Run) GetSecrets() map[string]SecretSource {
+	return runner.Secrets
+}
+
+// SetSecret - make the secret store's source available to the run as name
+func (runner *Run) SetSecret(name string, source string) *Run {
+	if runner.Secrets == nil {
+		runner.Secrets = make(map[string]SecretSource)
+	}
+	runner.Secrets[name] = SecretSource{Source: source}
+	return runner
+}
+
+// AddSecret - name the source secret0, secret1, ... unless the run already references it.  Returns the name
+func (runner *Run) AddSecret(source string) string {
+	for name, secret := range runner.Secrets {
+		if secret.Source == source {
+			return name
+		}
+	}
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("secret%d", i)
+		if _, taken := runner.Secrets[name]; !taken {
+			runner.SetSecret(name, source)
+			return name
+		}
+	}
+}
+
+// GetEnvSecrets - environment variables whose value comes from a secret, mapped to the secret's name
+func (runner *Run) GetEnvSecrets() map[string]string {
+	return runner.EnvSecrets
+}
+
+// SetEnvSecret - set the environment variable to the value of the named secret
+func (runner *Run) SetEnvSecret(variable string, secret string) *Run {
+	if runner.EnvSecrets == nil {
+		runner.EnvSecrets = make(map[string]string)
+	}
+	runner.EnvSecrets[variable] = secret
+	return runner
+}
+
+// NewSecretVolume - a volume exposing the named secret as a file at containerPath
+func NewSecretVolume(containerPath string, secret string) (*Volume, error) {
+	if containerPath == "" {
+		return nil, required("container path")
+	}
+	if secret == "" {
+		return nil, required("secret")
+	}
+	return &Volume{ContainerPath: ContainerPath(containerPath), Secret: secret}, nil
+}
+
+// volumeAlias - Volume without its json methods
+type volumeAlias Volume
+
+// MarshalJSON - json interface implementation.  Secret volumes have no host path or mode.  Writes back properties
+// kept in Extra
+func (vol Volume) MarshalJSON() ([]byte, error) {
+	if vol.Secret != "" {
+		return marshalExtra(&struct {
+			ContainerPath string `json:"containerPath"`
+			Secret        string `json:"secret"`
+		}{string(vol.ContainerPath), vol.Secret}, vol.Extra)
+	}
+	return marshalExtra((*volumeAlias)(&vol), vol.Extra)
+}
+
+// UnmarshalJSON - json interface implementation.  Keeps undeclared properties in Extra
+func (vol *Volume) UnmarshalJSON(raw []byte) (err error) {
+	vol.Extra, err = unmarshalExtra(raw, (*volumeAlias)(vol))
+	return err
+}
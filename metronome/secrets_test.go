@@ -0,0 +1,84 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const secretRun = `{
+  "cpus": 1, "mem": 128, "disk": 0, "maxLaunchDelay": 3600,
+  "env": {"STAGE": "prod", "DB_PASSWORD": {"secret": "secret0"}},
+  "secrets": {"secret0": {"source": "databases/prod/password"}, "secret1": {"source": "tls/cert"}},
+  "volumes": [
+    {"containerPath": "/mnt/data", "hostPath": "/data", "mode": "RO"},
+    {"containerPath": "cert.pem", "secret": "secret1"}
+  ]
+}`
+
+var _ = Describe("Secrets", func() {
+	It("Round trips secret env vars and volumes", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(secretRun), &run)).To(BeNil())
+		Expect(run.Env).To(Equal(map[string]string{"STAGE": "prod"}))
+		Expect(run.GetEnvSecrets()).To(Equal(map[string]string{"DB_PASSWORD": "secret0"}))
+		Expect(run.GetSecrets()["secret1"].Source).To(Equal("tls/cert"))
+		Expect(run.Volumes[1].Secret).To(Equal("secret1"))
+		Expect(run.Validate()).To(BeNil())
+
+		b, err := json.Marshal(&run)
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(secretRun))
+	})
+
+	It("Leaves plain env alone", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(`{"env": {"A": "1"}}`), &run)).To(BeNil())
+		Expect(run.EnvSecrets).To(BeNil())
+		b, err := json.Marshal(run)
+		Expect(err).To(BeNil())
+		Expect(b).To(ContainSubstring(`"env":{"A":"1"}`))
+	})
+
+	It("Replaces env and secret env vars when a run is reused", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(secretRun), &run)).To(BeNil())
+		Expect(json.Unmarshal([]byte(`{"env": {"A": "1"}}`), &run)).To(BeNil())
+		Expect(run.Env).To(Equal(map[string]string{"A": "1"}))
+		Expect(run.EnvSecrets).To(BeNil())
+		Expect(json.Unmarshal([]byte(`{"cpus": 1}`), &run)).To(BeNil())
+		Expect(run.Env).To(BeNil())
+	})
+
+	It("Rejects env values that are neither strings nor secrets", func() {
+		var run Run
+		Expect(json.Unmarshal([]byte(`{"env": {"A": 1}}`), &run)).ToNot(BeNil())
+	})
+
+	It("Names secrets once per source", func() {
+		run, _ := NewRun(1, 128, 1)
+		run.MaxLaunchDelay = 1
+		run.SetEnvSecret("USER", run.AddSecret("db/user")).SetEnvSecret("PASSWORD", run.AddSecret("db/password"))
+		Expect(run.AddSecret("db/user")).To(Equal("secret0"))
+		vol, err := NewSecretVolume("password", run.AddSecret("db/password"))
+		Expect(err).To(BeNil())
+		Expect(vol.Secret).To(Equal("secret1"))
+		run.Volumes = append(run.Volumes, *vol)
+		Expect(run.Secrets).To(HaveLen(2))
+		Expect(run.Validate()).To(BeNil())
+	})
+
+	It("Validates secret references", func() {
+		run := Run{Cpus: 1, Mem: 128, MaxLaunchDelay: 1,
+			Env:        map[string]string{"A": "1"},
+			EnvSecrets: map[string]string{"A": "secret0", "B": "missing"},
+			Secrets:    map[string]SecretSource{"secret0": {}},
+			Volumes:    []Volume{{ContainerPath: "cert", HostPath: "/etc", Secret: "nope"}},
+		}
+		Expect(fields(run.Validate())).To(ConsistOf(
+			"volumes[0].hostPath", "volumes[0].secret", "secrets.secret0.source", "env.A", "env.B"))
+	})
+})
@@ -0,0 +1,52 @@
+package metronome
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Timestamp - a time.Time that (un)marshals in Metronome's format, e.g. 2016-12-12T18:02:00.251+0000
+//  - null and "" decode to the zero time
+//  - the zero time encodes as null
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp - wrap a time.Time
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// MarshalJSON - json interface implementation
+func (ts Timestamp) MarshalJSON() ([]byte, error) {
+	if ts.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ts.Format(metronomeTimeFormat))
+}
+
+// UnmarshalJSON - json interface implementation.  Accepts Metronome's format and RFC3339
+func (ts *Timestamp) UnmarshalJSON(raw []byte) error {
+	var s *string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		ts.Time = time.Time{}
+		return nil
+	}
+	t, err := parseTime(*s)
+	if err != nil {
+		return err
+	}
+	ts.Time = t
+	return nil
+}
+
+// String - Metronome's format or "" for the zero time
+func (ts Timestamp) String() string {
+	if ts.IsZero() {
+		return ""
+	}
+	return ts.Format(metronomeTimeFormat)
+}
@@ -0,0 +1,369 @@
+package metronome
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adobe-platform/go-metronome/metronome/cron"
+)
+
+// Patterns and limits from the Metronome job and schedule json schemas
+var (
+	jobIDRe         = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9]+)*)([.][a-z0-9]([a-z0-9-]*[a-z0-9]+)*)*$`)
+	containerPathRe = regexp.MustCompile(`^/[^/].*$`)
+)
+
+const (
+	minCpus           = 0.01
+	minMem            = 32
+	minDisk           = 0
+	minMaxLaunchDelay = 1
+	minStartDeadline  = 1
+)
+
+var restartPolicies = []string{"NEVER", "ON_FAILURE"}
+var concurrencyPolicies = []string{"ALLOW", "FORBID", "REPLACE"}
+
+// FieldError - a single validation failure.  Field is the json path of the offending value e.g. run.volumes[0].mode
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error - error interface implementation
+func (fe FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", fe.Field, fe.Message)
+}
+
+// ValidationErrors - every violation found by a Validate method
+type ValidationErrors []FieldError
+
+// Error - error interface implementation.  Lists every violation
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// validator - collects violations while walking a model
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// err - nil when nothing was found so callers can compare against nil
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func fieldPath(parent string, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
+
+func indexPath(parent string, field string, i int) string {
+	return fmt.Sprintf("%s[%d]", fieldPath(parent, field), i)
+}
+
+func oneOf(val string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate - check the job, its run and any embedded schedules against Metronome's rules
+func (theJob *Job) Validate() error {
+	v := new(validator)
+	theJob.validate("", v)
+	return v.err()
+}
+
+func (theJob *Job) validate(path string, v *validator) {
+	if theJob.ID == "" {
+		v.add(fieldPath(path, "id"), "is required")
+	} else if !jobIDRe.MatchString(theJob.ID) {
+		v.add(fieldPath(path, "id"), "'%s' must be dot separated names of lowercase letters, digits and dashes not beginning or ending with a dash", theJob.ID)
+	}
+	if theJob.Run == nil {
+		v.add(fieldPath(path, "run"), "is required")
+	} else {
+		theJob.Run.validate(fieldPath(path, "run"), v)
+	}
+	for i, sched := range theJob.Schedules {
+		if sched != nil {
+			sched.validate(indexPath(path, "schedules", i), v)
+		}
+	}
+}
+
+// Validate - check resources, restart policy, volumes, artifacts and constraints.  A zero maxLaunchDelay leaves it to
+// Metronome's default
+func (runner *Run) Validate() error {
+	v := new(validator)
+	runner.validate("", v)
+	return v.err()
+}
+
+func (runner *Run) validate(path string, v *validator) {
+	runner.validateResources(path, v)
+	// zero leaves maxLaunchDelay to Metronome's default
+	if runner.MaxLaunchDelay != 0 && runner.MaxLaunchDelay < minMaxLaunchDelay {
+		v.add(fieldPath(path, "maxLaunchDelay"), "must be at least %d", minMaxLaunchDelay)
+	}
+	if runner.Docker != nil {
+		runner.Docker.validate(fieldPath(path, "docker"), v)
+		if runner.UCR != nil {
+			v.add(fieldPath(path, "ucr"), "can't be combined with docker")
+		}
+	}
+	if runner.UCR != nil {
+		runner.UCR.validate(fieldPath(path, "ucr"), v)
+	}
+	for i := range runner.Artifacts {
+		runner.Artifacts[i].validate(indexPath(path, "artifacts", i), v)
+	}
+	if runner.Placement != nil {
+		for i := range runner.Placement.Constraints {
+			runner.Placement.Constraints[i].validate(indexPath(path, "placement.constraints", i), v)
+		}
+	}
+	if runner.Restart != nil {
+		runner.Restart.validate(fieldPath(path, "restart"), v)
+	}
+	for i := range runner.Volumes {
+		volPath := indexPath(path, "volumes", i)
+		runner.Volumes[i].validate(volPath, v)
+		if secret := runner.Volumes[i].Secret; secret != "" {
+			if _, ok := runner.Secrets[secret]; !ok {
+				v.add(fieldPath(volPath, "secret"), "'%s' is not defined in secrets", secret)
+			}
+		}
+	}
+	runner.validateSecrets(path, v)
+}
+
+// validateResources - cpus, mem and disk.  NewRun checks these alone since the rest of a run is set afterwards
+func (runner *Run) validateResources(path string, v *validator) {
+	if runner.Cpus < minCpus {
+		v.add(fieldPath(path, "cpus"), "must be at least %v", minCpus)
+	}
+	if runner.Mem < minMem {
+		v.add(fieldPath(path, "mem"), "must be at least %d", minMem)
+	}
+	if runner.Disk < minDisk {
+		v.add(fieldPath(path, "disk"), "must be at least %d", minDisk)
+	}
+}
+
+// validateSecrets - every secret has a source and every reference to one is defined
+func (runner *Run) validateSecrets(path string, v *validator) {
+	names := make([]string, 0, len(runner.Secrets))
+	for name := range runner.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if runner.Secrets[name].Source == "" {
+			v.add(fieldPath(path, "secrets."+name+".source"), "is required")
+		}
+	}
+	vars := make([]string, 0, len(runner.EnvSecrets))
+	for name := range runner.EnvSecrets {
+		vars = append(vars, name)
+	}
+	sort.Strings(vars)
+	for _, name := range vars {
+		secret := runner.EnvSecrets[name]
+		if _, ok := runner.Secrets[secret]; !ok {
+			v.add(fieldPath(path, "env."+name), "secret '%s' is not defined in secrets", secret)
+		}
+		if _, ok := runner.Env[name]; ok {
+			v.add(fieldPath(path, "env."+name), "can't be both a value and a secret")
+		}
+	}
+}
+
+// Validate - check the image and parameters
+func (docker *Docker) Validate() error {
+	v := new(validator)
+	docker.validate("", v)
+	return v.err()
+}
+
+func (docker *Docker) validate(path string, v *validator) {
+	if docker.Image == "" {
+		v.add(fieldPath(path, "image"), "is required")
+	}
+	for i, param := range docker.Parameters {
+		if param.Key == "" {
+			v.add(indexPath(path, "parameters", i)+".key", "is required")
+		}
+	}
+}
+
+// Validate - check the image id and kind
+func (ucr *UCR) Validate() error {
+	v := new(validator)
+	ucr.validate("", v)
+	return v.err()
+}
+
+func (ucr *UCR) validate(path string, v *validator) {
+	if ucr.Image.ID == "" {
+		v.add(fieldPath(path, "image.id"), "is required")
+	}
+	if ucr.Image.Kind != "" && ucr.Image.Kind != ImageKindDocker && ucr.Image.Kind != ImageKindAppc {
+		v.add(fieldPath(path, "image.kind"), "must be %s or %s not '%s'", ImageKindDocker, ImageKindAppc, ucr.Image.Kind)
+	}
+}
+
+// Validate - check the schedule's id, cron expression, timezone, concurrency policy and deadline.  An empty policy or
+// zero deadline leaves it to Metronome's default
+func (sched *Schedule) Validate() error {
+	v := new(validator)
+	sched.validate("", v)
+	return v.err()
+}
+
+func (sched *Schedule) validate(path string, v *validator) {
+	if sched.ID == "" {
+		v.add(fieldPath(path, "id"), "is required")
+	}
+	if sched.Cron == "" {
+		v.add(fieldPath(path, "cron"), "is required")
+	} else if _, err := cron.Parse(sched.Cron); err != nil {
+		v.add(fieldPath(path, "cron"), "%s", err.Error())
+	}
+	if sched.Timezone != "" {
+		if _, err := time.LoadLocation(sched.Timezone); err != nil {
+			v.add(fieldPath(path, "timezone"), "unknown timezone '%s'", sched.Timezone)
+		}
+	}
+	if sched.ConcurrencyPolicy != "" && !oneOf(sched.ConcurrencyPolicy, concurrencyPolicies) {
+		v.add(fieldPath(path, "concurrencyPolicy"), "must be one of %s not '%s'", strings.Join(concurrencyPolicies, ","), sched.ConcurrencyPolicy)
+	}
+	if sched.StartingDeadlineSeconds != 0 && sched.StartingDeadlineSeconds < minStartDeadline {
+		v.add(fieldPath(path, "startingDeadlineSeconds"), "must be at least %d", minStartDeadline)
+	}
+}
+
+// Validate - check the volume's container path, host path and mode, or the container path of a secret volume
+func (vol *Volume) Validate() error {
+	v := new(validator)
+	vol.validate("", v)
+	return v.err()
+}
+
+func (vol *Volume) validate(path string, v *validator) {
+	if vol.Secret != "" {
+		if vol.ContainerPath == "" {
+			v.add(fieldPath(path, "containerPath"), "is required")
+		}
+		if vol.HostPath != "" {
+			v.add(fieldPath(path, "hostPath"), "can't be combined with secret")
+		}
+		return
+	}
+	if !containerPathRe.MatchString(string(vol.ContainerPath)) {
+		v.add(fieldPath(path, "containerPath"), "'%s' must match ^/[^/].*$", vol.ContainerPath)
+	}
+	if vol.HostPath == "" {
+		v.add(fieldPath(path, "hostPath"), "is required")
+	}
+	if vol.Mode != RO && vol.Mode != RW {
+		v.add(fieldPath(path, "mode"), "must be RO or RW")
+	}
+}
+
+// Validate - check the artifact has a usable uri
+func (theArtifact *Artifact) Validate() error {
+	v := new(validator)
+	theArtifact.validate("", v)
+	return v.err()
+}
+
+func (theArtifact *Artifact) validate(path string, v *validator) {
+	if theArtifact.URI == "" {
+		v.add(fieldPath(path, "uri"), "is required")
+	} else if _, err := url.Parse(theArtifact.URI); err != nil {
+		v.add(fieldPath(path, "uri"), "'%s' is not a valid uri", theArtifact.URI)
+	}
+}
+
+// Validate - check the constraint's attribute, operator and that the value suits the operator
+func (theConstraint *Constraint) Validate() error {
+	v := new(validator)
+	theConstraint.validate("", v)
+	return v.err()
+}
+
+func (theConstraint *Constraint) validate(path string, v *validator) {
+	if theConstraint.Attribute == "" {
+		v.add(fieldPath(path, "attribute"), "is required")
+	}
+	value := fieldPath(path, "value")
+	switch theConstraint.Operator {
+	case EQ, IS, IN:
+		if theConstraint.Value == "" {
+			v.add(value, "is required")
+		}
+	case LIKE, UNLIKE:
+		if _, err := regexp.Compile(theConstraint.Value); err != nil {
+			v.add(value, "'%s' isn't a valid regex: %s", theConstraint.Value, err)
+		}
+	case GROUPBY:
+		if theConstraint.Value != "" {
+			if n, err := strconv.Atoi(theConstraint.Value); err != nil || n < 1 {
+				v.add(value, "'%s' must be a positive integer", theConstraint.Value)
+			}
+		}
+	case MAXPER:
+		if n, err := strconv.Atoi(theConstraint.Value); err != nil || n < 1 {
+			v.add(value, "'%s' must be a positive integer", theConstraint.Value)
+		}
+	case UNIQUE:
+		if theConstraint.Value != "" {
+			v.add(value, "must be empty")
+		}
+	default:
+		names := make([]string, len(constraintOperators))
+		for i, op := range constraintOperators {
+			names[i] = op.String()
+		}
+		v.add(fieldPath(path, "operator"), "'%s' must be one of %s", theConstraint.Operator, strings.Join(names, ","))
+	}
+}
+
+// Validate - check the restart policy and deadline
+func (restart *Restart) Validate() error {
+	v := new(validator)
+	restart.validate("", v)
+	return v.err()
+}
+
+func (restart *Restart) validate(path string, v *validator) {
+	if !oneOf(restart.Policy, restartPolicies) {
+		v.add(fieldPath(path, "policy"), "must be one of %s not '%s'", strings.Join(restartPolicies, ","), restart.Policy)
+	}
+	if restart.ActiveDeadlineSeconds < 0 {
+		v.add(fieldPath(path, "activeDeadlineSeconds"), "must not be negative")
+	}
+}
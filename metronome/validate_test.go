@@ -0,0 +1,164 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fields - the field paths of a ValidationErrors
+func fields(err error) []string {
+	Expect(err).To(BeAssignableToTypeOf(ValidationErrors{}))
+	var paths []string
+	for _, fe := range err.(ValidationErrors) {
+		paths = append(paths, fe.Field)
+	}
+	return paths
+}
+
+var _ = Describe("Validate", func() {
+	It("Accepts the documented sample job", func() {
+		var job Job
+		Expect(json.Unmarshal([]byte(data5), &job)).To(BeNil())
+		Expect(job.Validate()).To(BeNil())
+	})
+
+	It("Reports every violation with its field path", func() {
+		job := Job{
+			ID: "Bad_ID",
+			Run: &Run{
+				Cpus:           0,
+				Mem:            16,
+				Disk:           -1,
+				MaxLaunchDelay: -1,
+				Artifacts:      []Artifact{{URI: ""}},
+				Restart:        &Restart{Policy: "ALWAYS"},
+				Volumes:        []Volume{{ContainerPath: "relative", HostPath: "/tmp", Mode: RW}},
+			},
+			Schedules: []*Schedule{{
+				ID:                      "nightly",
+				Cron:                    "0 0 *",
+				Timezone:                "Mars/Olympus",
+				ConcurrencyPolicy:       "SOMETIMES",
+				StartingDeadlineSeconds: -5,
+			}},
+		}
+		Expect(fields(job.Validate())).To(ConsistOf(
+			"id",
+			"run.cpus",
+			"run.mem",
+			"run.disk",
+			"run.maxLaunchDelay",
+			"run.artifacts[0].uri",
+			"run.restart.policy",
+			"run.volumes[0].containerPath",
+			"schedules[0].cron",
+			"schedules[0].timezone",
+			"schedules[0].concurrencyPolicy",
+			"schedules[0].startingDeadlineSeconds",
+		))
+	})
+
+	It("Leaves unset fields to Metronome's defaults", func() {
+		jobs, err := LoadJobs([]byte(`
+id: minimal
+run:
+  cpus: 0.1
+  mem: 64
+  docker:
+    image: busybox
+schedules:
+  - id: nightly
+    cron: "0 2 * * *"
+`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].Validate()).To(BeNil())
+	})
+
+	It("Requires a run", func() {
+		job := Job{ID: "foo.bar"}
+		Expect(fields(job.Validate())).To(ConsistOf("run"))
+	})
+
+	It("Validates schedules on their own", func() {
+		sched := Schedule{ID: "hourly", Cron: "@hourly", Timezone: "America/Los_Angeles", ConcurrencyPolicy: "FORBID", StartingDeadlineSeconds: 60}
+		Expect(sched.Validate()).To(BeNil())
+		sched.Cron = "0 0 * * * 2017"
+		Expect(sched.Validate()).To(BeNil())
+		sched.Cron = "0 0 * * $"
+		Expect(fields(sched.Validate())).To(ConsistOf("cron"))
+	})
+
+	It("Validates constraints", func() {
+		constraint := Constraint{Attribute: "", Operator: Operator("NEAR")}
+		Expect(fields(constraint.Validate())).To(ConsistOf("attribute", "operator"))
+		constraint = Constraint{Attribute: "rack", Operator: LIKE, Value: "rack-[1-3]"}
+		Expect(constraint.Validate()).To(BeNil())
+	})
+
+	It("Checks constraint values against their operator", func() {
+		Expect((&Constraint{Attribute: "hostname", Operator: UNIQUE}).Validate()).To(BeNil())
+		Expect((&Constraint{Attribute: "rack", Operator: GROUPBY}).Validate()).To(BeNil())
+		Expect((&Constraint{Attribute: "rack", Operator: GROUPBY, Value: "3"}).Validate()).To(BeNil())
+		Expect(fields((&Constraint{Attribute: "rack", Operator: MAXPER, Value: "many"}).Validate())).To(ConsistOf("value"))
+		Expect(fields((&Constraint{Attribute: "rack", Operator: IS}).Validate())).To(ConsistOf("value"))
+		Expect(fields((&Constraint{Attribute: "rack", Operator: LIKE, Value: "rack-["}).Validate())).To(ConsistOf("value"))
+		Expect(fields((&Constraint{Attribute: "hostname", Operator: UNIQUE, Value: "x"}).Validate())).To(ConsistOf("value"))
+	})
+
+	It("Parses constraints from the command line", func() {
+		constraint, err := StrToConstraint("rack_id IN rack-1,rack-2")
+		Expect(err).To(BeNil())
+		Expect(*constraint).To(Equal(Constraint{Attribute: "rack_id", Operator: IN, Value: "rack-1,rack-2"}))
+		constraint, err = StrToConstraint("hostname unique")
+		Expect(err).To(BeNil())
+		Expect(*constraint).To(Equal(Constraint{Attribute: "hostname", Operator: UNIQUE}))
+		constraint, err = StrToConstraint("rack_id GROUP_BY")
+		Expect(err).To(BeNil())
+		Expect(constraint.Operator).To(Equal(GROUPBY))
+		_, err = StrToConstraint("hostname NEAR x")
+		Expect(err).ToNot(BeNil())
+		_, err = StrToConstraint("hostname MAX_PER")
+		Expect(err).ToNot(BeNil())
+		_, err = StrToConstraint("hostname")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("Keeps operators it doesn't know", func() {
+		var placement Placement
+		raw := `{"constraints":[{"attribute":"hostname","operator":"UNIQUE"},{"attribute":"zone","operator":"CLOSE_TO","value":"a"}]}`
+		Expect(json.Unmarshal([]byte(raw), &placement)).To(BeNil())
+		Expect(placement.Constraints[0].Operator).To(Equal(UNIQUE))
+		Expect(placement.Constraints[1].Operator.Known()).To(BeFalse())
+		b, err := json.Marshal(&placement)
+		Expect(err).To(BeNil())
+		Expect(b).To(MatchJSON(raw))
+	})
+
+	It("Constructs runs, restarts and volumes with the same rules", func() {
+		_, err := NewRun(0, 16, 0)
+		Expect(fields(err)).To(Equal([]string{"cpus", "mem"}))
+		run, err := NewRun(0.5, 32, 0)
+		Expect(err).To(BeNil())
+		Expect(run.Disk).To(Equal(0))
+		_, err = NewRestart(-1, "ALWAYS")
+		Expect(fields(err)).To(Equal([]string{"policy", "activeDeadlineSeconds"}))
+		_, err = NewVolume("relative", "", "rw")
+		Expect(fields(err)).To(Equal([]string{"containerPath", "hostPath", "mode"}))
+		vol, err := NewVolume("/mnt/data", "/data", "RO")
+		Expect(err).To(BeNil())
+		Expect(vol.Mode).To(Equal(RO))
+	})
+
+	It("Rejects bad container paths when constructing volumes", func() {
+		_, err := NewContainerPath("//double")
+		Expect(err).ToNot(BeNil())
+		path, err := NewContainerPath("/mnt/data")
+		Expect(err).To(BeNil())
+		Expect(string(path)).To(Equal("/mnt/data"))
+	})
+})
@@ -0,0 +1,372 @@
+package metronome
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAML support goes through each model's json form so field names, custom marshalling and enum checks
+// (Operator, MountMode, ContainerPath, env secrets, unknown fields) are identical in both formats.
+
+// ToYAML - v's json as yaml, keeping the json field order
+func ToYAML(v interface{}) ([]byte, error) {
+	value, err := yamlValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(value)
+}
+
+// FromYAML - decode a yaml document into v as if it were the equivalent json.  Models with their own UnmarshalYAML,
+// such as Job, decode through it
+func FromYAML(data []byte, v interface{}) error {
+	if _, ok := v.(yaml.Unmarshaler); ok {
+		return yaml.Unmarshal(data, v)
+	}
+	var value interface{}
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return fromYAMLValue(value, v)
+}
+
+// MarshalYAML - yaml.Marshaler implementation.  Same field names and values as json
+func (theJob Job) MarshalYAML() (interface{}, error) {
+	return yamlValue(theJob)
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Same field names and checks as json.  Label and env values are
+// read as written, see DecodeYAMLJobs
+func (theJob *Job) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	var text jobText
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	jsonable, err := JSONValue(value)
+	if err != nil {
+		return err
+	}
+	text.apply(jsonable)
+	return fromJSONValue(jsonable, theJob)
+}
+
+// MarshalYAML - yaml.Marshaler implementation.  Same field names and values as json
+func (runner Run) MarshalYAML() (interface{}, error) {
+	return yamlValue(runner)
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Same field names and checks as json.  Env values are read as
+// written, see DecodeYAMLJobs
+func (runner *Run) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	var text runText
+	if err := unmarshal(&text); err != nil {
+		return err
+	}
+	jsonable, err := JSONValue(value)
+	if err != nil {
+		return err
+	}
+	text.apply(jsonable)
+	return fromJSONValue(jsonable, runner)
+}
+
+// MarshalYAML - yaml.Marshaler implementation.  Same field names and values as json
+func (sched Schedule) MarshalYAML() (interface{}, error) {
+	return yamlValue(sched)
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Same field names and checks as json
+func (sched *Schedule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return fromYAMLValue(value, sched)
+}
+
+var yamlDocumentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// SplitYAMLDocuments - the documents of a yaml stream separated by `---` lines.  Blank documents are dropped
+func SplitYAMLDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range yamlDocumentSeparator.Split(string(data), -1) {
+		if len(bytes.TrimSpace([]byte(doc))) > 0 {
+			docs = append(docs, []byte(doc))
+		}
+	}
+	return docs
+}
+
+// LoadJobs - jobs from json (a job or an array of jobs) or a yaml stream whose documents are each a job
+// or a list of jobs
+func LoadJobs(data []byte) ([]Job, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return loadJSONJobs(trimmed)
+	}
+	var jobs []Job
+	for i, doc := range SplitYAMLDocuments(data) {
+		value, err := DecodeYAMLJobs(doc)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %s", i+1, err)
+		}
+		if value == nil {
+			continue
+		}
+		values, isList := value.([]interface{})
+		if !isList {
+			values = []interface{}{value}
+		}
+		for j, value := range values {
+			var job Job
+			if err := fromJSONValue(value, &job); err != nil {
+				if isList {
+					return nil, fmt.Errorf("document %d, job %d: %s", i+1, j+1, err)
+				}
+				return nil, fmt.Errorf("document %d: %s", i+1, err)
+			}
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func loadJSONJobs(data []byte) ([]Job, error) {
+	if data[0] == '[' {
+		var jobs []Job
+		if err := json.Unmarshal(data, &jobs); err != nil {
+			return nil, err
+		}
+		return jobs, nil
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return []Job{job}, nil
+}
+
+// yamlValue - v's json decoded into yaml values.  Objects become MapSlices so fields keep their json order
+func yamlValue(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	return decodeOrdered(decoder)
+}
+
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '{' {
+			object := yaml.MapSlice{}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object = append(object, yaml.MapItem{Key: key, Value: value})
+			}
+			_, err = decoder.Token()
+			return object, err
+		}
+		list := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = decoder.Token()
+		return list, err
+	case json.Number:
+		if i, err := token.Int64(); err == nil {
+			return i, nil
+		}
+		return token.Float64()
+	}
+	return token, nil
+}
+
+// fromYAMLValue - decode a value produced by yaml.Unmarshal into v via json
+func fromYAMLValue(value interface{}, v interface{}) error {
+	jsonable, err := JSONValue(value)
+	if err != nil {
+		return err
+	}
+	return fromJSONValue(jsonable, v)
+}
+
+// fromJSONValue - decode a value made of json types into v
+func fromJSONValue(value interface{}, v interface{}) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// DecodeYAMLJobs - the json form of a yaml document holding a job or a list of jobs, with objects as
+// map[string]interface{}.  Metronome only takes strings as label and run env values, so the scalars among them are
+// kept as written: VERSION: 1.10 and MODE: 0755 stay "1.10" and "0755" rather than becoming the numbers 1.1 and 493
+func DecodeYAMLJobs(doc []byte) (interface{}, error) {
+	var value interface{}
+	if err := yaml.Unmarshal(doc, &value); err != nil {
+		return nil, err
+	}
+	var texts []jobText
+	if _, isList := value.([]interface{}); isList {
+		if err := yaml.Unmarshal(doc, &texts); err != nil {
+			return nil, err
+		}
+	} else {
+		var text jobText
+		if err := yaml.Unmarshal(doc, &text); err != nil {
+			return nil, err
+		}
+		texts = []jobText{text}
+	}
+	jsonable, err := JSONValue(value)
+	if err != nil {
+		return nil, err
+	}
+	if list, isList := jsonable.([]interface{}); isList {
+		for i := range list {
+			if i < len(texts) {
+				texts[i].apply(list[i])
+			}
+		}
+	} else {
+		texts[0].apply(jsonable)
+	}
+	return jsonable, nil
+}
+
+// scalarText - a yaml scalar as written.  Set is false for anything else, such as an env secret or null
+type scalarText struct {
+	text string
+	set  bool
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Never fails; values that aren't scalars are left unset
+func (scalar *scalarText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	// yaml.v2 decodes any scalar into a string as its source text
+	scalar.set = unmarshal(&scalar.text) == nil
+	return nil
+}
+
+// runText - the env values of a yaml run as written
+type runText struct {
+	env map[string]scalarText
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Never fails; the json decoding reports a malformed run
+func (text *runText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var fields struct {
+		Env map[string]scalarText `yaml:"env"`
+	}
+	if unmarshal(&fields) == nil {
+		text.env = fields.Env
+	}
+	return nil
+}
+
+// apply - set the env values of run, a json object, to their text
+func (text runText) apply(run interface{}) {
+	if object, ok := run.(map[string]interface{}); ok {
+		setText(object["env"], text.env)
+	}
+}
+
+// jobText - the label and run env values of a yaml job as written
+type jobText struct {
+	labels map[string]scalarText
+	run    runText
+}
+
+// UnmarshalYAML - yaml.Unmarshaler implementation.  Never fails; the json decoding reports a malformed job
+func (text *jobText) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var fields struct {
+		Labels map[string]scalarText `yaml:"labels"`
+		Run    runText               `yaml:"run"`
+	}
+	if unmarshal(&fields) == nil {
+		text.labels, text.run = fields.Labels, fields.Run
+	}
+	return nil
+}
+
+// apply - set the label and run env values of job, a json object, to their text
+func (text jobText) apply(job interface{}) {
+	if object, ok := job.(map[string]interface{}); ok {
+		setText(object["labels"], text.labels)
+		text.run.apply(object["run"])
+	}
+}
+
+// setText - set the values of object, a json object, that were yaml scalars to their text
+func setText(object interface{}, texts map[string]scalarText) {
+	values, ok := object.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key, scalar := range texts {
+		if _, ok := values[key]; ok && scalar.set {
+			values[key] = scalar.text
+		}
+	}
+}
+
+// JSONValue - yaml's map[interface{}]interface{} objects, at any depth, as json's map[string]interface{}
+func JSONValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v must be a string", k)
+			}
+			v, err := JSONValue(v)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = v
+		}
+		return object, nil
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			v, err := JSONValue(v)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return list, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,138 @@
+package metronome_test
+
+import (
+	"encoding/json"
+
+	. "github.com/adobe-platform/go-metronome/metronome"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+const yamlJob = `description: Example Application
+id: prod.example.app
+labels:
+  owner: zeus
+run:
+  cmd: nuke --dry
+  cpus: 1.5
+  mem: 32
+  disk: 128
+  docker:
+    image: foo/bla:test
+  maxLaunchDelay: 3600
+  placement:
+    constraints:
+    - attribute: rack
+      operator: IS
+      value: rack-2
+  volumes:
+  - containerPath: /mnt/test
+    hostPath: /etc/guest
+    mode: RW
+  secrets:
+    secret0:
+      source: db/password
+  env:
+    DB_PASSWORD:
+      secret: secret0
+    MON: test
+schedules:
+- id: nightly
+  cron: 0 2 * * *
+  concurrencyPolicy: ALLOW
+  enabled: true
+  startingDeadlineSeconds: 60
+  timezone: UTC
+`
+
+var _ = Describe("YAML", func() {
+	It("Decodes with the json field names and custom types", func() {
+		var job Job
+		Expect(yaml.Unmarshal([]byte(yamlJob), &job)).To(BeNil())
+		Expect(job.ID).To(Equal("prod.example.app"))
+		Expect(job.Run.Volumes[0].Mode).To(Equal(RW))
+		Expect(job.Run.Placement.Constraints[0].Operator).To(Equal(IS))
+		Expect(job.Run.EnvSecrets).To(Equal(map[string]string{"DB_PASSWORD": "secret0"}))
+		Expect(job.Schedules[0].StartingDeadlineSeconds).To(Equal(60))
+		Expect(job.Validate()).To(BeNil())
+	})
+
+	It("Round trips through yaml and matches the json form", func() {
+		var job Job
+		Expect(FromYAML([]byte(yamlJob), &job)).To(BeNil())
+		doc, err := ToYAML(&job)
+		Expect(err).To(BeNil())
+		Expect(string(doc)).To(Equal(yamlJob))
+
+		var viaYAML, viaJSON Job
+		Expect(yaml.Unmarshal(doc, &viaYAML)).To(BeNil())
+		b, _ := json.Marshal(&job)
+		Expect(json.Unmarshal(b, &viaJSON)).To(BeNil())
+		Expect(viaYAML).To(Equal(viaJSON))
+	})
+
+	It("Marshals models through yaml.Marshal", func() {
+		doc, err := yaml.Marshal(&Schedule{ID: "s", Cron: "@daily", ConcurrencyPolicy: "FORBID", Timezone: "UTC"})
+		Expect(err).To(BeNil())
+		Expect(string(doc)).To(Equal("id: s\ncron: '@daily'\nconcurrencyPolicy: FORBID\nenabled: false\nstartingDeadlineSeconds: 0\ntimezone: UTC\n"))
+	})
+
+	It("Reads unquoted env and label values as strings", func() {
+		jobs, err := LoadJobs([]byte("id: a.job\nlabels: {replicas: 3, canary: false}\nrun:\n  env: {WORKERS: 4, RATIO: 1.5, DEBUG: true, EMPTY: \"\"}\n"))
+		Expect(err).To(BeNil())
+		Expect(*jobs[0].Labels).To(Equal(Labels{"replicas": "3", "canary": "false"}))
+		Expect(jobs[0].Run.Env).To(Equal(map[string]string{"WORKERS": "4", "RATIO": "1.5", "DEBUG": "true", "EMPTY": ""}))
+		var run Run
+		Expect(yaml.Unmarshal([]byte("env:\n  PORT: 8080\n"), &run)).To(Succeed())
+		Expect(run.Env["PORT"]).To(Equal("8080"))
+	})
+
+	It("Keeps env and label values as written", func() {
+		doc := "- id: a.job\n  labels: {version: 1.10}\n  run:\n    env: {MODE: 0755, COST: 1e3, BIG: 123456789012345678901234567890, ENABLED: yes, DB: {secret: db}}\n    secrets: {db: {source: db/password}}\n  tuning: {env: {THREADS: 0x10}}\n"
+		jobs, err := LoadJobs([]byte(doc))
+		Expect(err).To(BeNil())
+		Expect(*jobs[0].Labels).To(Equal(Labels{"version": "1.10"}))
+		Expect(jobs[0].Run.Env).To(Equal(map[string]string{"MODE": "0755", "COST": "1e3", "BIG": "123456789012345678901234567890", "ENABLED": "yes"}))
+		Expect(jobs[0].Run.EnvSecrets).To(Equal(map[string]string{"DB": "db"}))
+		Expect(string(jobs[0].Extra["tuning"])).To(Equal(`{"env":{"THREADS":16}}`))
+
+		var job Job
+		Expect(FromYAML([]byte("id: a.job\nlabels: {version: 1.10}\n"), &job)).To(Succeed())
+		Expect(*job.Labels).To(Equal(Labels{"version": "1.10"}))
+		var run Run
+		Expect(yaml.Unmarshal([]byte("env: {RATIO: 0.50}\n"), &run)).To(Succeed())
+		Expect(run.Env["RATIO"]).To(Equal("0.50"))
+	})
+
+	It("Applies the json enum checks", func() {
+		var run Run
+		err := yaml.Unmarshal([]byte("volumes:\n- containerPath: /mnt\n  hostPath: /data\n  mode: RX\n"), &run)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("Loads several jobs from one file", func() {
+		stream := "---\n" + yamlJob + "---\n# nothing here\n---\n- id: a.job\n  run: {cpus: 1, mem: 32}\n- id: b.job\n"
+		jobs, err := LoadJobs([]byte(stream))
+		Expect(err).To(BeNil())
+		Expect(jobs).To(HaveLen(3))
+		Expect(jobs[0].ID).To(Equal("prod.example.app"))
+		Expect(jobs[1].Run.Mem).To(Equal(32))
+		Expect(jobs[2].ID).To(Equal("b.job"))
+	})
+
+	It("Loads json too", func() {
+		jobs, err := LoadJobs([]byte(`[{"id": "a.job"}, {"id": "b.job"}]`))
+		Expect(err).To(BeNil())
+		Expect(jobs).To(HaveLen(2))
+		jobs, err = LoadJobs([]byte("\n{\"id\": \"a.job\"}"))
+		Expect(err).To(BeNil())
+		Expect(jobs[0].ID).To(Equal("a.job"))
+	})
+
+	It("Says which document is bad", func() {
+		_, err := LoadJobs([]byte("id: a.job\n---\nid: b.job\nrun:\n  volumes:\n  - mode: XX\n"))
+		Expect(err).To(MatchError(ContainSubstring("document 2")))
+	})
+})